@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/zalando/go-keyring"
+)
+
+// Loads configs whose ai.api_key is a keyring: or exec: reference and checks
+// the key is fetched, that failures stop the load, and that the environment
+// variables still win. Uses an in-memory keyring, not the real one:
+//
+//	go run ./cmd/testapikey
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testapikey")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	os.Unsetenv("CLAUDE_API_KEY")
+	os.Unsetenv("ANTHROPIC_API_KEY")
+	keyring.MockInit()
+	if err := keyring.Set("gitpulse", "anthropic", "sk-from-keyring\n"); err != nil {
+		gittest.Fail("seed keyring", err)
+	}
+	if err := keyring.Set("gitpulse", "work", "sk-work"); err != nil {
+		gittest.Fail("seed keyring", err)
+	}
+
+	load := func(yaml string) (*config.Config, error) {
+		if err := os.WriteFile(filepath.Join(tmp, "config.yaml"), []byte(yaml), 0644); err != nil {
+			gittest.Fail("write config", err)
+		}
+		return config.LoadFromDir(tmp, tmp)
+	}
+	key := func(cfg *config.Config) string {
+		if cfg == nil {
+			return ""
+		}
+		return cfg.AI.APIKey
+	}
+
+	fmt.Println("=== keyring ===")
+	cfg, err := load("ai:\n  api_key: keyring:gitpulse\n")
+	checks.Check("default account", err == nil && key(cfg) == "sk-from-keyring", err)
+	cfg, err = load("ai:\n  api_key: keyring:gitpulse/work\n")
+	checks.Check("named account", err == nil && key(cfg) == "sk-work", err)
+	_, err = load("ai:\n  api_key: keyring:missing\n")
+	checks.Check("missing entry is an error", err != nil && strings.Contains(err.Error(), "keyring"), err)
+	_, err = load("ai:\n  api_key: \"keyring:\"\n")
+	checks.Check("no service is an error", err != nil, err)
+
+	fmt.Println("=== exec ===")
+	cfg, err = load("ai:\n  api_key: \"exec:printf 'sk-from-command\\\\n'\"\n")
+	checks.Check("command output, trimmed", err == nil && key(cfg) == "sk-from-command", fmt.Sprint(key(cfg), err))
+	_, err = load("ai:\n  api_key: \"exec:echo locked >&2; exit 3\"\n")
+	checks.Check("failing command is an error with its stderr", err != nil && strings.Contains(err.Error(), "locked"), err)
+	_, err = load("ai:\n  api_key: \"exec:true\"\n")
+	checks.Check("empty output is an error", err != nil && strings.Contains(err.Error(), "empty"), err)
+
+	fmt.Println("=== precedence ===")
+	os.Setenv("ANTHROPIC_API_KEY", "sk-from-env")
+	cfg, err = load("ai:\n  api_key: \"exec:exit 1\"\n")
+	checks.Check("env var wins, command not run", err == nil && key(cfg) == "sk-from-env", err)
+	os.Unsetenv("ANTHROPIC_API_KEY")
+	cfg, err = load("ai:\n  provider: none\n  api_key: \"exec:exit 1\"\n")
+	checks.Check("not fetched with provider none", err == nil, err)
+	cfg, err = load("ai:\n  api_key: sk-literal\n")
+	checks.Check("literal key unchanged", err == nil && key(cfg) == "sk-literal", err)
+
+	fmt.Println("=== config.Load ===")
+	path := filepath.Join(tmp, "other.yaml")
+	os.WriteFile(path, []byte("ai:\n  api_key: keyring:gitpulse/work\n"), 0644)
+	cfg, err = config.Load(path)
+	checks.Check("Load fetches it too", err == nil && key(cfg) == "sk-work", err)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll API key checks passed.")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// slowReviewer is the offline client with a reviewer that waits for release
+// and then reports one blocker in api/handler.go.
+type slowReviewer struct {
+	*ai.OfflineClient
+	release chan struct{}
+}
+
+func (c slowReviewer) ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error) {
+	<-c.release
+	return &ai.ReviewResult{
+		Findings: []ai.ReviewFinding{{
+			File:        "api/handler.go",
+			StartLine:   3,
+			EndLine:     3,
+			Severity:    ai.SeverityError,
+			Description: "nil map write",
+		}},
+		HasBlockers: true,
+	}, nil
+}
+
+// Flushes with ai.review_mode: async: the commits are made without waiting
+// for the review, which then records its findings on the commits as a
+// post-push review and posts the blocker to a (fake) Slack webhook:
+//
+//	go run ./cmd/testasyncreview
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testasyncreview")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	slack := make(chan string, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		slack <- string(body)
+	}))
+	defer webhook.Close()
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.ReviewMinLines = 0
+	cfg.AI.ReviewMode = config.ReviewModeAsync
+	cfg.Notify.SlackWebhook = webhook.URL
+
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	reviewer := slowReviewer{ai.NewOfflineClient(), make(chan struct{})}
+	eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, reviewer)
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "api", "handler.go"), "package api\n\nfunc set() { var m map[string]int; m[\"a\"] = 1 }\n")
+	gittest.Write(filepath.Join(tmp, "util", "util.go"), "package util\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "api/handler.go", Type: watcher.Created},
+		{Path: "util/util.go", Type: watcher.Created},
+	}})
+
+	fmt.Println("=== commit without waiting ===")
+	eng.Flush() // returns while the review is still blocked on release
+	commits := strings.Fields(gittest.Run(tmp, "git", "rev-list", "HEAD"))
+	checks.Check("both groups committed before the review finished", len(commits) == 3, len(commits))
+	historyPath := filepath.Join(tmp, ".gitpulse", "history.json")
+	s, err := store.New(historyPath)
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	pending := true
+	for _, r := range s.All() {
+		pending = pending && r.Review == nil
+	}
+	checks.Check("no review recorded yet", pending, s.All())
+
+	fmt.Println("=== background review ===")
+	close(reviewer.release)
+	eng.Stop() // waits for the background review
+
+	s, err = store.New(historyPath)
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	var api, util *store.CommitRecord
+	for _, r := range s.All() {
+		r := r
+		switch r.Files[0].Path {
+		case "api/handler.go":
+			api = &r
+		case "util/util.go":
+			util = &r
+		}
+	}
+	checks.Check("blocker recorded on its commit", api != nil && api.Review != nil && api.Review.HasBlockers && len(api.Review.Findings) == 1, api)
+	checks.Check("flagged as a post-push review", api != nil && api.Review != nil && api.Review.Async, api)
+	checks.Check("other commit reviewed clean", util != nil && util.Review != nil && util.Review.Async && !util.Review.HasBlockers && len(util.Review.Findings) == 0, util)
+	checks.Check("review coverage recorded as async", api != nil && api.ReviewMode == store.ReviewModeAsync, api)
+	checks.Check("async commits listed as unreviewed", len(s.GetUnreviewed()) == 2, len(s.GetUnreviewed()))
+
+	select {
+	case body := <-slack:
+		checks.Check("Slack notified with the finding", strings.Contains(body, "api/handler.go:3 nil map write") && strings.Contains(body, api.Hash[:7]), body)
+	default:
+		checks.Check("Slack notified", false, "no request")
+	}
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll async review checks passed.")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Replays editor save sequences through watcher.Coalesce — Vim's
+// rename-to-backup save and a write-temp-then-rename save — and checks each
+// becomes a single Modified change, while real renames and new files are
+// left alone. Then saves a file the Vim way under a live watcher:
+//
+//	go run ./cmd/testatomicsave
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testatomicsave")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	fmt.Println("=== replayed sequences ===")
+	vim := changes(
+		"4913", watcher.Created,
+		"4913", watcher.Deleted,
+		"main.go", watcher.Renamed,
+		"main.go~", watcher.Created,
+		"main.go", watcher.Created,
+		"main.go", watcher.Modified,
+		"main.go~", watcher.Deleted,
+	)
+	checks.Check("Vim save is one Modified change", describe(watcher.Coalesce(vim)) == "main.go:modified", describe(watcher.Coalesce(vim)))
+
+	temp := changes(
+		".goutputstream-X1Y2Z3", watcher.Created,
+		".goutputstream-X1Y2Z3", watcher.Modified,
+		".goutputstream-X1Y2Z3", watcher.Renamed,
+		"util.go", watcher.Created,
+	)
+	checks.Check("write-temp-then-rename is one Modified change", describe(watcher.Coalesce(temp)) == "util.go:modified", describe(watcher.Coalesce(temp)))
+
+	mixed := append(changes("README.md", watcher.Modified), vim...)
+	mixed = append(mixed, changes("new.go", watcher.Created, "new.go", watcher.Modified)...)
+	checks.Check("other changes kept around a save", describe(watcher.Coalesce(mixed)) == "README.md:modified main.go:modified new.go:created new.go:modified", describe(watcher.Coalesce(mixed)))
+
+	mv := changes("old.go", watcher.Renamed, "moved.go", watcher.Created)
+	checks.Check("real rename left alone", describe(watcher.Coalesce(mv)) == "old.go:renamed moved.go:created", describe(watcher.Coalesce(mv)))
+
+	del := changes("gone.go", watcher.Deleted)
+	checks.Check("real delete left alone", describe(watcher.Coalesce(del)) == "gone.go:deleted", describe(watcher.Coalesce(del)))
+
+	fmt.Println("\n=== live watcher ===")
+	path := filepath.Join(tmp, "main.go")
+	gittest.Write(path, "package main\n")
+	for _, on := range []bool{true, false} {
+		got := vimSave(tmp, path, on)
+		if on {
+			checks.Check("coalesced: one Modified main.go", describe(got) == "main.go:modified", describe(got))
+		} else {
+			d := describe(got)
+			checks.Check("not coalesced: temp and backup events reported", strings.Contains(d, "main.go~") && strings.Contains(d, "4913"), d)
+		}
+	}
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll atomic save checks passed.")
+}
+
+// vimSave saves path the way Vim does under a watcher of dir and returns the
+// changes the watcher reports.
+func vimSave(dir, path string, coalesce bool) []watcher.FileChange {
+	w, err := watcher.New(dir, 1, nil)
+	if err != nil {
+		gittest.Fail("create watcher", err)
+	}
+	w.CoalesceAtomicSaves(coalesce)
+	if err := w.Start(); err != nil {
+		gittest.Fail("start watcher", err)
+	}
+	defer w.Stop()
+	time.Sleep(300 * time.Millisecond) // let the directory walk add the watch
+
+	probe := filepath.Join(dir, "4913")
+	gittest.Write(probe, "")
+	os.Remove(probe)
+	if err := os.Rename(path, path+"~"); err != nil {
+		gittest.Fail("rename to backup", err)
+	}
+	gittest.Write(path, "package main\n\nfunc main() {}\n")
+	os.Remove(path + "~")
+
+	select {
+	case cs := <-w.Events():
+		return cs.Files
+	case <-time.After(10 * time.Second):
+		gittest.Fail("receive changes", fmt.Errorf("timed out"))
+	}
+	return nil
+}
+
+// changes builds FileChanges from path, type pairs.
+func changes(pairs ...interface{}) []watcher.FileChange {
+	var out []watcher.FileChange
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, watcher.FileChange{Path: pairs[i].(string), Type: pairs[i+1].(watcher.ChangeType), Time: time.Now()})
+	}
+	return out
+}
+
+// describe renders changes as "path:type ...".
+func describe(changes []watcher.FileChange) string {
+	names := map[watcher.ChangeType]string{
+		watcher.Modified: "modified", watcher.Created: "created",
+		watcher.Deleted: "deleted", watcher.Renamed: "renamed",
+	}
+	var parts []string
+	for _, fc := range changes {
+		parts = append(parts, fc.Path+":"+names[fc.Type])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Flushes a change first seen two hours ago and checks the commit's author
+// date is that edit (author_date: first_change) while the committer date is
+// now. Then folds a later edit into it (amend_window_seconds) and checks the
+// amended commit keeps the author date, matching the first change time in
+// its history record, and that with author_date: now the author date is now:
+//
+//	go run ./cmd/testauthordate
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testauthordate")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = ai.ProviderNone
+	cfg.PushMode = config.PushModeNever
+	cfg.AmendWindowSeconds = 3600
+
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, ai.NewOfflineClient())
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer func() { eng.Stop() }()
+
+	fmt.Println("=== first change two hours ago ===")
+	edited := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	start := time.Now().Truncate(time.Second)
+	gittest.Write(filepath.Join(tmp, "a.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "a.go", Type: watcher.Created, Time: edited}}})
+	eng.Flush()
+	first := strings.TrimSpace(gittest.Run(tmp, "git", "rev-parse", "HEAD"))
+	authored, committed := dates(tmp)
+	checks.Check("author date is the edit", authored.Equal(edited), authored)
+	checks.Check("committer date is now", !committed.Before(start), committed)
+
+	fmt.Println("=== later edit amended in ===")
+	gittest.Write(filepath.Join(tmp, "a.go"), "package main\n\nvar a = 1\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "a.go", Type: watcher.Modified, Time: time.Now().Add(-time.Hour)}}})
+	eng.Flush()
+	head := strings.TrimSpace(gittest.Run(tmp, "git", "rev-parse", "HEAD"))
+	parent := strings.TrimSpace(gittest.Run(tmp, "git", "rev-parse", "HEAD^"))
+	checks.Check("amended", head != first && parent != first, head)
+	authored, committed = dates(tmp)
+	checks.Check("author date kept", authored.Equal(edited), authored)
+	checks.Check("committer date is now", !committed.Before(start), committed)
+	s, err := store.New(filepath.Join(tmp, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	rec := s.GetByHash(head)
+	var firstChanged time.Time
+	if rec != nil && len(rec.Files) == 1 && rec.Files[0].FirstChangedAt != nil {
+		firstChanged = *rec.Files[0].FirstChangedAt
+	}
+	checks.Check("history agrees on the first change", firstChanged.Equal(authored), firstChanged)
+
+	fmt.Println("=== author_date: now ===")
+	cfg.AmendWindowSeconds = 0
+	cfg.AuthorDate = config.AuthorDateNow
+	gittest.Write(filepath.Join(tmp, "b.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "b.go", Type: watcher.Created, Time: edited}}})
+	eng.Flush()
+	authored, _ = dates(tmp)
+	checks.Check("author date is now", !authored.Before(start), authored)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll author date checks passed.")
+}
+
+// dates returns HEAD's author and committer dates.
+func dates(dir string) (authored, committed time.Time) {
+	out := strings.Fields(gittest.Run(dir, "git", "log", "-1", "--format=%at %ct"))
+	if len(out) != 2 {
+		gittest.Fail("read dates", fmt.Errorf("unexpected output %q", out))
+	}
+	at, _ := strconv.ParseInt(out[0], 10, 64)
+	ct, _ := strconv.ParseInt(out[1], 10, 64)
+	return time.Unix(at, 0), time.Unix(ct, 0)
+}
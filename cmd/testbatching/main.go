@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+var filesRe = regexp.MustCompile(`(?m)^\s*Files: (.*)$`)
+
+// fakeAPI rejects prompts with more than maxGroups groups, or with a file
+// named huge.go, as too long, and otherwise answers refine prompts with one
+// message per group, review prompts with one error per group and classify
+// prompts with "fix" for every file. It records
+// the number of groups in each request.
+type fakeAPI struct {
+	maxGroups int
+	status    int // status of a too-long rejection
+	requests  []int
+}
+
+func (f *fakeAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req struct {
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	prompt := req.Messages[0].Content
+	groups := filesRe.FindAllStringSubmatch(prompt, -1)
+	f.requests = append(f.requests, len(groups))
+
+	if len(groups) > f.maxGroups || strings.Contains(prompt, "huge.go") {
+		return respond(r, f.status, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 250000 tokens > 200000 maximum"}}`), nil
+	}
+
+	var reply interface{}
+	if strings.Contains(prompt, "Classify the change") {
+		types := make(map[string]string)
+		for _, g := range groups {
+			for _, f := range strings.Split(g[1], ", ") {
+				types[f] = "fix"
+			}
+		}
+		reply = types
+	} else if strings.HasPrefix(prompt, "You are an expert code reviewer") {
+		var findings []map[string]interface{}
+		for _, g := range groups {
+			findings = append(findings, map[string]interface{}{
+				"file": g[1], "start_line": 1, "severity": "error", "description": "bug in " + g[1],
+			})
+		}
+		reply = findings
+	} else {
+		var refined []map[string]interface{}
+		for _, g := range groups {
+			refined = append(refined, map[string]interface{}{
+				"files": strings.Split(g[1], ", "), "reason": "r", "commit_message": "feat: change " + g[1],
+			})
+		}
+		reply = refined
+	}
+	text, _ := json.Marshal(reply)
+	body, _ := json.Marshal(map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": string(text)}},
+	})
+	return respond(r, http.StatusOK, string(body)), nil
+}
+
+func respond(r *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}
+}
+
+// Simulates the API rejecting big prompts as too long and checks refine,
+// review and classify split the groups into smaller batches and merge the
+// results, that ai.max_groups_per_request batches up front, and that one
+// group too large on its own only costs that group its AI message (or type).
+// No real API calls:
+//
+//	go run ./cmd/testbatching
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testbatching")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	fmt.Println("=== config ===")
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	checks.Check("no batch cap by default", err == nil && cfg.AI.MaxGroupsPerRequest == 0, err)
+	if err := os.WriteFile(filepath.Join(tmp, "config.yaml"), []byte("ai:\n  max_groups_per_request: 2\n"), 0644); err != nil {
+		gittest.Fail("write config", err)
+	}
+	cfg, err = config.LoadFromDir(tmp, tmp)
+	checks.Check("max_groups_per_request read", err == nil && cfg.AI.MaxGroupsPerRequest == 2, err)
+
+	groups := func(files ...string) []grouper.FileGroup {
+		var gs []grouper.FileGroup
+		for _, f := range files {
+			gs = append(gs, grouper.FileGroup{Files: []string{f}, Reason: "r", Diffs: "+" + f + "\n"})
+		}
+		return gs
+	}
+	eight := []string{"a.go", "b.go", "c.go", "d.go", "e.go", "f.go", "g.go", "h.go"}
+	newClient := func(api *fakeAPI, maxGroups int) *ai.Client {
+		c := ai.NewClient("test-key", "test-model", &http.Client{Transport: api})
+		c.SetMaxGroupsPerRequest(maxGroups)
+		return c
+	}
+	allAI := func(refined []grouper.FileGroup, files []string) bool {
+		if len(refined) != len(files) {
+			return false
+		}
+		for i, g := range refined {
+			if g.CommitMessage != "feat: change "+files[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	fmt.Println("\n=== refine: prompt too long ===")
+	api := &fakeAPI{maxGroups: 3, status: http.StatusBadRequest}
+	refined, err := newClient(api, 0).RefineAndCommit(groups(eight...))
+	checks.Check("refine succeeds", err == nil, err)
+	checks.Check("every group gets its AI message, in order", allAI(refined, eight), refined)
+	checks.Check("halved until the batches fit", fmt.Sprint(api.requests) == "[8 4 2 2 4 2 2]", api.requests)
+
+	fmt.Println("\n=== refine: max_groups_per_request ===")
+	api = &fakeAPI{maxGroups: 3, status: http.StatusBadRequest}
+	refined, err = newClient(api, 2).RefineAndCommit(groups(eight...))
+	checks.Check("batched up front", err == nil && fmt.Sprint(api.requests) == "[2 2 2 2]", api.requests)
+	checks.Check("results merged", allAI(refined, eight), refined)
+
+	fmt.Println("\n=== refine: one group too large ===")
+	api = &fakeAPI{maxGroups: 3, status: http.StatusRequestEntityTooLarge}
+	refined, err = newClient(api, 0).RefineAndCommit(groups("a.go", "huge.go", "c.go"))
+	checks.Check("refine succeeds", err == nil && len(refined) == 3, err)
+	if len(refined) == 3 {
+		checks.Check("others keep their AI messages", refined[0].CommitMessage == "feat: change a.go" && refined[2].CommitMessage == "feat: change c.go", refined)
+		checks.Check("the huge group gets the generic message", refined[1].CommitMessage == "chore: auto-commit changes", refined[1].CommitMessage)
+	}
+
+	fmt.Println("\n=== review: prompt too long ===")
+	api = &fakeAPI{maxGroups: 2, status: http.StatusBadRequest}
+	result, err := newClient(api, 0).ReviewCode(groups("a.go", "b.go", "c.go", "d.go", "e.go"))
+	checks.Check("review succeeds", err == nil, err)
+	if err == nil {
+		checks.Check("findings from every batch merged", len(result.Findings) == 5 && result.HasBlockers, result.Findings)
+	}
+	api = &fakeAPI{maxGroups: 5, status: http.StatusBadRequest}
+	result, err = newClient(api, 2).ReviewCode(groups("a.go", "b.go", "c.go", "d.go", "e.go"))
+	checks.Check("review batched up front", err == nil && fmt.Sprint(api.requests) == "[2 2 1]" && len(result.Findings) == 5, api.requests)
+
+	fmt.Println("\n=== review: one group too large ===")
+	api = &fakeAPI{maxGroups: 3, status: http.StatusBadRequest}
+	_, err = newClient(api, 0).ReviewCode(groups("a.go", "huge.go"))
+	checks.Check("review fails rather than skipping the group", err != nil && strings.Contains(err.Error(), "too large"), err)
+
+	fmt.Println("\n=== classify: prompt too long ===")
+	allFix := func(types map[string]string, files []string) bool {
+		for _, f := range files {
+			if types[f] != "fix" {
+				return false
+			}
+		}
+		return len(types) == len(files)
+	}
+	api = &fakeAPI{maxGroups: 3, status: http.StatusBadRequest}
+	types, err := newClient(api, 0).ClassifyChanges(groups(eight...))
+	checks.Check("classify succeeds", err == nil, err)
+	checks.Check("every file typed by the model", allFix(types, eight), types)
+	checks.Check("halved until the batches fit", fmt.Sprint(api.requests) == "[8 4 2 2 4 2 2]", api.requests)
+	api = &fakeAPI{maxGroups: 3, status: http.StatusBadRequest}
+	types, err = newClient(api, 2).ClassifyChanges(groups(eight...))
+	checks.Check("classify batched up front", err == nil && fmt.Sprint(api.requests) == "[2 2 2 2]" && allFix(types, eight), api.requests)
+
+	fmt.Println("\n=== classify: one group too large ===")
+	api = &fakeAPI{maxGroups: 3, status: http.StatusRequestEntityTooLarge}
+	types, err = newClient(api, 0).ClassifyChanges(groups("a.go", "huge.go", "c.go"))
+	checks.Check("classify succeeds", err == nil, err)
+	checks.Check("others keep their AI types", types["a.go"] == "fix" && types["c.go"] == "fix", types)
+	checks.Check("the huge group's file is chore", types["huge.go"] == "chore", types["huge.go"])
+
+	fmt.Println("\n=== other errors aren't split ===")
+	api = &fakeAPI{maxGroups: 0, status: http.StatusUnauthorized}
+	_, err = newClient(api, 0).ReviewCode(groups("a.go", "b.go"))
+	checks.Check("single request, error returned", err != nil && len(api.requests) == 1, api.requests)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll batching checks passed.")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Runs the engine with flush_on_branch_switch, buffers changes on main and
+// checks out another branch: the changes are committed to main (not the new
+// branch), and the index and the new branch are left alone:
+//
+//	go run ./cmd/testbranchswitch
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testbranchswitch")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+	gittest.Run(tmp, "git", "branch", "feature")
+	featureHead := gittest.Run(tmp, "git", "rev-parse", "feature")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	cfg.FlushOnBranchSwitch = true
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+
+	// Written before the watcher starts, so only Submit buffers them
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\nworld\n")
+	gittest.Write(filepath.Join(tmp, "work.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "README.md", Type: watcher.Modified},
+		{Path: "work.go", Type: watcher.Created},
+	}})
+	go eng.Run()
+	time.Sleep(500 * time.Millisecond)
+
+	fmt.Println("=== git checkout feature ===")
+	gittest.Run(tmp, "git", "checkout", "-q", "feature")
+	committed := false
+	for i := 0; i < 100 && !committed; i++ {
+		time.Sleep(100 * time.Millisecond)
+		committed = eng.PendingCount() == 0
+	}
+	checks.Check("pending drained", committed, eng.PendingCount())
+
+	files := gittest.Run(tmp, "git", "show", "--name-only", "--format=", "main")
+	checks.Check("changes committed to main", strings.Join(strings.Fields(files), ",") == "README.md,work.go", files)
+	checks.Check("main's commit has the new content", gittest.Run(tmp, "git", "show", "main:README.md") == "hello\nworld\n", "")
+	checks.Check("feature left alone", gittest.Run(tmp, "git", "rev-parse", "feature") == featureHead, "")
+	checks.Check("still on feature", strings.TrimSpace(gittest.Run(tmp, "git", "branch", "--show-current")) == "feature", "")
+	staged := gittest.Run(tmp, "git", "diff", "--cached", "--name-only")
+	checks.Check("index untouched", strings.TrimSpace(staged) == "", staged)
+
+	s, err := store.New(filepath.Join(tmp, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	commits := s.GetByFile("work.go")
+	checks.Check("recorded on main", len(commits) == 1 && commits[0].Branch == "main" && commits[0].GroupReason == "branch switch", commits)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll branch switch checks passed.")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Toggles a script's executable bit with no content change: the watcher
+// reports it (but not a touch, which fires the same Chmod event), the mode
+// change is staged and committed on its own as "chore: make script.sh
+// executable" next to an ordinary edit in the same flush, clearing the bit
+// again commits it as non-executable, and chmod +x with a whitespace edit
+// isn't passed off as formatting:
+//
+//	go run ./cmd/testchmod
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testchmod")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	script := filepath.Join(tmp, "script.sh")
+	gittest.Write(script, "#!/bin/sh\necho hi\n")
+	gittest.Write(filepath.Join(tmp, "main.go"), "package main\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.Provider = ai.ProviderNone
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+
+	// ── watcher ──
+	fmt.Println("=== watcher ===")
+	eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, ai.NewOfflineClient())
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	go eng.Run()
+	time.Sleep(500 * time.Millisecond) // let the directory walk add the watch
+	now := time.Now()
+	if err := os.Chtimes(script, now, now); err != nil {
+		gittest.Fail("touch", err)
+	}
+	time.Sleep(3 * time.Second) // past the watcher's 2s batching
+	checks.Check("touch (Chmod event, same mode) ignored", eng.PendingCount() == 0, eng.PendingCount())
+	if err := os.Chmod(script, 0755); err != nil {
+		gittest.Fail("chmod", err)
+	}
+	time.Sleep(3 * time.Second)
+	checks.Check("chmod +x reported as a change", eng.PendingCount() == 1, eng.PendingCount())
+	eng.Stop()
+
+	// ── commit ──
+	fmt.Println("=== commit ===")
+	flush := func(changes ...watcher.FileChange) {
+		eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, ai.NewOfflineClient())
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		eng.Submit(watcher.ChangeSet{Files: changes})
+		eng.Flush()
+		eng.Stop()
+	}
+	subjects := func(n int) []string {
+		return strings.Split(strings.TrimSpace(gittest.Run(tmp, "git", "log", fmt.Sprintf("-%d", n), "--format=%s")), "\n")
+	}
+
+	gittest.Write(filepath.Join(tmp, "main.go"), "package main\n\nfunc main() {}\n")
+	flush(
+		watcher.FileChange{Path: "script.sh", Type: watcher.Modified},
+		watcher.FileChange{Path: "main.go", Type: watcher.Modified},
+	)
+	got := subjects(2)
+	checks.Check("mode change committed with a canned message", len(got) == 2 && got[0] == "chore: make script.sh executable", got)
+	checks.Check("edit committed separately", len(got) == 2 && !strings.Contains(got[1], "script.sh") && got[1] != "init", got)
+	files := strings.TrimSpace(gittest.Run(tmp, "git", "show", "--name-only", "--format=", "HEAD"))
+	checks.Check("mode commit holds only the script", files == "script.sh", files)
+	mode := strings.Fields(gittest.Run(tmp, "git", "ls-files", "-s", "script.sh"))
+	checks.Check("committed as 100755", len(mode) > 0 && mode[0] == "100755", mode)
+	status := strings.TrimSpace(gittest.Run(tmp, "git", "status", "--porcelain", "--", "script.sh", "main.go"))
+	checks.Check("nothing left uncommitted", status == "", status)
+
+	fmt.Println("=== chmod -x ===")
+	if err := os.Chmod(script, 0644); err != nil {
+		gittest.Fail("chmod", err)
+	}
+	flush(watcher.FileChange{Path: "script.sh", Type: watcher.Modified})
+	got = subjects(1)
+	checks.Check("committed as non-executable", got[0] == "chore: make script.sh non-executable", got)
+	mode = strings.Fields(gittest.Run(tmp, "git", "ls-files", "-s", "script.sh"))
+	checks.Check("committed as 100644", len(mode) > 0 && mode[0] == "100644", mode)
+
+	fmt.Println("=== chmod +x with a whitespace edit ===")
+	if err := os.Chmod(script, 0755); err != nil {
+		gittest.Fail("chmod", err)
+	}
+	gittest.Write(script, "#!/bin/sh\necho   hi\n")
+	flush(watcher.FileChange{Path: "script.sh", Type: watcher.Modified})
+	got = subjects(1)
+	checks.Check("not committed as formatting", got[0] != "style: format code" && got[0] != "chore: format code", got)
+	mode = strings.Fields(gittest.Run(tmp, "git", "ls-files", "-s", "script.sh"))
+	checks.Check("mode committed too", len(mode) > 0 && mode[0] == "100755", mode)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll file mode checks passed.")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// countingAI is the offline client with call counters and a switchable
+// combined call, standing in for the Claude client.
+type countingAI struct {
+	*ai.OfflineClient
+	combinedFails bool
+	combined      int
+	refines       int
+	reviews       int
+}
+
+func (c *countingAI) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	c.refines++
+	return c.OfflineClient.RefineWithHint(groups, hint)
+}
+
+func (c *countingAI) ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error) {
+	c.reviews++
+	return &ai.ReviewResult{}, nil
+}
+
+func (c *countingAI) RefineAndReview(groups []grouper.FileGroup) ([]grouper.FileGroup, *ai.ReviewResult, error) {
+	c.combined++
+	if c.combinedFails {
+		return nil, nil, ai.ErrCombinedUnparsed
+	}
+	groups, _ = c.OfflineClient.RefineAndCommit(groups)
+	return groups, &ai.ReviewResult{}, nil
+}
+
+// Checks ai.combine_refine_and_review: small flushes make one combined call
+// instead of refine + review, and fall back to both when it fails. Uses a
+// throwaway repo and a fake AI client:
+//
+//	go run ./cmd/testcombined
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testcombined")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.CombineRefineAndReview = true
+
+	flush := func(client *countingAI, file, content string) {
+		repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+		if err != nil {
+			gittest.Fail("open repo", err)
+		}
+		eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, client)
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		gittest.Write(filepath.Join(tmp, file), content)
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: file, Type: watcher.Created}}})
+		eng.Flush()
+		eng.Stop()
+	}
+	code := "package main\n\nfunc a() int {\n\treturn 1\n}\n\nfunc b() int {\n\treturn 2\n}\n"
+
+	fmt.Println("=== small flush: one combined call ===")
+	c := &countingAI{OfflineClient: ai.NewOfflineClient()}
+	flush(c, "a.go", code)
+	checks.Check("combined call made once", c.combined == 1, c.combined)
+	checks.Check("no separate refine", c.refines == 0, c.refines)
+	checks.Check("no separate review", c.reviews == 0, c.reviews)
+
+	fmt.Println("\n=== combined reply doesn't parse: falls back ===")
+	c = &countingAI{OfflineClient: ai.NewOfflineClient(), combinedFails: true}
+	flush(c, "b.go", code)
+	checks.Check("combined call tried", c.combined == 1, c.combined)
+	checks.Check("separate refine", c.refines == 1, c.refines)
+	checks.Check("separate review", c.reviews == 1, c.reviews)
+
+	fmt.Println("\n=== large flush: separate calls ===")
+	c = &countingAI{OfflineClient: ai.NewOfflineClient()}
+	flush(c, "c.go", "package main\n"+strings.Repeat("var _ = 1\n", 500))
+	checks.Check("no combined call", c.combined == 0, c.combined)
+	checks.Check("separate refine and review", c.refines == 1 && c.reviews == 1, fmt.Sprint(c.refines, c.reviews))
+
+	fmt.Println("\n=== option off: separate calls ===")
+	cfg.AI.CombineRefineAndReview = false
+	c = &countingAI{OfflineClient: ai.NewOfflineClient()}
+	flush(c, "d.go", code)
+	checks.Check("no combined call", c.combined == 0, c.combined)
+	checks.Check("separate refine and review", c.refines == 1 && c.reviews == 1, fmt.Sprint(c.refines, c.reviews))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll combine_refine_and_review checks passed.")
+}
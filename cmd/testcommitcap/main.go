@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Flushes with max_commits_per_hour: 2 — the first two flushes commit, the
+// next ones keep their changes buffered and coalescing, and `gitpulse push`
+// (PushNow) commits them together in spite of the cap:
+//
+//	go run ./cmd/testcommitcap
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testcommitcap")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	cfg.MaxCommitsPerHour = 2
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+	commits := func() int {
+		return len(strings.Fields(gittest.Run(tmp, "git", "rev-list", "HEAD")))
+	}
+	change := func(name string) {
+		gittest.Write(filepath.Join(tmp, name), name+"\n")
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: name, Type: watcher.Created}}})
+	}
+
+	fmt.Println("=== under the cap ===")
+	change("a.txt")
+	eng.Flush()
+	change("b.txt")
+	eng.Flush()
+	checks.Check("two flushes, two commits", commits() == 3, commits())
+
+	fmt.Println("=== over the cap ===")
+	change("c.txt")
+	eng.Flush()
+	checks.Check("third flush held", commits() == 3, commits())
+	checks.Check("its change stays buffered", eng.PendingCount() == 1, eng.PendingCount())
+	change("d.txt")
+	eng.Flush()
+	checks.Check("fourth flush held", commits() == 3, commits())
+	checks.Check("changes coalesce", eng.PendingCount() == 2, eng.PendingCount())
+
+	fmt.Println("=== manual push ===")
+	eng.PushNow()
+	checks.Check("push bypasses the cap", commits() == 4, commits())
+	checks.Check("buffer drained", eng.PendingCount() == 0, eng.PendingCount())
+	files := strings.Fields(gittest.Run(tmp, "git", "show", "--name-only", "--format=", "HEAD"))
+	checks.Check("held changes committed together", strings.Join(files, ",") == "c.txt,d.txt", files)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll commit cap checks passed.")
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// fakeAPI answers every API request with reply and keeps the last prompt.
+type fakeAPI struct {
+	reply  string
+	prompt string
+}
+
+func (f *fakeAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req struct {
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err == nil && len(req.Messages) > 0 {
+		f.prompt = req.Messages[0].Content
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": f.reply}},
+	})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    r,
+	}, nil
+}
+
+// Checks that ai.commit_language puts a language instruction (keeping the
+// commit types English) into the refine and commit message prompts, and
+// that the default English adds none. The API is faked in-process:
+//
+//	go run ./cmd/testcommitlanguage
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testcommitlanguage")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	fmt.Println("=== config ===")
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	checks.Check("defaults to en", err == nil && cfg.AI.CommitLanguage == "en", cfg)
+	if err := os.WriteFile(filepath.Join(tmp, "config.yaml"), []byte("ai:\n  commit_language: es\n"), 0644); err != nil {
+		gittest.Fail("write config", err)
+	}
+	cfg, err = config.LoadFromDir(tmp, tmp)
+	checks.Check("commit_language read", err == nil && cfg.AI.CommitLanguage == "es", err)
+
+	groups := func() []grouper.FileGroup {
+		return []grouper.FileGroup{{Files: []string{"auth/login.go"}, Reason: "same package: auth", Diffs: "+func Login() {}\n"}}
+	}
+	const instruction = "in this language: es"
+
+	fmt.Println("\n=== commit_language: es ===")
+	api := &fakeAPI{reply: `[{"files":["auth/login.go"],"reason":"login","commit_message":"feat(auth): añadir inicio de sesión"}]`}
+	client := ai.NewClient("test-key", "test-model", &http.Client{Transport: api})
+	client.SetCommitLanguage(cfg.AI.CommitLanguage)
+	refined, err := client.RefineAndCommit(groups())
+	checks.Check("refine prompt asks for Spanish", strings.Contains(api.prompt, instruction), api.prompt)
+	checks.Check("refine prompt keeps types English", strings.Contains(api.prompt, "type and scope (e.g. 'feat(auth):') in English"), api.prompt)
+	checks.Check("Spanish message kept", err == nil && len(refined) == 1 && refined[0].CommitMessage == "feat(auth): añadir inicio de sesión", refined)
+
+	api.reply = "fix(auth): corregir el token"
+	msg, model, err := client.GenerateCommitMessage("+fix\n", []string{"auth/token.go"})
+	checks.Check("commit message prompt asks for Spanish", strings.Contains(api.prompt, instruction), api.prompt)
+	checks.Check("message returned", err == nil && msg == "fix(auth): corregir el token", msg)
+	checks.Check("with the model that wrote it", model == "test-model", model)
+
+	review := client.WithModel("review-model")
+	api.reply = `{"groups":[{"files":["auth/login.go"],"reason":"login","commit_message":"feat(auth): añadir"}],"findings":[]}`
+	review.RefineAndReview(groups())
+	checks.Check("carried over by WithModel and the combined prompt", strings.Contains(api.prompt, instruction), api.prompt)
+
+	fmt.Println("\n=== default (en) ===")
+	api.reply = "feat(auth): add login"
+	client = ai.NewClient("test-key", "test-model", &http.Client{Transport: api})
+	client.SetCommitLanguage("en")
+	client.GenerateCommitMessage("+x\n", []string{"auth/login.go"})
+	checks.Check("no language instruction", api.prompt != "" && !strings.Contains(api.prompt, "in this language"), api.prompt)
+	api.reply = `[{"files":["auth/login.go"],"reason":"login","commit_message":"feat(auth): add login"}]`
+	client.RefineAndCommit(groups())
+	checks.Check("no language instruction in refine", api.prompt != "" && !strings.Contains(api.prompt, "in this language"), api.prompt)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll commit_language checks passed.")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+)
+
+// fakeAPI answers every API request with reply.
+type fakeAPI struct {
+	reply string
+}
+
+func (f *fakeAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": f.reply}},
+	})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    r,
+	}, nil
+}
+
+// Checks how commit messages from the model are normalized to the allowed
+// commit types (commit_types): allowed types pass, conventional-looking
+// ones that aren't allowed become chore, and anything else, like a
+// capitalized "Update:" prefix, is kept whole behind "chore: ". The API is
+// faked in-process:
+//
+//	go run ./cmd/testcommittypes
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testcommittypes")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	api := &fakeAPI{}
+	client := ai.NewClient("test-key", "test-model", &http.Client{Transport: api})
+	normalized := func(reply string) string {
+		api.reply = reply
+		msg, _, err := client.GenerateCommitMessage("+func Login() {}\n", []string{"auth/login.go"})
+		if err != nil {
+			gittest.Fail("generate message", err)
+		}
+		return msg
+	}
+
+	fmt.Println("=== default commit_types ===")
+	cases := []struct{ name, reply, want string }{
+		{"allowed type kept", "feat(auth): add login", "feat(auth): add login"},
+		{"capitalized allowed type lowercased", "Fix: handle nil session", "fix: handle nil session"},
+		{"unknown type becomes chore", "feature: add login", "chore: add login"},
+		{"unknown type with scope becomes chore", "Feature(auth)!: drop sessions", "chore(auth)!: drop sessions"},
+		{"capitalized word kept whole", "Update: handle nil config", "chore: Update: handle nil config"},
+		{"no type gets a prefix", "add login", "chore: add login"},
+		{"body untouched", "Update: handle nil config\n\nNote: keeps the old default", "chore: Update: handle nil config\n\nNote: keeps the old default"},
+	}
+	for _, c := range cases {
+		got := normalized(c.reply)
+		checks.Check(c.name, got == c.want, got)
+	}
+
+	fmt.Println("\n=== commit_types with wip ===")
+	client.SetCommitTypes([]string{"feat", "fix", "chore", "wip"})
+	got := normalized("wip: half of login")
+	checks.Check("custom type kept", got == "wip: half of login", got)
+	got = normalized("docs: explain login")
+	checks.Check("type outside the list becomes chore", got == "chore: explain login", got)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll commit type checks passed.")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// Fills a history with old and recent commits, then checks that Compact
+// strips only the old diffs, keeps their metadata and stats, reports the
+// bytes reclaimed, survives a reload, and that a dry run writes nothing:
+//
+//	go run ./cmd/testcompact
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testcompact")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1,2 @@\n package main\n+" + strings.Repeat("x", 2000) + "\n"
+	record := func(hash string, age time.Duration) store.CommitRecord {
+		return store.CommitRecord{
+			Hash:      hash,
+			Message:   "feat: " + hash,
+			CreatedAt: time.Now().Add(-age),
+			Files: []store.FileChange{
+				{Path: "a.go", Diff: diff, LinesAdded: 1, Status: "modified", DiffBytes: len(diff), HunkCount: 1},
+				{Path: "logo.png", Status: "added", Binary: true},
+			},
+		}
+	}
+	records := []store.CommitRecord{
+		record("old1", 90*24*time.Hour),
+		record("old2", 40*24*time.Hour),
+		record("new1", 24*time.Hour),
+	}
+
+	historyPath := filepath.Join(tmp, ".gitpulse", "history.json")
+	s, err := store.New(historyPath)
+	if err != nil {
+		gittest.Fail("open store", err)
+	}
+	if _, err := s.Import(records); err != nil {
+		gittest.Fail("import records", err)
+	}
+	size := func() int64 {
+		info, err := os.Stat(historyPath)
+		if err != nil {
+			gittest.Fail("stat history", err)
+		}
+		return info.Size()
+	}
+	opts := store.CompactOptions{StripDiffs: true, OlderThan: 30 * 24 * time.Hour}
+
+	fmt.Println("=== dry run ===")
+	before := size()
+	opts.DryRun = true
+	result, err := s.Compact(opts)
+	checks.Check("no error", err == nil, err)
+	checks.Check("counts the old commits", result.Records == 2 && result.Files == 2, result)
+	checks.Check("reports bytes it would reclaim", result.Reclaimed() > 2*2000, result.Reclaimed())
+	checks.Check("file untouched", size() == before, size())
+	checks.Check("diffs still in memory", s.GetByHash("old1").Files[0].Diff == diff, len(s.GetByHash("old1").Files[0].Diff))
+	dryReclaimed := result.Reclaimed()
+
+	fmt.Println("=== compact ===")
+	opts.DryRun = false
+	result, err = s.Compact(opts)
+	checks.Check("no error", err == nil, err)
+	checks.Check("stripped the old commits", result.Records == 2 && result.Files == 2, result)
+	checks.Check("sizes match the file", result.BytesBefore == before && result.BytesAfter == size(), result)
+	checks.Check("reclaimed what the dry run said", result.Reclaimed() == dryReclaimed, result.Reclaimed())
+
+	reloaded, err := store.New(historyPath)
+	if err != nil {
+		gittest.Fail("reload store", err)
+	}
+	old := reloaded.GetByHash("old1")
+	checks.Check("old diff gone", old != nil && old.Files[0].Diff == "" && old.Files[0].DiffCompacted, old)
+	checks.Check("old metadata kept", old != nil && old.Message == "feat: old1" && old.Files[0].LinesAdded == 1 &&
+		old.Files[0].DiffBytes == len(diff) && old.Files[0].HunkCount == 1, old)
+	checks.Check("file without a diff not marked", old != nil && !old.Files[1].DiffCompacted, old)
+	recent := reloaded.GetByHash("new1")
+	checks.Check("recent diff kept", recent != nil && recent.Files[0].Diff == diff && !recent.Files[0].DiffCompacted, recent)
+	checks.Check("stats unchanged", reloaded.Stats().TotalLinesAdded == 3, reloaded.Stats())
+
+	fmt.Println("=== again ===")
+	result, err = s.Compact(opts)
+	checks.Check("nothing left to strip", err == nil && result.Records == 0 && result.Reclaimed() == 0, result)
+	opts.OlderThan = 0
+	result, err = s.Compact(opts)
+	checks.Check("0 strips every commit", err == nil && result.Records == 1 && result.Reclaimed() > 2000, result)
+
+	fmt.Println("=== in memory ===")
+	mem := store.NewInMemory()
+	if _, err := mem.Import(records); err != nil {
+		gittest.Fail("import records", err)
+	}
+	result, err = mem.Compact(store.CompactOptions{StripDiffs: true})
+	checks.Check("strips in memory", err == nil && result.Records == 3 && mem.GetByHash("new1").Files[0].Diff == "", result)
+	checks.Check("no sizes in memory", result.BytesBefore == 0 && result.BytesAfter == 0, result)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll compaction checks passed.")
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+const conflicted = `package main
+
+func greet() string {
+<<<<<<< HEAD
+	return "hello"
+=======
+	return "hi"
+>>>>>>> feature
+}
+`
+
+const resolved = `package main
+
+func greet() string {
+	return "hello"
+}
+`
+
+// Flushes a file that still has merge-conflict markers next to a clean one:
+// non-interactively the conflicted file is skipped and the clean one
+// committed; interactively the flush pauses until the conflict is resolved.
+// A Markdown "=======" underline is not mistaken for a conflict:
+//
+//	go run ./cmd/testconflicts
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testconflicts")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	// ── Non-interactive: skip the conflicted file ──
+	fmt.Println("=== Non-interactive ===")
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "greet.go"), conflicted)
+	gittest.Write(filepath.Join(tmp, "README.md"), "Title\n=======\n\nhello\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "greet.go", Type: watcher.Created},
+		{Path: "README.md", Type: watcher.Modified},
+	}})
+	eng.Flush()
+	eng.Stop()
+
+	tracked := strings.Fields(gittest.Run(tmp, "git", "ls-files"))
+	checks.Check("conflicted file not committed", !strings.Contains(strings.Join(tracked, ","), "greet.go"), tracked)
+	checks.Check("clean file (with a ======= underline) committed", !strings.Contains(gittest.Run(tmp, "git", "status", "--porcelain"), "README.md"), gittest.Run(tmp, "git", "status", "--porcelain"))
+
+	// ── Interactive: pause, resolve, re-check ──
+	fmt.Println("\n=== Interactive ===")
+	stdin := make(chan string)
+	logger := ui.New(stdin)
+	eng, err = engine.New(cfg, logger)
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	eng.Interactive = true
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "greet.go", Type: watcher.Created}}})
+
+	done := make(chan struct{})
+	go func() {
+		eng.Flush()
+		close(done)
+	}()
+	// The flush blocks at the prompt until the user resolves the file
+	paused := false
+	for i := 0; i < 500 && !paused; i++ {
+		paused = logger.Prompting()
+		time.Sleep(10 * time.Millisecond)
+	}
+	checks.Check("flush pauses at the conflict prompt", paused, paused)
+	if !paused {
+		os.Exit(1)
+	}
+	gittest.Write(filepath.Join(tmp, "greet.go"), resolved)
+	stdin <- ""
+	<-done
+	eng.Stop()
+
+	committed, _ := exec.Command("git", "-C", tmp, "show", "HEAD:greet.go").Output()
+	checks.Check("resolved file committed after the pause", string(committed) == resolved, string(committed))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll conflict marker checks passed.")
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/dashboard"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// Checks the commit diff-size totals served by /api/history, the
+// dashboard's /healthz and /readyz probes against a good, a corrupt and a
+// repaired history file, with the auth token set, the filter for commits
+// that never got a blocking review, the date range and limit on a file's
+// commits, and a history rewrite that keeps the file's mtime and size:
+//
+//	go run ./cmd/testdashboard
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testdashboard")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	path := filepath.Join(tmp, "history.json")
+	s, err := store.New(path)
+	if err != nil {
+		gittest.Fail("open store", err)
+	}
+	if err := s.Save(store.CommitRecord{Hash: "abc1234", Message: "feat: one", Files: []store.FileChange{
+		{Path: "a.go", DiffBytes: 1200, HunkCount: 3},
+		{Path: "b.go", DiffBytes: 300, HunkCount: 1},
+	}}); err != nil {
+		gittest.Fail("save record", err)
+	}
+	good, err := os.ReadFile(path)
+	if err != nil {
+		gittest.Fail("read history", err)
+	}
+
+	srv := dashboard.NewServer(s, path)
+	srv.SetAuthToken("secret")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	fmt.Println("=== healthy ===")
+	checks.Check("healthz 200 without a token", status(ts.URL+"/healthz") == http.StatusOK, status(ts.URL+"/healthz"))
+	checks.Check("readyz 200 without a token", status(ts.URL+"/readyz") == http.StatusOK, status(ts.URL+"/readyz"))
+
+	fmt.Println("=== diff size ===")
+	history := getJSON[[]store.CommitRecord](ts.URL + "/api/history")
+	checks.Check("commit totals diff bytes", len(history) == 1 && history[0].DiffBytes == 1500, history)
+	checks.Check("commit totals hunks", len(history) == 1 && history[0].HunkCount == 4, history)
+
+	fmt.Println("=== corrupt history ===")
+	write(path, "{not json", time.Now().Add(time.Second))
+	checks.Check("readyz 503", status(ts.URL+"/readyz") == http.StatusServiceUnavailable, status(ts.URL+"/readyz"))
+	checks.Check("healthz still 200", status(ts.URL+"/healthz") == http.StatusOK, status(ts.URL+"/healthz"))
+
+	fmt.Println("=== repaired ===")
+	write(path, string(good), time.Now().Add(2*time.Second))
+	checks.Check("readyz 200 again", status(ts.URL+"/readyz") == http.StatusOK, status(ts.URL+"/readyz"))
+
+	fmt.Println("=== unreviewed ===")
+	if err := s.Save(store.CommitRecord{Hash: "def5678", Message: "fix: two", Review: &store.ReviewRecord{}, ReviewMode: store.ReviewModeBlocking}); err != nil {
+		gittest.Fail("save record", err)
+	}
+	if err := s.Save(store.CommitRecord{Hash: "0a1b2c3", Message: "docs: three", ReviewMode: store.ReviewModeSkipped, ReviewSkipReason: "docs/config changes only"}); err != nil {
+		gittest.Fail("save record", err)
+	}
+	history = getJSON[[]store.CommitRecord](ts.URL + "/api/history?unreviewed=1")
+	checks.Check("skipped and legacy unreviewed commits, newest first", len(history) == 2 && history[0].Hash == "0a1b2c3" && history[1].Hash == "abc1234", history)
+	checks.Check("skip reason served", len(history) == 2 && history[0].ReviewSkipReason == "docs/config changes only", history)
+	history = getJSON[[]store.CommitRecord](ts.URL + "/api/history?unreviewed=1&message=FEAT")
+	checks.Check("combined with the message filter", len(history) == 1 && history[0].Hash == "abc1234", history)
+	stats := getJSON[store.StoreStats](ts.URL + "/api/stats")
+	checks.Check("stats count unreviewed commits", stats.UnreviewedCommits == 2, stats.UnreviewedCommits)
+
+	fmt.Println("=== file history ===")
+	var file struct {
+		SchemaVersion int                  `json:"schema_version"`
+		Commits       []store.CommitRecord `json:"commits"`
+	}
+	if err := json.Unmarshal(good, &file); err != nil {
+		gittest.Fail("decode history", err)
+	}
+	file.Commits = nil
+	for i, day := range []string{"2026-01-01", "2026-02-01", "2026-03-01", "2026-04-01"} {
+		at, _ := time.ParseInLocation("2006-01-02 15:04", day+" 12:00", time.Local)
+		file.Commits = append(file.Commits, store.CommitRecord{
+			Hash:      fmt.Sprintf("f%d", i),
+			Message:   "feat: " + day,
+			CreatedAt: at,
+			Files:     []store.FileChange{{Path: "main.go"}},
+		})
+	}
+	file.Commits = append(file.Commits, store.CommitRecord{Hash: "other", CreatedAt: file.Commits[1].CreatedAt, Files: []store.FileChange{{Path: "other.go"}}})
+	data, err := json.Marshal(file)
+	if err != nil {
+		gittest.Fail("encode history", err)
+	}
+	write(path, string(data), time.Now().Add(3*time.Second))
+
+	hashes := func(query string) string {
+		var got []string
+		for _, r := range getJSON[[]store.CommitRecord](ts.URL + "/api/files?path=main.go" + query) {
+			got = append(got, r.Hash)
+		}
+		return strings.Join(got, ",")
+	}
+	checks.Check("newest first", hashes("") == "f3,f2,f1,f0", hashes(""))
+	checks.Check("limit", hashes("&limit=2") == "f3,f2", hashes("&limit=2"))
+	checks.Check("since and until dates, inclusive", hashes("&since=2026-02-01&until=2026-03-01") == "f2,f1", hashes("&since=2026-02-01&until=2026-03-01"))
+	checks.Check("since alone, with limit", hashes("&since=2026-02-01&limit=1") == "f3", hashes("&since=2026-02-01&limit=1"))
+	until := file.Commits[1].CreatedAt.Format(time.RFC3339)
+	checks.Check("until as RFC 3339", hashes("&until="+url.QueryEscape(until)) == "f1,f0", hashes("&until="+url.QueryEscape(until)))
+	checks.Check("empty range is []", hashes("&since=2027-01-01") == "", hashes("&since=2027-01-01"))
+	for _, bad := range []string{"&since=yesterday", "&until=2026-13-01", "&limit=0", "&limit=x"} {
+		code := status(ts.URL + "/api/files?path=main.go" + bad)
+		checks.Check("400 for "+bad, code == http.StatusBadRequest, code)
+	}
+
+	fmt.Println("=== rewritten with the same mtime and size ===")
+	info, err := os.Stat(path)
+	if err != nil {
+		gittest.Fail("stat history", err)
+	}
+	write(path, strings.Replace(string(data), `"f3"`, `"f9"`, 1), info.ModTime())
+	checks.Check("same-size rewrite picked up", hashes("") == "f9,f2,f1,f0", hashes(""))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll dashboard checks passed.")
+}
+
+// status GETs url and returns the response status code.
+func status(url string) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		gittest.Fail("GET "+url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// getJSON GETs url and decodes its JSON response.
+func getJSON[T any](url string) T {
+	var v T
+	resp, err := http.Get(url)
+	if err != nil {
+		gittest.Fail("GET "+url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		gittest.Fail("decode "+url, err)
+	}
+	return v
+}
+
+// write replaces path's content and sets its mod time, so the store's
+// change check sees it even within the filesystem's timestamp resolution.
+func write(path, content string, modTime time.Time) {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		gittest.Fail("write "+path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		gittest.Fail("touch "+path, err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// lumpingAI is the offline client with a refiner that puts every file in one
+// "chore: various changes" group, unless asked to split and willing to.
+type lumpingAI struct {
+	*ai.OfflineClient
+	splitsWhenAsked bool
+	calls           *int
+}
+
+func (c lumpingAI) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	*c.calls++
+	if hint != "" && c.splitsWhenAsked {
+		return c.RefineAndCommit(groups)
+	}
+	var all grouper.FileGroup
+	for _, g := range groups {
+		all.Files = append(all.Files, g.Files...)
+		all.Diffs += g.Diffs
+	}
+	all.Reason = "various changes"
+	all.CommitMessage = "chore: various changes"
+	return []grouper.FileGroup{all}, nil
+}
+
+// droppingAI is the offline client with a refiner that leaves out the last
+// file and invents one that wasn't changed.
+type droppingAI struct {
+	*ai.OfflineClient
+}
+
+func (c droppingAI) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	refined, err := c.OfflineClient.RefineWithHint(groups, hint)
+	if err != nil {
+		return nil, err
+	}
+	last := &refined[len(refined)-1]
+	last.Files = append(last.Files[:len(last.Files)-1:len(last.Files)-1], "ghost.go")
+	return refined, nil
+}
+
+// Feeds one-group "various changes" AI replies for a flush touching three
+// unrelated directories: with reject_degenerate_grouping the AI is asked
+// again, and its focused answer is used; if it lumps everything again, the
+// heuristic groups are committed. Turned off, the single commit is kept. A
+// reply that omits a file and names one that wasn't changed still commits
+// every changed file and nothing else:
+//
+//	go run ./cmd/testdegenerate
+func main() {
+	checks := &gittest.Checks{}
+
+	cases := []struct {
+		name            string
+		reject          bool
+		splitsWhenAsked bool
+		commits, calls  int
+		grouping        string
+	}{
+		{"re-prompt splits", true, true, 3, 2, store.GroupingAISplit},
+		{"still lumped: heuristic groups", true, false, 3, 2, store.GroupingHeuristic},
+		{"rejection off", false, false, 1, 1, store.GroupingAI},
+	}
+	for _, c := range cases {
+		fmt.Printf("=== %s ===\n", c.name)
+		calls := 0
+		records := flush(c.reject, lumpingAI{ai.NewOfflineClient(), c.splitsWhenAsked, &calls})
+		checks.Check("commits", len(records) == c.commits, len(records))
+		checks.Check("refine calls", calls == c.calls, calls)
+		grouping := ""
+		if len(records) > 0 {
+			grouping = records[0].Grouping
+		}
+		checks.Check("grouping recorded", grouping == c.grouping, grouping)
+		generic := false
+		for _, r := range records {
+			generic = generic || strings.Contains(r.Message, "various changes")
+		}
+		checks.Check("generic message kept only when rejection is off", generic == !c.reject, generic)
+	}
+
+	fmt.Println("=== AI reply omits a file ===")
+	var committed []string
+	for _, r := range flush(false, droppingAI{ai.NewOfflineClient()}) {
+		for _, f := range r.Files {
+			committed = append(committed, f.Path)
+		}
+	}
+	sort.Strings(committed)
+	checks.Check("every changed file committed once, invented one dropped", strings.Join(committed, ",") == "api/handler.go,api/routes.go,docs/guide.txt,web/app.js", committed)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll degenerate grouping checks passed.")
+}
+
+// flush commits changes in api/, docs/ and web/ in a fresh repo through an
+// engine using client, and returns the commit records.
+func flush(reject bool, client engine.AIClient) []store.CommitRecord {
+	tmp, err := os.MkdirTemp("", "gitpulse-testdegenerate")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.CodeReview = false
+	cfg.RejectDegenerateGrouping = reject
+
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, client)
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+
+	files := []string{"api/handler.go", "api/routes.go", "docs/guide.txt", "web/app.js"}
+	var changes []watcher.FileChange
+	for _, f := range files {
+		gittest.Write(filepath.Join(tmp, f), "// "+f+"\n")
+		changes = append(changes, watcher.FileChange{Path: f, Type: watcher.Created})
+	}
+	eng.Submit(watcher.ChangeSet{Files: changes})
+	eng.Flush()
+
+	s, err := store.New(filepath.Join(tmp, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	return s.All()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// fileCount is how many files each benchmarked flush modifies.
+const fileCount = 60
+
+// Benchmarks flushes that modify many files with diff_concurrency 1 and the
+// default, which fetch the per-file diffs serially and on a worker pool.
+// Prints the time per flush for each; how much the pool helps depends on the
+// machine's cores, so only that every flush commits every file is checked:
+//
+//	go run ./cmd/testdiffconcurrency
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testdiffconcurrency")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	var files []string
+	for i := 0; i < fileCount; i++ {
+		f := fmt.Sprintf("pkg%d/file%d.go", i%6, i)
+		files = append(files, f)
+		gittest.Write(filepath.Join(tmp, f), fmt.Sprintf("package pkg%d\n", i%6))
+	}
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.CodeReview = false
+
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+
+	round := 0
+	for _, workers := range []int{1, cfg.DiffConcurrency} {
+		fmt.Printf("=== diff_concurrency: %d ===\n", workers)
+		cfg.DiffConcurrency = workers
+		eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, ai.NewOfflineClient())
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		clean := true
+		result := testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				round++
+				var changes []watcher.FileChange
+				for _, f := range files {
+					pkg := filepath.Base(filepath.Dir(f))
+					gittest.Write(filepath.Join(tmp, f), fmt.Sprintf("package %s\n\nvar round = %d\n", pkg, round))
+					changes = append(changes, watcher.FileChange{Path: f, Type: watcher.Modified})
+				}
+				eng.Submit(watcher.ChangeSet{Files: changes})
+				b.StartTimer()
+
+				eng.Flush()
+
+				b.StopTimer()
+				clean = clean && strings.TrimSpace(gittest.Run(tmp, "git", "status", "--porcelain", "--", "pkg0", "pkg1", "pkg2", "pkg3", "pkg4", "pkg5")) == ""
+				b.StartTimer()
+			}
+		})
+		eng.Stop()
+		fmt.Printf("  %d files: %v per flush (%d flushes)\n", fileCount, result.T/time.Duration(result.N), result.N)
+		checks.Check("every file committed by every flush", clean, clean)
+	}
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll diff concurrency checks passed.")
+}
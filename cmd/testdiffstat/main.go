@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Checks Manager.DiffStat on a root commit, then flushes an edit, a rename,
+// a new binary and a deletion and checks the stats stored for each file
+// come from git's tree comparison:
+//
+//	go run ./cmd/testdiffstat
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testdiffstat")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	body := "package pkg\n\n" + strings.Repeat("// a comment long enough to tell the file apart\n", 20)
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "pkg", "edit.go"), "package pkg\n\nvar a = 1\nvar b = 2\n")
+	gittest.Write(filepath.Join(tmp, "pkg", "old.go"), body)
+	gittest.Write(filepath.Join(tmp, "pkg", "gone.go"), "package pkg\n\nvar gone = 1\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	fmt.Println("=== root commit ===")
+	repo, err := git.New(tmp, "origin", "auto")
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	stats, err := repo.DiffStat(strings.TrimSpace(gittest.Run(tmp, "git", "rev-parse", "HEAD")))
+	checks.Check("every file added", err == nil && len(stats) == 3 && stats[0].Status == "added" && stats[0].Added == 4, stats)
+
+	fmt.Println("=== flush ===")
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+
+	gittest.Write(filepath.Join(tmp, "pkg", "edit.go"), "package pkg\n\nvar a = 10\nvar b = 2\nvar c = 3\n")
+	if err := os.Rename(filepath.Join(tmp, "pkg", "old.go"), filepath.Join(tmp, "pkg", "new.go")); err != nil {
+		gittest.Fail("rename", err)
+	}
+	gittest.Write(filepath.Join(tmp, "pkg", "logo.png"), "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if err := os.Remove(filepath.Join(tmp, "pkg", "gone.go")); err != nil {
+		gittest.Fail("remove", err)
+	}
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "pkg/edit.go", Type: watcher.Modified},
+		{Path: "pkg/old.go", Type: watcher.Deleted},
+		{Path: "pkg/new.go", Type: watcher.Created},
+		{Path: "pkg/logo.png", Type: watcher.Created},
+		{Path: "pkg/gone.go", Type: watcher.Deleted},
+	}})
+	eng.Flush()
+
+	s, err := store.New(filepath.Join(tmp, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	files := make(map[string]store.FileChange)
+	for _, r := range s.All() {
+		for _, fc := range r.Files {
+			files[fc.Path] = fc
+		}
+	}
+	edit := files["pkg/edit.go"]
+	checks.Check("edit counted", edit.Status == "modified" && edit.LinesAdded == 2 && edit.LinesRemoved == 1, edit)
+	checks.Check("edit keeps its diff", strings.Contains(edit.Diff, "+var c = 3"), edit.Diff)
+	renamed := files["pkg/new.go"]
+	checks.Check("rename detected", renamed.Status == "renamed" && renamed.OldPath == "pkg/old.go" && renamed.LinesAdded == 0, renamed)
+	_, oldListed := files["pkg/old.go"]
+	checks.Check("old path folded into the rename", !oldListed, files["pkg/old.go"])
+	logo := files["pkg/logo.png"]
+	checks.Check("binary flagged", logo.Status == "added" && logo.Binary, logo)
+	gone := files["pkg/gone.go"]
+	checks.Check("deletion counted", gone.Status == "deleted" && gone.LinesRemoved == 3, gone)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll diff stat checks passed.")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Flushes Create events for new empty directories: by default they're
+// skipped without a staging error; with auto_gitkeep each empty leaf gets a
+// .gitkeep that is committed alongside the other changes:
+//
+//	go run ./cmd/testemptydir
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testemptydir")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	// ── Default: empty directories are skipped ──
+	fmt.Println("=== auto_gitkeep: false ===")
+	head := strings.TrimSpace(gittest.Run(tmp, "git", "rev-parse", "HEAD"))
+	mkdir(filepath.Join(tmp, "empty"))
+	flush(cfg, watcher.FileChange{Path: "empty", Type: watcher.Created})
+	checks.Check("no commit for an empty directory", strings.TrimSpace(gittest.Run(tmp, "git", "rev-parse", "HEAD")) == head, gittest.Run(tmp, "git", "log", "--oneline"))
+	_, err = os.Stat(filepath.Join(tmp, "empty", ".gitkeep"))
+	checks.Check("no .gitkeep created", os.IsNotExist(err), err)
+
+	mkdir(filepath.Join(tmp, "pkg"))
+	gittest.Write(filepath.Join(tmp, "pkg", "a.go"), "package pkg\n")
+	flush(cfg,
+		watcher.FileChange{Path: "pkg", Type: watcher.Created},
+		watcher.FileChange{Path: filepath.Join("pkg", "a.go"), Type: watcher.Created})
+	files := gittest.Run(tmp, "git", "show", "--name-only", "--format=", "HEAD")
+	checks.Check("file in a new directory committed", strings.TrimSpace(files) == "pkg/a.go", files)
+
+	// ── auto_gitkeep: a .gitkeep goes in each empty leaf ──
+	fmt.Println("=== auto_gitkeep: true ===")
+	cfg.AutoGitkeep = true
+	mkdir(filepath.Join(tmp, "assets", "img", "icons"))
+	mkdir(filepath.Join(tmp, "assets", "fonts"))
+	gittest.Write(filepath.Join(tmp, "main.go"), "package main\n")
+	flush(cfg,
+		watcher.FileChange{Path: "assets", Type: watcher.Created},
+		watcher.FileChange{Path: "empty", Type: watcher.Created},
+		watcher.FileChange{Path: "main.go", Type: watcher.Created})
+	tracked := gittest.Run(tmp, "git", "ls-files")
+	checks.Check("nested empty leaf tracked", strings.Contains(tracked, "assets/img/icons/.gitkeep\n"), tracked)
+	checks.Check("sibling empty leaf tracked", strings.Contains(tracked, "assets/fonts/.gitkeep\n"), tracked)
+	checks.Check("no .gitkeep in a non-empty parent", !strings.Contains(tracked, "assets/img/.gitkeep"), tracked)
+	checks.Check("earlier skipped directory tracked", strings.Contains(tracked, "empty/.gitkeep\n"), tracked)
+	checks.Check("regular file committed too", strings.Contains(tracked, "main.go\n"), tracked)
+	status := gittest.Run(tmp, "git", "status", "--porcelain", "--", ".", ":!.gitpulse")
+	checks.Check("working tree clean", strings.TrimSpace(status) == "", status)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll empty directory checks passed.")
+}
+
+// flush runs one engine flush over changes.
+func flush(cfg *config.Config, changes ...watcher.FileChange) {
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	eng.Submit(watcher.ChangeSet{Files: changes})
+	eng.Flush()
+	eng.Stop()
+}
+
+func mkdir(path string) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		gittest.Fail("mkdir "+path, err)
+	}
+}
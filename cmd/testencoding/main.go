@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// fixingAI is the offline client with a reviewer that flags a "BUG" in each
+// of files on its first review and passes after that, recording the files
+// each review was sent, and a fixer that replaces the BUG, recording the
+// content it was given per file.
+type fixingAI struct {
+	*ai.OfflineClient
+	files    []string
+	reviews  int
+	reviewed [][]string
+	got      map[string]string
+}
+
+func (c *fixingAI) ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error) {
+	c.reviews++
+	var files []string
+	for _, g := range groups {
+		files = append(files, g.Files...)
+	}
+	sort.Strings(files)
+	c.reviewed = append(c.reviewed, files)
+	if c.reviews > 1 {
+		return &ai.ReviewResult{}, nil
+	}
+	result := &ai.ReviewResult{HasBlockers: true}
+	for _, f := range c.files {
+		result.Findings = append(result.Findings, ai.ReviewFinding{
+			File:        f,
+			StartLine:   1,
+			EndLine:     1,
+			Severity:    ai.SeverityError,
+			Description: "BUG left in",
+		})
+	}
+	return result, nil
+}
+
+func (c *fixingAI) GenerateFix(filePath string, finding ai.ReviewFinding, primaryContent string, relatedContents map[string]string) (string, error) {
+	c.got[filePath] = primaryContent
+	return strings.Replace(primaryContent, "BUG", "FIXED", 1), nil
+}
+
+const (
+	bom    = "\xEF\xBB\xBF"
+	bomGo  = bom + "package main // BUG\n"
+	latin  = "package main // caf\xe9 BUG\n" // Latin-1 é
+	binary = "package main\x00 // BUG\n"
+	script = "#!/bin/sh\n# BUG\n"
+)
+
+// Lets the AI fix a blocker in a UTF-8 file with a BOM, a Latin-1 file, a
+// binary one and an executable script: the BOM, the Latin-1 encoding and the
+// script's executable bit survive the fix, the AI only ever sees UTF-8 text,
+// and the binary file is left alone, its blocker carried over to a second
+// prompt without sending it to the reviewer again. Then checks
+// that with fallback_encoding: none the Latin-1 diff isn't sent or stored:
+//
+//	go run ./cmd/testencoding
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testencoding")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.ReviewMinLines = 0
+
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+
+	// ── AI fix: BOM, Latin-1 and binary files ──
+	fmt.Println("=== AI fix ===")
+	fixer := &fixingAI{OfflineClient: ai.NewOfflineClient(), files: []string{"bom.go", "latin.go", "blob.go", "run.sh"}, got: map[string]string{}}
+	stdin := make(chan string)
+	logger := ui.New(stdin)
+	eng, err := engine.NewWithDeps(cfg, logger, repo, fixer)
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	eng.Interactive = true
+	gittest.Write(filepath.Join(tmp, "bom.go"), bomGo)
+	gittest.Write(filepath.Join(tmp, "latin.go"), latin)
+	gittest.Write(filepath.Join(tmp, "blob.go"), binary)
+	gittest.Write(filepath.Join(tmp, "run.sh"), script)
+	if err := os.Chmod(filepath.Join(tmp, "run.sh"), 0755); err != nil {
+		gittest.Fail("chmod run.sh", err)
+	}
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "bom.go", Type: watcher.Created},
+		{Path: "latin.go", Type: watcher.Created},
+		{Path: "blob.go", Type: watcher.Created},
+		{Path: "run.sh", Type: watcher.Created},
+	}})
+
+	done := make(chan struct{})
+	go func() {
+		eng.Flush()
+		close(done)
+	}()
+	// Answer the review prompt with "Let AI fix"
+	prompted := false
+	for i := 0; i < 500 && !prompted; i++ {
+		prompted = logger.Prompting()
+		time.Sleep(10 * time.Millisecond)
+	}
+	checks.Check("review prompt shown", prompted, prompted)
+	if !prompted {
+		os.Exit(1)
+	}
+	stdin <- "2"
+	// The binary file is left unfixed, so its blocker carries over to the
+	// re-review and the prompt comes back: continue anyway
+	reprompted := false
+	select {
+	case stdin <- "3":
+		reprompted = true
+		<-done
+	case <-done:
+	}
+	eng.Stop()
+
+	checks.Check("unfixed binary blocker carried over, prompted again", reprompted, reprompted)
+	var rereviewed []string
+	if len(fixer.reviewed) == 2 {
+		rereviewed = fixer.reviewed[1]
+	}
+	checks.Check("re-review sent only the fixed files", strings.Join(rereviewed, ",") == "bom.go,latin.go,run.sh", fixer.reviewed)
+
+	checks.Check("AI got the BOM file without its BOM", fixer.got["bom.go"] == "package main // BUG\n", fixer.got["bom.go"])
+	checks.Check("AI got the Latin-1 file as UTF-8", fixer.got["latin.go"] == "package main // café BUG\n", fixer.got["latin.go"])
+	_, sent := fixer.got["blob.go"]
+	checks.Check("binary file not sent for a fix", !sent, fixer.got["blob.go"])
+
+	committed := func(path string) string {
+		out, _ := exec.Command("git", "-C", tmp, "show", "HEAD:"+path).Output()
+		return string(out)
+	}
+	checks.Check("BOM survives the fix", committed("bom.go") == bom+"package main // FIXED\n", committed("bom.go"))
+	checks.Check("Latin-1 encoding survives the fix", committed("latin.go") == "package main // caf\xe9 FIXED\n", committed("latin.go"))
+	checks.Check("binary file left alone", committed("blob.go") == binary, committed("blob.go"))
+	checks.Check("script fixed", committed("run.sh") == "#!/bin/sh\n# FIXED\n", committed("run.sh"))
+	var perm os.FileMode
+	if info, err := os.Stat(filepath.Join(tmp, "run.sh")); err == nil {
+		perm = info.Mode().Perm()
+	}
+	checks.Check("script still executable after the fix", perm == 0755, perm)
+	mode := strings.Fields(gittest.Run(tmp, "git", "ls-files", "-s", "run.sh"))
+	checks.Check("script committed as 100755", len(mode) > 0 && mode[0] == "100755", mode)
+
+	historyPath := filepath.Join(tmp, ".gitpulse", "history.json")
+	s, err := store.New(historyPath)
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	latinDiff := fileDiff(s, "latin.go")
+	checks.Check("Latin-1 diff stored as UTF-8", utf8.ValidString(latinDiff) && strings.Contains(latinDiff, "café"), latinDiff)
+
+	// ── fallback_encoding: none ──
+	fmt.Println("\n=== fallback_encoding: none ===")
+	cfg.FallbackEncoding = config.EncodingNone
+	cfg.AI.CodeReview = false
+	eng, err = engine.NewWithDeps(cfg, ui.New(nil), repo, &fixingAI{OfflineClient: ai.NewOfflineClient(), got: map[string]string{}})
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "other.go"), "package main // ol\xe9\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "other.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.Stop()
+
+	checks.Check("file still committed", committed("other.go") == "package main // ol\xe9\n", committed("other.go"))
+	s, err = store.New(historyPath)
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	otherDiff := fileDiff(s, "other.go")
+	checks.Check("diff replaced by a placeholder", strings.Contains(otherDiff, "not text in a supported encoding") && !strings.Contains(otherDiff, "ol"), otherDiff)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll encoding checks passed.")
+}
+
+// fileDiff returns the stored diff of path from the newest commit touching it.
+func fileDiff(s *store.Store, path string) string {
+	records := s.GetByFile(path)
+	if len(records) == 0 {
+		return ""
+	}
+	for _, f := range records[len(records)-1].Files {
+		if f.Path == path {
+			return f.Diff
+		}
+	}
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/firasastwani/gitpulse/internal/config"
 	"github.com/firasastwani/gitpulse/internal/engine"
@@ -27,7 +28,7 @@ func main() {
 		fmt.Fprintln(os.Stderr, "No API key found. Set CLAUDE_API_KEY in .env")
 		os.Exit(1)
 	}
-	logger.Info("Config loaded", "model", cfg.AI.Model, "auto_push", cfg.AutoPush)
+	logger.Info("Config loaded", "model", cfg.AI.Model, "push_mode", cfg.PushMode)
 
 	// ── Detect real changed files ──
 	cmd := exec.Command("git", "status", "--porcelain")
@@ -83,18 +84,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Start engine so it can buffer, then manually flush
-	go eng.Run()
+	// Feed the detected changes in as if the watcher had emitted them,
+	// then flush — no need to start the watcher loop
+	eng.Submit(watcher.ChangeSet{Files: changes, Timestamp: time.Now()})
 
-	// Simulate the watcher having buffered these changes
-	// by sending them to the engine's watcher events channel
-	// Instead, directly test Flush after the watcher buffers
 	logger.Info("Flushing engine pipeline...")
-
-	// We can't inject into the watcher, so just use Flush with pending set externally
-	// For a clean test, just build and use `gitpulse` + `gitpulse push` flow
-	_ = changes
 	eng.Flush()
+	eng.Stop()
 
 	logger.Info("=== Engine Pipeline Test Complete ===")
 }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// slowRepo is the real repo with slow commits that count how many run at
+// once, so overlapping pipelines can't go unnoticed.
+type slowRepo struct {
+	*git.Manager
+	active, maxActive int32
+	entered           chan struct{} // signalled when a commit starts
+}
+
+func (r *slowRepo) enter() {
+	n := atomic.AddInt32(&r.active, 1)
+	for {
+		max := atomic.LoadInt32(&r.maxActive)
+		if n <= max || atomic.CompareAndSwapInt32(&r.maxActive, max, n) {
+			break
+		}
+	}
+	select {
+	case r.entered <- struct{}{}:
+	default:
+	}
+	time.Sleep(300 * time.Millisecond)
+}
+
+func (r *slowRepo) leave() { atomic.AddInt32(&r.active, -1) }
+
+func (r *slowRepo) Commit(message string) (string, error) {
+	r.enter()
+	defer r.leave()
+	return r.Manager.Commit(message)
+}
+
+func (r *slowRepo) CommitFilesAt(files []string, message string, authorTime time.Time) (string, error) {
+	r.enter()
+	defer r.leave()
+	return r.Manager.CommitFilesAt(files, message, authorTime)
+}
+
+// Fires the safety timer's flush and a manual push at the same moment, then
+// more triggers and saves while the first flush is committing: only one
+// pipeline may run at a time, and the late triggers share one more flush
+// that picks up everything saved meanwhile:
+//
+//	go run ./cmd/testflushqueue
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testflushqueue")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	cfg.CommitImmediatelyPatterns = []string{"*.lock"}
+
+	mgr, err := git.New(tmp, "origin", "main")
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	repo := &slowRepo{Manager: mgr, entered: make(chan struct{}, 1)}
+	eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, ai.NewOfflineClient())
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+
+	var flushes int32
+	events := eng.Subscribe()
+	go func() {
+		for ev := range events {
+			if ev.Type == engine.EventFlushStart {
+				atomic.AddInt32(&flushes, 1)
+			}
+		}
+	}()
+
+	fmt.Println("=== safety timer and gitpulse push at once ===")
+	gittest.Write(filepath.Join(tmp, "a.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "a.go", Type: watcher.Created}}})
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	trigger := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			f()
+		}()
+	}
+	trigger(eng.Flush)   // safety timer
+	trigger(eng.PushNow) // gitpulse push
+	close(start)
+	<-repo.entered
+
+	fmt.Println("=== more saves and triggers mid-flush ===")
+	gittest.Write(filepath.Join(tmp, "b.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "b.go", Type: watcher.Created}}})
+	gittest.Write(filepath.Join(tmp, "deps.lock"), "v1\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "deps.lock", Type: watcher.Created}}})
+	checks.Check("commit_immediately file buffered while a flush runs", eng.PendingCount() == 2, eng.PendingCount())
+	start = make(chan struct{})
+	for i := 0; i < 3; i++ {
+		trigger(eng.Flush)
+	}
+	close(start)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		gittest.Fail("wait for flushes", fmt.Errorf("timed out"))
+	}
+	time.Sleep(50 * time.Millisecond) // let the last events arrive
+
+	checks.Check("never two commits at once", atomic.LoadInt32(&repo.maxActive) == 1, atomic.LoadInt32(&repo.maxActive))
+	checks.Check("late triggers coalesced into one flush", atomic.LoadInt32(&flushes) == 2, atomic.LoadInt32(&flushes))
+	checks.Check("nothing left pending", eng.PendingCount() == 0, eng.PendingCount())
+	status := strings.TrimSpace(gittest.Run(tmp, "git", "status", "--porcelain", "--", "a.go", "b.go", "deps.lock"))
+	checks.Check("everything committed", status == "", status)
+	index := gittest.Run(tmp, "git", "ls-files", "--stage")
+	checks.Check("index intact", strings.Count(index, "\n") == 4, index)
+
+	fmt.Println("=== a flush on its own ===")
+	gittest.Write(filepath.Join(tmp, "c.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "c.go", Type: watcher.Created}}})
+	eng.Flush()
+	status = strings.TrimSpace(gittest.Run(tmp, "git", "status", "--porcelain", "--", "c.go"))
+	checks.Check("queue released afterwards", status == "", status)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll flush queue checks passed.")
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Tries to amend a commit that was already pushed (amend_window_seconds):
+// without allow_force_push, unattended, or declined at the prompt it gets a
+// new commit on top instead; confirmed, the commit is amended and
+// force-pushed over the remote's copy, keeping its author date, and its
+// record keeps the original commit time and branch. Uses a local bare remote:
+//
+//	go run ./cmd/testforcepush
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testforcepush")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	r, err := gittest.New(tmp)
+	if err != nil {
+		gittest.Fail("set up remote", err)
+	}
+	repo, err := r.Manager()
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	cfg, err := config.LoadFromDir(r.Dir, r.Dir)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = ai.ProviderNone
+	cfg.PushMode = config.PushModeAuto
+	cfg.AmendWindowSeconds = 3600
+
+	// flush commits path with content, answering each prompt in turn when
+	// interactive, and returns the log
+	flush := func(path, content string, interactive bool, answers ...string) string {
+		var out bytes.Buffer
+		stdin := make(chan string)
+		logger := ui.New(stdin)
+		logger.SetOutput(&out)
+		eng, err := engine.NewWithDeps(cfg, logger, repo, ai.NewOfflineClient())
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		eng.Interactive = interactive
+		gittest.Write(filepath.Join(r.Dir, path), content)
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: path, Type: watcher.Modified}}})
+
+		done := make(chan struct{})
+		go func() {
+			eng.Flush()
+			close(done)
+		}()
+		for {
+			if logger.Prompting() && len(answers) > 0 {
+				stdin <- answers[0]
+				answers = answers[1:]
+			}
+			select {
+			case <-done:
+				eng.Stop()
+				return out.String()
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+	head := func() string {
+		h, err := r.Head()
+		if err != nil {
+			gittest.Fail("read HEAD", err)
+		}
+		return h
+	}
+	remoteHead := func() string {
+		h, err := r.RemoteHead()
+		if err != nil {
+			gittest.Fail("read remote HEAD", err)
+		}
+		return h
+	}
+	// pushed commits path and pushes it, returning the hash
+	pushed := func(path string) string {
+		flush(path, "v1\n", false)
+		if head() != remoteHead() {
+			gittest.Fail("push "+path, fmt.Errorf("remote at %s, local at %s", remoteHead(), head()))
+		}
+		return head()
+	}
+	// record returns the stored record for hash
+	record := func(hash string) store.CommitRecord {
+		s, err := store.New(filepath.Join(r.Dir, ".gitpulse", "history.json"))
+		if err != nil {
+			gittest.Fail("open history", err)
+		}
+		if rec := s.GetByHash(hash); rec != nil {
+			return *rec
+		}
+		gittest.Fail("find record", fmt.Errorf("no record for %s", hash))
+		return store.CommitRecord{}
+	}
+	// dates returns hash's author (with its date) and committer date
+	dates := func(hash string) (author, committed string) {
+		out, err := r.Git("log", "-1", "--format=%an <%ae> %at%n%ct", hash)
+		if err != nil {
+			gittest.Fail("read dates", err)
+		}
+		author, committed, _ = strings.Cut(out, "\n")
+		return author, committed
+	}
+	// amended reports whether HEAD replaced hash rather than building on it
+	amended := func(hash string) bool {
+		parent, _ := r.Git("rev-parse", "HEAD^")
+		return head() != hash && parent != hash
+	}
+
+	fmt.Println("=== allow_force_push off ===")
+	first := pushed("a.txt")
+	log := flush("a.txt", "v2\n", false)
+	checks.Check("pushed commit not amended", !amended(first) && head() != first, log)
+	checks.Check("says why", strings.Contains(log, "allow_force_push is off"), log)
+	checks.Check("new commit pushed normally", head() == remoteHead(), remoteHead())
+
+	fmt.Println("=== allow_force_push, unattended ===")
+	cfg.AllowForcePush = true
+	first = pushed("b.txt")
+	log = flush("b.txt", "v2\n", false)
+	checks.Check("not amended without a prompt", !amended(first) && head() != first, log)
+	checks.Check("warns", strings.Contains(log, "without confirmation"), log)
+
+	fmt.Println("=== allow_force_push, declined ===")
+	first = pushed("c.txt")
+	// First interactive push asks to confirm the push target too
+	log = flush("c.txt", "v2\n", true, "n", "y")
+	checks.Check("asked before force-pushing", strings.Contains(log, "Amend and force-push?"), log)
+	checks.Check("not amended when declined", !amended(first) && head() != first, log)
+	checks.Check("new commit pushed normally", head() == remoteHead(), remoteHead())
+
+	fmt.Println("=== allow_force_push, confirmed ===")
+	first = pushed("d.txt")
+	original := record(first)
+	// A second apart, so an amend that moves the author date shows
+	time.Sleep(1100 * time.Millisecond)
+	log = flush("d.txt", "v2\n", true, "y")
+	checks.Check("amended", amended(first), log)
+	checks.Check("force-pushed", strings.Contains(log, "Force-pushed over"), log)
+	checks.Check("force-pushed over the remote's copy", head() == remoteHead(), remoteHead())
+	_, err = r.Git("merge-base", "--is-ancestor", first, remoteHead())
+	checks.Check("old commit gone from the remote branch", err != nil, first)
+	content, _ := r.Git("show", "HEAD:d.txt")
+	checks.Check("amended commit has the new content", content == "v2", content)
+	author, committed := dates(first)
+	newAuthor, newCommitted := dates(head())
+	checks.Check("author and author date kept", newAuthor == author, newAuthor+" vs "+author)
+	checks.Check("committer date moved on", newCommitted > committed, newCommitted+" vs "+committed)
+	rec := record(head())
+	checks.Check("record keeps the original commit time", rec.CreatedAt.Equal(original.CreatedAt), rec.CreatedAt)
+	checks.Check("record keeps the branch", rec.Branch == original.Branch && rec.Branch == gittest.Branch, rec.Branch)
+	checks.Check("record pushed by the force push", rec.Pushed, rec.Pushed)
+
+	fmt.Println("=== pushing on top of the rewritten branch ===")
+	cfg.AmendWindowSeconds = 0
+	log = flush("e.txt", "v1\n", false)
+	checks.Check("plain push, no force", head() == remoteHead() && !strings.Contains(log, "Force-pushed over"), log)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll force-push checks passed.")
+}
@@ -3,18 +3,32 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
-	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
 )
 
+// Stages, commits and pushes three files through git.Manager against a
+// throwaway repo and local bare remote (internal/git/gittest), so it never
+// touches this repository or a real origin:
+//
+//	go run ./cmd/testgit
 func main() {
-	repoPath := "."
-	remote := "origin"
-	branch := "main"
+	tmp, err := os.MkdirTemp("", "gitpulse-testgit")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "MkdirTemp failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmp)
 
 	// Step 1: Open repo
 	fmt.Println("Opening repo...")
-	mgr, err := git.New(repoPath, remote, branch)
+	r, err := gittest.New(tmp)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Setting up remote failed: %v\n", err)
+		os.Exit(1)
+	}
+	mgr, err := r.Manager()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "New failed: %v\n", err)
 		os.Exit(1)
@@ -27,6 +41,18 @@ func main() {
 		"internal/watcher/watcher.go",
 		"internal/grouper/grouper.go",
 	}
+	for _, f := range files {
+		full := filepath.Join(r.Dir, f)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "MkdirAll failed: %v\n", err)
+			os.Exit(1)
+		}
+		pkg := filepath.Base(filepath.Dir(f))
+		if err := os.WriteFile(full, []byte("package "+pkg+"\n"), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "WriteFile failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	fmt.Println("Staging files...")
 	if err := mgr.StageFiles(files); err != nil {
 		fmt.Fprintf(os.Stderr, "StageFiles failed: %v\n", err)
@@ -59,5 +85,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Push failed: %v\n", err)
 		os.Exit(1)
 	}
+	remoteHead, err := r.RemoteHead()
+	if err != nil || remoteHead != hash {
+		fmt.Fprintf(os.Stderr, "Remote is at %s, want %s (%v)\n", remoteHead, hash, err)
+		os.Exit(1)
+	}
 	fmt.Println("Pushed successfully!")
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+)
+
+// Provokes each git failure GitPulse distinguishes and checks that Manager
+// returns the matching sentinel error (errors.Is). Everything is local — the
+// "auth" remote is an HTTP server that answers 401:
+//
+//	go run ./cmd/testgiterrors
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testgiterrors")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// Never block on a credential prompt
+	os.Setenv("GIT_TERMINAL_PROMPT", "0")
+
+	checks := &gittest.Checks{}
+	check := func(name string, err, want error) {
+		checks.Check(name, errors.Is(err, want), err)
+	}
+
+	// ── ErrNothingToCommit ──
+	fmt.Println("=== Nothing to commit ===")
+	repo := setup(tmp, "clean")
+	m := manager(repo)
+	_, err = m.Commit("chore: nothing")
+	check("commit with a clean index", err, git.ErrNothingToCommit)
+
+	// ── ErrNoRemote ──
+	fmt.Println("\n=== No remote ===")
+	err = m.Push()
+	check("push without an origin remote", err, git.ErrNoRemote)
+	gittest.Run(repo, "git", "remote", "add", "origin", filepath.Join(tmp, "missing.git"))
+	err = m.Push()
+	check("push to a remote path that isn't a repo", err, git.ErrNoRemote)
+
+	// ── ErrNonFastForward ──
+	fmt.Println("\n=== Non-fast-forward ===")
+	r, err := gittest.New(filepath.Join(tmp, "diverged"))
+	if err != nil {
+		gittest.Fail("set up remote", err)
+	}
+	if _, err := r.PushElsewhere("theirs.txt", "theirs.txt\n", "add theirs.txt"); err != nil {
+		gittest.Fail("push from another clone", err)
+	}
+	if _, err := r.Commit("ours.txt", "ours.txt\n", "add ours.txt"); err != nil {
+		gittest.Fail("commit", err)
+	}
+	err = manager(r.Dir).Push()
+	check("push when the remote has moved", err, git.ErrNonFastForward)
+
+	// ── ErrAuthFailed ──
+	fmt.Println("\n=== Auth failed ===")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="gitpulse-test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	repo = setup(tmp, "auth")
+	gittest.Run(repo, "git", "config", "credential.helper", "")
+	gittest.Run(repo, "git", "remote", "add", "origin", srv.URL+"/repo.git")
+	err = manager(repo).Push()
+	check("push to a remote that answers 401", err, git.ErrAuthFailed)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll git error checks passed.")
+}
+
+// setup creates repo name on branch main with one commit.
+func setup(tmp, name string) string {
+	repo := filepath.Join(tmp, name)
+	gittest.InitRepo(repo)
+	commitFile(repo, "README.md")
+	return repo
+}
+
+func commitFile(repo, name string) {
+	gittest.Write(filepath.Join(repo, name), name+"\n")
+	gittest.Run(repo, "git", "add", name)
+	gittest.Run(repo, "git", "commit", "-q", "-m", "add "+name)
+}
+
+func manager(repo string) *git.Manager {
+	m, err := git.New(repo, "origin", "auto")
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	return m
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// typingAI is the offline client with a fixed classification, standing in
+// for the model.
+type typingAI struct {
+	*ai.OfflineClient
+	types   map[string]string
+	fail    bool
+	hint    string
+	lumpAll bool // answer the refine call with everything in one group
+}
+
+func (c *typingAI) ClassifyChanges(groups []grouper.FileGroup) (map[string]string, error) {
+	if c.fail {
+		return nil, errors.New("classification failed")
+	}
+	return c.types, nil
+}
+
+func (c *typingAI) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	c.hint = hint
+	groups, _ = c.OfflineClient.RefineWithHint(groups, hint)
+	if c.lumpAll && len(groups) > 1 {
+		var all []string
+		for _, g := range groups {
+			all = append(all, g.Files...)
+		}
+		return []grouper.FileGroup{{Files: all, CommitMessage: "chore: everything"}}, nil
+	}
+	for i := range groups {
+		groups[i].CommitMessage = "feat(x): describe " + strings.Join(groups[i].Files, " ")
+	}
+	return groups, nil
+}
+
+// commit is one commit's subject and files.
+type commit struct {
+	subject string
+	files   string // sorted, space separated
+}
+
+// Flushes changes of several kinds in two directories with each
+// grouping_strategy and checks the commits formed: one per type, one per
+// type per directory, or by directory when classification fails:
+//
+//	go run ./cmd/testgroupbytype
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testgroupbytype")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	_, err = config.LoadFromDir(tmp, tmp)
+	checks.Check("directory is the default", err == nil, err)
+	gittest.Write(filepath.Join(tmp, "config.yaml"), "grouping_strategy: by-mood\n")
+	_, err = config.LoadFromDir(tmp, tmp)
+	checks.Check("unknown grouping_strategy rejected", err != nil, err)
+	os.Remove(filepath.Join(tmp, "config.yaml"))
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	checks.Check("default strategy", cfg.GroupingStrategy == config.GroupingStrategyDirectory, cfg.GroupingStrategy)
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.CodeReview = false
+
+	types := map[string]string{
+		"auth/login.go":  "fix",
+		"auth/token.go":  "feat",
+		"api/handler.go": "fix",
+		"api/routes.go":  "feat",
+		"README.md":      "docs",
+	}
+	round := 0
+	flush := func(client engine.AIClient) []commit {
+		round++
+		repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+		if err != nil {
+			gittest.Fail("open repo", err)
+		}
+		eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, client)
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		head := strings.TrimSpace(gittest.Run(tmp, "git", "rev-parse", "HEAD"))
+		var changes []watcher.FileChange
+		for path := range types {
+			gittest.Write(filepath.Join(tmp, path), fmt.Sprintf("package x // round %d\n", round))
+			changes = append(changes, watcher.FileChange{Path: path, Type: watcher.Modified})
+		}
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+		eng.Submit(watcher.ChangeSet{Files: changes})
+		eng.Flush()
+		eng.Stop()
+		return commitsSince(tmp, head)
+	}
+	has := func(commits []commit, prefix, files string) bool {
+		for _, c := range commits {
+			if strings.HasPrefix(c.subject, prefix) && c.files == files {
+				return true
+			}
+		}
+		return false
+	}
+
+	fmt.Println("=== grouping_strategy: type ===")
+	cfg.GroupingStrategy = config.GroupingStrategyType
+	client := &typingAI{OfflineClient: ai.NewOfflineClient(), types: types}
+	commits := flush(client)
+	checks.Check("one commit per type", len(commits) == 3, commits)
+	checks.Check("fixes together", has(commits, "fix(", "api/handler.go auth/login.go"), commits)
+	checks.Check("features together", has(commits, "feat(", "api/routes.go auth/token.go"), commits)
+	checks.Check("docs on their own", has(commits, "docs", "README.md"), commits)
+	checks.Check("refine told to keep the groups", strings.Contains(client.hint, "Keep the groups"), client.hint)
+
+	fmt.Println("=== grouping_strategy: hybrid ===")
+	cfg.GroupingStrategy = config.GroupingStrategyHybrid
+	commits = flush(&typingAI{OfflineClient: ai.NewOfflineClient(), types: types})
+	checks.Check("one commit per type per directory", len(commits) == 5, commits)
+	checks.Check("auth fix", has(commits, "fix", "auth/login.go"), commits)
+	checks.Check("auth feature", has(commits, "feat", "auth/token.go"), commits)
+	checks.Check("api fix", has(commits, "fix", "api/handler.go"), commits)
+	checks.Check("api feature", has(commits, "feat", "api/routes.go"), commits)
+
+	fmt.Println("=== refine regroups anyway ===")
+	cfg.GroupingStrategy = config.GroupingStrategyType
+	commits = flush(&typingAI{OfflineClient: ai.NewOfflineClient(), types: types, lumpAll: true})
+	checks.Check("typed groups kept", len(commits) == 3 && has(commits, "fix", "api/handler.go auth/login.go"), commits)
+
+	fmt.Println("=== classification fails ===")
+	commits = flush(&typingAI{OfflineClient: ai.NewOfflineClient(), types: types, fail: true})
+	checks.Check("grouped by directory", has(commits, "feat", "auth/login.go auth/token.go") && has(commits, "feat", "api/handler.go api/routes.go"), commits)
+
+	fmt.Println("=== offline ===")
+	cfg.AI.Provider = ai.ProviderNone
+	types = map[string]string{"lib/a.go": "", "lib/a_test.go": "", "lib/NOTES.md": ""}
+	commits = flush(ai.NewOfflineClient())
+	checks.Check("offline types: feat, test, docs", len(commits) == 3 && has(commits, "feat", "lib/a.go") &&
+		has(commits, "test", "lib/a_test.go") && has(commits, "docs", "lib/NOTES.md"), commits)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll grouping_strategy checks passed.")
+}
+
+// commitsSince lists the commits after since, oldest first.
+func commitsSince(dir, since string) []commit {
+	var commits []commit
+	for _, hash := range strings.Fields(gittest.Run(dir, "git", "rev-list", "--reverse", since+"..HEAD")) {
+		subject := strings.TrimSpace(gittest.Run(dir, "git", "log", "-1", "--format=%s", hash))
+		files := strings.Fields(gittest.Run(dir, "git", "show", "--name-only", "--format=", hash))
+		sort.Strings(files)
+		commits = append(commits, commit{subject: subject, files: strings.Join(files, " ")})
+	}
+	return commits
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Exercises grouping_overrides and generated_file_rules without git or an API key:
+//
+//	go run ./cmd/testgrouper
+func main() {
+	checks := &gittest.Checks{}
+
+	changeset := watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "go.mod"},
+		{Path: "go.sum"},
+		{Path: "main.go"},
+		{Path: "api/user.proto"},
+		{Path: "internal/pb/user.pb.go"},
+		{Path: "internal/pb/client.go"},
+	}}
+	groups := grouper.PreGroup(changeset)
+
+	// ── force_separate: go.mod + go.sum pulled away from main.go ──
+	fmt.Println("=== force_separate ===")
+	sep := grouper.Overrides{Separate: []grouper.TypeRule{{Name: "deps", Patterns: []string{"go.mod", "go.sum"}}}}
+	out, conflicts := grouper.ApplyOverrides(groups, sep)
+	deps := find(out, "go.mod")
+	checks.Check("deps group holds only go.mod and go.sum", key(deps.Files) == "go.mod,go.sum", deps.Files)
+	checks.Check("deps group reason", deps.Reason == "forced separate: deps", deps.Reason)
+	checks.Check("main.go no longer with go.mod", key(find(out, "main.go").Files) == "main.go", find(out, "main.go").Files)
+	checks.Check("no conflicts", len(conflicts) == 0, conflicts)
+
+	// ── force_together: proto joins its generated code ──
+	fmt.Println("=== force_together ===")
+	tog := grouper.Overrides{Together: []grouper.TypeRule{{Name: "proto", Patterns: []string{"*.proto", "*.pb.go"}}}}
+	out, _ = grouper.ApplyOverrides(groups, tog)
+	proto := find(out, "api/user.proto")
+	checks.Check("proto and pb.go share a group", contains(proto.Files, "internal/pb/user.pb.go"), proto.Files)
+	checks.Check("every file still committed exactly once", countFiles(out) == len(changeset.Files), countFiles(out))
+
+	// ── conflicting overrides: a file in both lists stays separate ──
+	fmt.Println("=== conflicting overrides ===")
+	both := grouper.Overrides{
+		Separate: []grouper.TypeRule{{Name: "generated", Patterns: []string{"*.pb.go"}}},
+		Together: []grouper.TypeRule{{Name: "proto", Patterns: []string{"*.proto", "*.pb.go"}}},
+	}
+	out, conflicts = grouper.ApplyOverrides(groups, both)
+	gen := find(out, "internal/pb/user.pb.go")
+	checks.Check("separate wins over together", key(gen.Files) == "internal/pb/user.pb.go", gen.Files)
+	checks.Check("proto not merged with pb.go", !contains(find(out, "api/user.proto").Files, "internal/pb/user.pb.go"), find(out, "api/user.proto").Files)
+	checks.Check("conflict reported once", len(conflicts) == 1 && strings.Contains(conflicts[0], "user.pb.go"), conflicts)
+	checks.Check("every file still committed exactly once", countFiles(out) == len(changeset.Files), countFiles(out))
+
+	// ── idempotent: re-applying (as the engine does after AI refine) changes nothing ──
+	fmt.Println("=== idempotent ===")
+	for i := range out {
+		out[i].CommitMessage = fmt.Sprintf("msg %d", i)
+	}
+	again, _ := grouper.ApplyOverrides(out, both)
+	same := len(again) == len(out)
+	for i := 0; same && i < len(out); i++ {
+		same = key(again[i].Files) == key(out[i].Files) && again[i].CommitMessage == out[i].CommitMessage
+	}
+	checks.Check("second application keeps groups and messages", same, again)
+
+	// ── generated_file_rules: pb.go follows its proto across directories ──
+	fmt.Println("=== generated_file_rules ===")
+	rules := []grouper.GeneratedRule{{Source: "*.proto", Generated: []string{"*.pb.go", "*_grpc.pb.go"}}}
+	withGen := watcher.ChangeSet{Files: append(append([]watcher.FileChange(nil), changeset.Files...),
+		watcher.FileChange{Path: "internal/pb/user_grpc.pb.go"},
+		watcher.FileChange{Path: "internal/pb/order.pb.go"})}
+	out = grouper.ApplyGenerated(grouper.PreGroup(withGen), rules)
+	proto = find(out, "api/user.proto")
+	checks.Check("user.pb.go and user_grpc.pb.go join user.proto", key(proto.Files) == "api/user.proto,internal/pb/user.pb.go,internal/pb/user_grpc.pb.go", proto.Files)
+	checks.Check("reason notes the generated files", strings.Contains(proto.Reason, "user.pb.go generated from user.proto"), proto.Reason)
+	checks.Check("order.pb.go (source unchanged) stays put", !contains(proto.Files, "internal/pb/order.pb.go"), proto.Files)
+	checks.Check("every file still committed exactly once", countFiles(out) == len(withGen.Files), countFiles(out))
+	again = grouper.ApplyGenerated(out, rules)
+	checks.Check("second application is a no-op", len(again) == len(out) && key(find(again, "api/user.proto").Files) == key(proto.Files), again)
+
+	// ── group_root_depth: monorepo services grouped by top-level dir ──
+	fmt.Println("=== group_root_depth ===")
+	mono := watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "services/api/handler.go"},
+		{Path: "services/api/internal/db/query.go"},
+		{Path: "services/api/internal/db/query_test.go"},
+		{Path: "services/web/src/app.ts"},
+		{Path: "services/web/src/lib/util.ts"},
+		{Path: "libs/log/log.go"},
+		{Path: "README.md"},
+	}}
+	depth0 := grouper.PreGroupWithOptions(mono, grouper.Options{})
+	checks.Check("depth 0 groups by parent dir", find(depth0, "services/api/handler.go").Reason != find(depth0, "services/api/internal/db/query.go").Reason, depth0)
+	checks.Check("depth 0 keeps name affinity", key(find(depth0, "services/api/internal/db/query.go").Files) == "services/api/internal/db/query.go,services/api/internal/db/query_test.go", depth0)
+
+	depth1 := grouper.PreGroupWithOptions(mono, grouper.Options{RootDepth: 1})
+	svc := find(depth1, "services/api/handler.go")
+	checks.Check("depth 1 groups all of services/ together", key(svc.Files) == "services/api/handler.go,services/api/internal/db/query.go,services/api/internal/db/query_test.go,services/web/src/app.ts,services/web/src/lib/util.ts", svc.Files)
+	checks.Check("depth 1 test pairing still shows in the reason", svc.Reason == "name affinity: services", svc.Reason)
+	checks.Check("depth 1 leaves libs/ apart", !contains(svc.Files, "libs/log/log.go"), svc.Files)
+
+	depth2 := grouper.PreGroupWithOptions(mono, grouper.Options{RootDepth: 2})
+	api := find(depth2, "services/api/handler.go")
+	web := find(depth2, "services/web/src/app.ts")
+	checks.Check("depth 2 groups services/api across subdirs", key(api.Files) == "services/api/handler.go,services/api/internal/db/query.go,services/api/internal/db/query_test.go", api.Files)
+	checks.Check("depth 2 groups services/web across subdirs", key(web.Files) == "services/web/src/app.ts,services/web/src/lib/util.ts", web.Files)
+	checks.Check("depth 2 reason names the root", web.Reason == "same root: services/web", web.Reason)
+	checks.Check("shallower files keep their own dir", find(depth2, "libs/log/log.go").Reason != api.Reason && !contains(api.Files, "README.md"), depth2)
+	checks.Check("every file grouped exactly once", countFiles(depth2) == len(mono.Files), countFiles(depth2))
+
+	// ── ResolveScope: Go package name, else the shared directory ──
+	fmt.Println("=== scope ===")
+	root, err := os.MkdirTemp("", "gitpulse-testgrouper")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create temp dir: %v\n", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(root)
+	for name, content := range map[string]string{
+		"internal/engine/engine.go":      "// Package engine runs the pipeline.\npackage engine\n",
+		"internal/engine/engine_test.go": "package engine_test\n",
+		"internal/engine/README.md":      "engine\n",
+		"internal/store/store.go":        "package store\n",
+		"cmd/testgrouper/main.go":        "package main\n",
+		"main.go":                        "package main\n",
+		"web/src/app.ts":                 "export {}\n",
+		"web/src/lib/util.ts":            "export {}\n",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+	scope := func(files ...string) string { return grouper.ResolveScope(root, files) }
+	checks.Check("Go package name", scope("internal/engine/engine.go") == "engine", scope("internal/engine/engine.go"))
+	checks.Check("external test package counts as its package", scope("internal/engine/engine.go", "internal/engine/engine_test.go") == "engine", scope("internal/engine/engine.go", "internal/engine/engine_test.go"))
+	checks.Check("non-Go files follow the Go package", scope("internal/engine/engine.go", "internal/engine/README.md") == "engine", scope("internal/engine/engine.go", "internal/engine/README.md"))
+	checks.Check("package main uses its directory", scope("cmd/testgrouper/main.go") == "testgrouper", scope("cmd/testgrouper/main.go"))
+	checks.Check("root package main has no scope", scope("main.go") == "", scope("main.go"))
+	checks.Check("mixed packages fall back to the directory", scope("internal/engine/engine.go", "internal/store/store.go") == "internal", scope("internal/engine/engine.go", "internal/store/store.go"))
+	checks.Check("other languages use the directory", scope("web/src/app.ts", "web/src/lib/util.ts") == "web/src", scope("web/src/app.ts", "web/src/lib/util.ts"))
+	checks.Check("deleted Go files fall back to the directory", scope("internal/auth/gone.go") == "internal/auth", scope("internal/auth/gone.go"))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll grouping override, generated-file, root-depth and scope checks passed.")
+}
+
+func find(groups []grouper.FileGroup, file string) grouper.FileGroup {
+	for _, g := range groups {
+		if contains(g.Files, file) {
+			return g
+		}
+	}
+	return grouper.FileGroup{}
+}
+
+func contains(files []string, file string) bool {
+	for _, f := range files {
+		if f == file {
+			return true
+		}
+	}
+	return false
+}
+
+func key(files []string) string {
+	s := append([]string(nil), files...)
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func countFiles(groups []grouper.FileGroup) int {
+	n := 0
+	for _, g := range groups {
+		n += len(g.Files)
+	}
+	return n
+}
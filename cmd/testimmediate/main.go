@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// blockingAI is the offline client with a reviewer that flags every file,
+// counting its reviews.
+type blockingAI struct {
+	*ai.OfflineClient
+	reviews int
+}
+
+func (c *blockingAI) ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error) {
+	c.reviews++
+	result := &ai.ReviewResult{HasBlockers: true}
+	for _, g := range groups {
+		for _, f := range g.Files {
+			result.Findings = append(result.Findings, ai.ReviewFinding{File: f, StartLine: 1, EndLine: 1, Severity: ai.SeverityError, Description: "bad"})
+		}
+	}
+	return result, nil
+}
+
+// Saves a file matching commit_immediately_patterns alongside a normal one:
+// it's committed on its own straight away, with no flush, while the other
+// stays buffered. While snoozed it's buffered like everything else. The
+// commit runs off the caller's goroutine, so a review prompt doesn't hold up
+// the watcher, and aborting it puts the file back in pending changes without
+// starting another immediate commit:
+//
+//	go run ./cmd/testimmediate
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testimmediate")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	cfg.CommitImmediatelyPatterns = []string{"TODO.md"}
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer func() { eng.Stop() }()
+
+	events := eng.Subscribe()
+
+	fmt.Println("=== save ===")
+	gittest.Write(filepath.Join(tmp, "TODO.md"), "- [ ] ship it\n")
+	gittest.Write(filepath.Join(tmp, "src", "app.go"), "package src\n")
+	before := commitCount(tmp)
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "TODO.md", Type: watcher.Created},
+		{Path: "src/app.go", Type: watcher.Created},
+	}})
+	waitDone(events)
+	checks.Check("committed without a flush", commitCount(tmp) == before+1, commitCount(tmp)-before)
+	files := strings.TrimSpace(gittest.Run(tmp, "git", "show", "--name-only", "--format=", "HEAD"))
+	checks.Check("commit has only the immediate file", files == "TODO.md", files)
+	checks.Check("other change still buffered", eng.PendingCount() == 1, eng.PendingCount())
+
+	fmt.Println("=== save again ===")
+	gittest.Write(filepath.Join(tmp, "TODO.md"), "- [x] ship it\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "TODO.md", Type: watcher.Modified}}})
+	waitDone(events)
+	checks.Check("every save gets its own commit", commitCount(tmp) == before+2, commitCount(tmp)-before)
+
+	fmt.Println("=== snoozed ===")
+	eng.Snooze(time.Now().Add(time.Hour))
+	gittest.Write(filepath.Join(tmp, "TODO.md"), "- [x] ship it\n- [ ] rest\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "TODO.md", Type: watcher.Modified}}})
+	checks.Check("held while snoozed", commitCount(tmp) == before+2, commitCount(tmp)-before)
+	checks.Check("buffered instead", eng.PendingCount() == 2, eng.PendingCount())
+	eng.Stop()
+
+	fmt.Println("=== review aborted ===")
+	cfg.AI.Provider = "anthropic"
+	cfg.AI.CodeReview = true
+	cfg.ReviewMinLines = 0
+	cfg.CommitImmediatelyPatterns = []string{"schema.go"}
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	reviewer := &blockingAI{OfflineClient: ai.NewOfflineClient()}
+	stdin := make(chan string)
+	logger := ui.New(stdin)
+	eng, err = engine.NewWithDeps(cfg, logger, repo, reviewer)
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	eng.Interactive = true
+	events = eng.Subscribe()
+	gittest.Write(filepath.Join(tmp, "schema.go"), "package main\n")
+	before = commitCount(tmp)
+	submitted := make(chan struct{})
+	go func() {
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "schema.go", Type: watcher.Created}}})
+		close(submitted)
+	}()
+	returned := false
+	select {
+	case <-submitted:
+		returned = true
+	case <-time.After(5 * time.Second):
+	}
+	checks.Check("caller not held up by the review prompt", returned, returned)
+	prompted := false
+	for i := 0; i < 500 && !prompted; i++ {
+		prompted = logger.Prompting()
+		time.Sleep(10 * time.Millisecond)
+	}
+	checks.Check("review prompt shown", prompted, prompted)
+	if !prompted {
+		os.Exit(1)
+	}
+	stdin <- "5"
+	waitDone(events)
+	time.Sleep(500 * time.Millisecond) // time for a repeat run to start, if one would
+	checks.Check("not reviewed again", reviewer.reviews == 1 && !logger.Prompting(), reviewer.reviews)
+	checks.Check("not committed", commitCount(tmp) == before, commitCount(tmp)-before)
+	checks.Check("back in pending changes", eng.PendingCount() == 1, eng.PendingCount())
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll commit-immediately checks passed.")
+}
+
+// waitDone waits for the next flush (immediate commit) to finish, exiting
+// if it doesn't within 10s.
+func waitDone(events <-chan engine.Event) {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == engine.EventFlushDone {
+				return
+			}
+		case <-timeout:
+			gittest.Fail("wait for the commit", fmt.Errorf("timed out"))
+		}
+	}
+}
+
+// commitCount returns how many commits HEAD has.
+func commitCount(dir string) int {
+	return len(strings.Fields(gittest.Run(dir, "git", "rev-list", "HEAD")))
+}
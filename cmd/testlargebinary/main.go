@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Flushes new files over large_binary_prompt_mb: a binary is skipped with a
+// warning when non-interactive, and committed or left out per the answer to
+// "Add large binary ...? [y/N]" when interactive. Big text files and small
+// binaries are committed as usual:
+//
+//	go run ./cmd/testlargebinary
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testlargebinary")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	cfg.LargeBinaryPromptMB = 1
+	cfg.LargeCommitLines = 0
+
+	binary := string(bytes.Repeat([]byte{0x50, 0x4b, 0x03, 0x04, 0x00}, 1<<19)) // 2.5MB
+	text := strings.Repeat("a line of text\n", 1<<17)                           // ~2MB
+
+	fmt.Println("=== non-interactive ===")
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "dist", "app.zip"), binary)
+	gittest.Write(filepath.Join(tmp, "data.csv"), text)
+	gittest.Write(filepath.Join(tmp, "icon.png"), "\x89PNG\x00\x00")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "dist/app.zip", Type: watcher.Created},
+		{Path: "data.csv", Type: watcher.Created},
+		{Path: "icon.png", Type: watcher.Created},
+	}})
+	eng.Flush()
+	eng.Stop()
+	checks.Check("large binary skipped", !tracked(tmp, "dist/app.zip"), tracked(tmp, "dist/app.zip"))
+	checks.Check("large text file committed", tracked(tmp, "data.csv"), "")
+	checks.Check("small binary committed", tracked(tmp, "icon.png"), "")
+
+	fmt.Println("=== interactive ===")
+	stdin := make(chan string)
+	logger := ui.New(stdin)
+	eng, err = engine.New(cfg, logger)
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+	eng.Interactive = true
+	gittest.Write(filepath.Join(tmp, "build.bin"), binary)
+	for _, c := range []struct {
+		file, answer string
+		committed    bool
+	}{
+		{"dist/app.zip", "n", false},
+		{"build.bin", "y", true},
+	} {
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: c.file, Type: watcher.Modified}}})
+		done := make(chan struct{})
+		go func() {
+			eng.Flush()
+			close(done)
+		}()
+		asked := false
+		for i := 0; i < 300 && !asked; i++ {
+			asked = logger.Prompting()
+			time.Sleep(10 * time.Millisecond)
+		}
+		checks.Check("asks about "+c.file, asked, asked)
+		if asked {
+			stdin <- c.answer
+		}
+		<-done
+		checks.Check(fmt.Sprintf("%s committed: %v", c.file, c.committed), tracked(tmp, c.file) == c.committed, tracked(tmp, c.file))
+	}
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll large binary checks passed.")
+}
+
+// tracked reports whether path is in HEAD.
+func tracked(dir, path string) bool {
+	return strings.TrimSpace(gittest.Run(dir, "git", "ls-tree", "--name-only", "HEAD", "--", path)) == path
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Exercises push, fetch and pull against a local bare remote (see
+// internal/git/gittest), fully offline: a plain push, catching up with a
+// remote that moved, a diverged branch that is rejected and then rebased,
+// two same-message commits made in the same second keeping their own new
+// hashes through the rebase, a conflicting pull that is rolled back, a pull refused because branch is
+// pinned to another branch, and the engine's push bookkeeping around a
+// rejected push and the pull that rebases its commit:
+//
+//	go run ./cmd/testlocalremote
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testlocalremote")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+	dir := func(name string) string {
+		d := filepath.Join(tmp, name)
+		if err := os.Mkdir(d, 0755); err != nil {
+			gittest.Fail("create "+name, err)
+		}
+		return d
+	}
+
+	// ── Push ──
+	fmt.Println("=== Push ===")
+	r := setup(dir("push"))
+	m := manager(r)
+	hash := must(r.Commit("a.go", "package a\n", "feat: add a"))
+	pushed, err := m.IsPushed(hash)
+	checks.Check("new commit not pushed yet", err == nil && !pushed, err)
+	err = m.Push()
+	checks.Check("push succeeds", err == nil, err)
+	checks.Check("remote has the commit", must(r.RemoteHead()) == hash, must(r.RemoteHead()))
+	must(r.Git("fetch", "-q", "origin"))
+	pushed, err = m.IsPushed(hash)
+	checks.Check("commit reported pushed", err == nil && pushed, err)
+	err = m.Push()
+	checks.Check("pushing again is a no-op", err == nil, err)
+
+	// ── Behind ──
+	fmt.Println("\n=== Behind the remote ===")
+	r = setup(dir("behind"))
+	m = manager(r)
+	theirs := must(r.PushElsewhere("b.go", "package b\n", "feat: add b"))
+	behind, n, err := m.IsBehind()
+	checks.Check("not behind before fetching", err == nil && !behind, err)
+	err = m.Fetch()
+	checks.Check("fetch succeeds", err == nil, err)
+	behind, n, err = m.IsBehind()
+	checks.Check("one commit behind after fetching", err == nil && behind && n == 1, n)
+	gittest.Write(filepath.Join(r.Dir, "pending.go"), "package pending\n")
+	must(r.Git("add", "pending.go"))
+	_, err = m.Pull()
+	checks.Check("pull succeeds", err == nil, err)
+	checks.Check("HEAD is the remote commit", must(r.Head()) == theirs, must(r.Head()))
+	checks.Check("pending change survives the pull", strings.Contains(must(r.Git("status", "--porcelain")), "pending.go"), must(r.Git("status", "--porcelain")))
+	behind, _, err = m.IsBehind()
+	checks.Check("no longer behind", err == nil && !behind, err)
+
+	// ── Diverged ──
+	fmt.Println("\n=== Diverged ===")
+	r = setup(dir("diverged"))
+	m = manager(r)
+	theirs = must(r.PushElsewhere("theirs.go", "package theirs\n", "feat: add theirs"))
+	ours := must(r.Commit("ours.go", "package ours\n", "feat: add ours"))
+	err = m.Push()
+	checks.Check("push rejected as non-fast-forward", errors.Is(err, git.ErrNonFastForward), err)
+	rewritten, err := m.Pull()
+	checks.Check("pull rebases onto the remote", err == nil && must(r.Git("rev-parse", "HEAD~1")) == theirs, err)
+	checks.Check("our change is kept", must(r.Git("log", "-1", "--format=%s")) == "feat: add ours", must(r.Git("log", "-1", "--format=%s")))
+	checks.Check("rebased commit's new hash reported", len(rewritten) == 1 && rewritten[ours] == must(r.Head()), rewritten)
+	err = m.Push()
+	checks.Check("push succeeds after the pull", err == nil && must(r.RemoteHead()) == must(r.Head()), err)
+
+	// ── Look-alike commits ──
+	fmt.Println("\n=== Diverged with look-alike commits ===")
+	r = setup(dir("lookalike"))
+	m = manager(r)
+	must(r.PushElsewhere("theirs.go", "package theirs\n", "feat: add theirs"))
+	// Same message, same second: only their order tells them apart
+	var wips []string
+	for _, f := range []string{"one.go", "two.go"} {
+		gittest.Write(filepath.Join(r.Dir, f), "package wip\n")
+		must(r.Git("add", f))
+		must(r.Git("commit", "-q", "-m", "wip", "--date", "2024-03-01T10:00:00Z"))
+		wips = append(wips, must(r.Head()))
+	}
+	rewritten, err = m.Pull()
+	checks.Check("pull succeeds", err == nil, err)
+	checks.Check("each look-alike mapped to its own new hash",
+		len(rewritten) == 2 && rewritten[wips[0]] == must(r.Git("rev-parse", "HEAD~1")) && rewritten[wips[1]] == must(r.Head()), rewritten)
+
+	// ── Conflict ──
+	fmt.Println("\n=== Conflicting pull ===")
+	r = setup(dir("conflict"))
+	m = manager(r)
+	must(r.PushElsewhere("README.md", "theirs\n", "docs: theirs"))
+	ours = must(r.Commit("README.md", "ours\n", "docs: ours"))
+	_, err = m.Pull()
+	checks.Check("pull fails", err != nil, err)
+	checks.Check("HEAD unchanged", must(r.Head()) == ours, must(r.Head()))
+	op, err := m.InProgressOperation()
+	checks.Check("no rebase left in progress", err == nil && op == "", op)
+
+	// ── Pinned branch ──
+	fmt.Println("\n=== Pull with branch pinned to another branch ===")
+	r = setup(dir("pinned"))
+	must(r.PushElsewhere("b.go", "package b\n", "feat: add b"))
+	must(r.Git("checkout", "-q", "-b", "feature"))
+	head := must(r.Head())
+	pinned, err := git.New(r.Dir, "origin", gittest.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	_, err = pinned.Pull()
+	checks.Check("refused", errors.Is(err, git.ErrBranchMismatch), err)
+	checks.Check("feature branch untouched", must(r.Head()) == head, must(r.Head()))
+
+	// ── Engine ──
+	fmt.Println("\n=== Engine push bookkeeping ===")
+	r = setup(dir("engine"))
+	cfg, err := config.LoadFromDir(r.Dir, r.Dir)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeManual
+	stdin := make(chan string, 1)
+	eng, err := engine.New(cfg, ui.New(stdin))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	must(r.PushElsewhere("other.go", "package other\n", "feat: add other"))
+	gittest.Write(filepath.Join(r.Dir, "main.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.PushNow()
+	unpushed := openStore(r).GetUnpushedCommits()
+	checks.Check("rejected push leaves the commit unpushed", len(unpushed) == 1, len(unpushed))
+	// The next flush offers to pull first, which rebases that commit
+	eng.Interactive = true
+	stdin <- "y"
+	gittest.Write(filepath.Join(r.Dir, "second.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "second.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.Interactive = false
+	rebased := must(r.Git("log", "-1", "--format=%H", "--", "main.go"))
+	s := openStore(r)
+	checks.Check("pulled before committing", must(r.Git("log", "-1", "--format=%s", "--", "other.go")) == "feat: add other" && len(unpushed) == 1 && rebased != unpushed[0].Hash, rebased)
+	checks.Check("history follows the rebased commit", len(unpushed) == 1 && s.GetByHash(unpushed[0].Hash) == nil && s.GetByHash(rebased) != nil, rebased)
+	eng.PushNow()
+	checks.Check("push after pulling marks it pushed", len(openStore(r).GetUnpushedCommits()) == 0, len(openStore(r).GetUnpushedCommits()))
+	checks.Check("remote has the engine's commit", must(r.RemoteHead()) == must(r.Head()), must(r.RemoteHead()))
+	eng.Stop()
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll local remote checks passed.")
+}
+
+func setup(dir string) *gittest.Remote {
+	r, err := gittest.New(dir)
+	if err != nil {
+		gittest.Fail("set up repo and remote", err)
+	}
+	return r
+}
+
+func manager(r *gittest.Remote) *git.Manager {
+	m, err := r.Manager()
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	return m
+}
+
+func openStore(r *gittest.Remote) *store.Store {
+	s, err := store.New(filepath.Join(r.Dir, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	return s
+}
+
+func must(s string, err error) string {
+	if err != nil {
+		gittest.Fail("run git", err)
+	}
+	return s
+}
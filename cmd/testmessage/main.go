@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/version"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// longMessageAI is the offline client, except every group gets subject, an
+// overly long AI-style subject.
+type longMessageAI struct {
+	*ai.OfflineClient
+	subject string
+}
+
+const longSubject = "feat(engine): add configurable commit subject length enforcement that moves the overflowing words into a wrapped commit body"
+
+func (c longMessageAI) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	for i := range groups {
+		groups[i].CommitMessage = c.subject
+		groups[i].Model = "claude-test"
+	}
+	return groups, nil
+}
+
+// Commits with an AI message whose subject is far over 72 characters and
+// checks that commit_subject_max_length / commit_body_wrap clean it up (with
+// no empty body left when only its spacing was too long), then that
+// include_diffstat_in_body appends a diffstat to the body and
+// attribution_trailer a Generated-by trailer:
+//
+//	go run ./cmd/testmessage
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testmessage")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.CodeReview = false
+	cfg.CommitBodyWrap = 40
+	cfg.AttributionTrailer = false // on by default; checked last
+
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, longMessageAI{ai.NewOfflineClient(), longSubject})
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "main.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.Stop()
+
+	fmt.Println("=== long AI subject ===")
+	subject := strings.TrimSpace(gittest.Run(tmp, "git", "log", "-1", "--format=%s"))
+	body := strings.TrimSpace(gittest.Run(tmp, "git", "log", "-1", "--format=%b"))
+	checks.Check("subject within 72 characters", len(subject) <= 72, len(subject))
+	checks.Check("subject ends on a whole word", strings.HasPrefix(longSubject, subject+" "), subject)
+	checks.Check("overflow moved into the body", strings.Join(strings.Fields(subject+" "+body), " ") == longSubject, body)
+	wrapped := true
+	for _, line := range strings.Split(body, "\n") {
+		wrapped = wrapped && len(line) <= 40
+	}
+	checks.Check("body wrapped at commit_body_wrap", wrapped && strings.Count(body, "\n") >= 1, body)
+	checks.Check("no trailer with attribution_trailer off", !strings.Contains(body, "Generated-by"), body)
+
+	// ── Long only by its spacing: every word fits, so no body ──
+	fmt.Println("=== long only by its spacing ===")
+	spaced := "fix(engine): handle" + strings.Repeat(" ", 60) + "nil config"
+	eng, err = engine.NewWithDeps(cfg, ui.New(nil), repo, longMessageAI{ai.NewOfflineClient(), spaced})
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "main.go"), "package main\n\nfunc main() {}\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Modified}}})
+	eng.Flush()
+	eng.Stop()
+	// go-git stores the message as given, so the raw commit shows any
+	// trailing blank lines
+	_, raw, _ := strings.Cut(gittest.Run(tmp, "git", "cat-file", "commit", "HEAD"), "\n\n")
+	checks.Check("subject tidied, no empty body", raw == "fix(engine): handle nil config", raw)
+
+	// ── include_diffstat_in_body: git diff --stat summary after the body ──
+	fmt.Println("=== include_diffstat_in_body ===")
+	cfg.IncludeDiffStatInBody = true
+	eng, err = engine.NewWithDeps(cfg, ui.New(nil), repo, longMessageAI{ai.NewOfflineClient(), longSubject})
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello world\nsecond line\n")
+	gittest.Write(filepath.Join(tmp, "util.go"), "package main\n"+strings.Repeat("// filler\n", 99))
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "README.md", Type: watcher.Modified},
+		{Path: "util.go", Type: watcher.Created},
+	}})
+	eng.Flush()
+	eng.Stop()
+
+	subject = strings.TrimSpace(gittest.Run(tmp, "git", "log", "-1", "--format=%s"))
+	full := gittest.Run(tmp, "git", "log", "-1", "--format=%B")
+	checks.Check("subject has no diffstat", len(subject) <= 72 && !strings.Contains(subject, "|"), subject)
+	checks.Check("README.md line with count and bar", strings.Contains(full, "\n README.md |   3 +-\n"), full)
+	checks.Check("large file scaled to 40 marks", strings.Contains(full, "\n util.go   | 100 "+strings.Repeat("+", 40)+"\n"), full)
+	checks.Check("summary line", strings.Contains(full, "\n 2 files changed, 102 insertions(+), 1 deletion(-)"), full)
+	checks.Check("diffstat follows the wrapped overflow", strings.Index(full, "README.md |") > strings.Index(full, "body"), full)
+
+	// ── attribution_trailer: Generated-by trailer at the very end ──
+	fmt.Println("=== attribution_trailer ===")
+	cfg.IncludeDiffStatInBody = false
+	cfg.AttributionTrailer = true
+	eng, err = engine.NewWithDeps(cfg, ui.New(nil), repo, longMessageAI{ai.NewOfflineClient(), longSubject})
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(tmp, "util.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "util.go", Type: watcher.Modified}}})
+	eng.Flush()
+	eng.Stop()
+
+	full = strings.TrimSpace(gittest.Run(tmp, "git", "log", "-1", "--format=%B"))
+	lines := strings.Split(full, "\n")
+	last := lines[len(lines)-1]
+	generatedBy := "GitPulse " + version.String() + " (model: claude-test)"
+	checks.Check("trailer names GitPulse, its version and the model", last == "Generated-by: "+generatedBy, last)
+	trailer := strings.TrimSpace(gittest.Run(tmp, "git", "log", "-1", "--format=%(trailers:key=Generated-by,valueonly)"))
+	checks.Check("git reads it as a trailer", trailer == generatedBy, trailer)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll commit message checks passed.")
+}
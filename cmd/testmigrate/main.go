@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// v0History is a history.json as written before the file was versioned: a
+// bare array of commit records.
+const v0History = `[
+  {
+    "hash": "1111111111111111111111111111111111111111",
+    "message": "feat: add login",
+    "files": [{"path": "auth.go", "diff": "", "lines_added": 12, "lines_removed": 0, "status": "added"}],
+    "group_reason": "auth",
+    "ai_generated": true,
+    "pushed": true,
+    "remote": "origin",
+    "branch": "main",
+    "created_at": "2025-01-02T03:04:05Z"
+  },
+  {
+    "hash": "2222222222222222222222222222222222222222",
+    "message": "docs: update readme",
+    "files": [{"path": "README.md", "diff": "", "lines_added": 1, "lines_removed": 1, "status": "modified"}],
+    "group_reason": "docs",
+    "ai_generated": true,
+    "pushed": false,
+    "created_at": "2025-01-02T03:05:00Z"
+  }
+]
+`
+
+// Loads an unversioned (v0) history file and checks it is migrated in place
+// to the current schema_version, with the original backed up and every
+// record intact:
+//
+//	go run ./cmd/testmigrate
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testmigrate")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	fmt.Println("=== v0 history file ===")
+	path := filepath.Join(tmp, ".gitpulse", "history.json")
+	gittest.Write(path, v0History)
+
+	s, err := store.New(path)
+	checks.Check("v0 file loads", err == nil, err)
+	if err != nil {
+		os.Exit(1)
+	}
+	records := s.All()
+	checks.Check("both records loaded", len(records) == 2, len(records))
+	if r := s.GetByHash("1111111111111111111111111111111111111111"); r != nil {
+		checks.Check("fields survive the migration", r.Message == "feat: add login" && r.Pushed && r.Remote == "origin" &&
+			len(r.Files) == 1 && r.Files[0].LinesAdded == 12 && r.CreatedAt.Year() == 2025, *r)
+	} else {
+		checks.Check("fields survive the migration", false, nil)
+	}
+	checks.Check("unpushed state kept", len(s.GetUnpushedCommits()) == 1, len(s.GetUnpushedCommits()))
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	checks.Check("original backed up byte for byte", err == nil && string(backup) == v0History, err)
+
+	var file struct {
+		SchemaVersion int               `json:"schema_version"`
+		Commits       []json.RawMessage `json:"commits"`
+	}
+	data, _ := os.ReadFile(path)
+	err = json.Unmarshal(data, &file)
+	checks.Check("file rewritten with schema_version 1", err == nil && file.SchemaVersion == 1 && len(file.Commits) == 2, string(data))
+
+	fmt.Println("\n=== Migrated file ===")
+	if err := os.Remove(path + ".v0.bak"); err != nil {
+		gittest.Fail("remove backup", err)
+	}
+	if err := s.Save(store.CommitRecord{Hash: "3333333333333333333333333333333333333333", Message: "fix: typo"}); err != nil {
+		gittest.Fail("save record", err)
+	}
+	s, err = store.New(path)
+	checks.Check("reopens without error", err == nil, err)
+	checks.Check("all three records present", err == nil && len(s.All()) == 3, len(s.All()))
+	_, err = os.Stat(path + ".v0.bak")
+	checks.Check("current file is not migrated again", os.IsNotExist(err), err)
+
+	fmt.Println("\n=== Newer schema_version ===")
+	gittest.Write(path, `{"schema_version": 99, "commits": []}`)
+	_, err = store.New(path)
+	checks.Check("refuses a file from a newer GitPulse", err != nil, err)
+	data, _ = os.ReadFile(path)
+	checks.Check("newer file left untouched", string(data) == `{"schema_version": 99, "commits": []}`, string(data))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll store migration checks passed.")
+}
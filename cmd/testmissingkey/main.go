@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Starts the engine with no API key under each ai.on_missing_api_key: warn
+// says so at startup and on every flush and commits with offline messages,
+// disable_ai does the same without the warnings, fail refuses to start, and
+// an unknown value is a config error. Uses a throwaway repo:
+//
+//	go run ./cmd/testmissingkey
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testmissingkey")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	os.Unsetenv("CLAUDE_API_KEY")
+	os.Unsetenv("ANTHROPIC_API_KEY")
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	load := func(mode string) *config.Config {
+		cfg, err := config.LoadFromDir(tmp, tmp)
+		if err != nil {
+			gittest.Fail("load config", err)
+		}
+		cfg.PushMode = config.PushModeNever
+		cfg.AI.APIKey = "  "
+		cfg.AI.OnMissingAPIKey = mode
+		return cfg
+	}
+	// flush commits one new file and returns the engine's log and the commit
+	// message
+	flush := func(cfg *config.Config, file string) (string, string) {
+		var out bytes.Buffer
+		logger := ui.New(nil)
+		logger.SetOutput(&out)
+		eng, err := engine.New(cfg, logger)
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		gittest.Write(filepath.Join(tmp, file), "package main\n")
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: file, Type: watcher.Created}}})
+		eng.Flush()
+		eng.Stop()
+		return out.String(), strings.TrimSpace(gittest.Run(tmp, "git", "log", "-1", "--format=%s"))
+	}
+
+	fmt.Println("=== default ===")
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	checks.Check("defaults to warn", cfg.AI.OnMissingAPIKey == config.MissingAPIKeyWarn, cfg.AI.OnMissingAPIKey)
+
+	fmt.Println("=== warn ===")
+	cfg = load(config.MissingAPIKeyWarn)
+	log, msg := flush(cfg, "warn.go")
+	checks.Check("warns at startup", strings.Contains(log, "No API key set"), log)
+	checks.Check("warns on the flush", strings.Contains(log, "committing with heuristic messages"), log)
+	checks.Check("switched to provider none", cfg.AI.Provider == "none", cfg.AI.Provider)
+	checks.Check("committed with an offline message", strings.Contains(msg, "warn"), msg)
+	checks.Check("not the generic fallback message", msg != "chore: auto-commit changes", msg)
+
+	fmt.Println("=== disable_ai ===")
+	cfg = load(config.MissingAPIKeyDisableAI)
+	log, msg = flush(cfg, "quiet.go")
+	checks.Check("no warnings", !strings.Contains(log, "WARN"), log)
+	checks.Check("says AI is disabled", strings.Contains(log, "AI disabled"), log)
+	checks.Check("committed", strings.Contains(msg, "quiet"), msg)
+
+	fmt.Println("=== fail ===")
+	_, err = engine.New(load(config.MissingAPIKeyFail), ui.New(nil))
+	checks.Check("refuses to start", err != nil && strings.Contains(err.Error(), "no API key"), err)
+	cfg = load(config.MissingAPIKeyFail)
+	cfg.AI.APIKey = "sk-test"
+	eng, err := engine.New(cfg, ui.New(nil))
+	checks.Check("starts with a key", err == nil, err)
+	if eng != nil {
+		eng.Stop()
+	}
+	cfg = load(config.MissingAPIKeyFail)
+	cfg.AI.Provider = "none"
+	eng, err = engine.New(cfg, ui.New(nil))
+	checks.Check("starts with provider none", err == nil, err)
+	if eng != nil {
+		eng.Stop()
+	}
+
+	fmt.Println("=== invalid ===")
+	gittest.Write(filepath.Join(tmp, "config.yaml"), "ai:\n  on_missing_api_key: panic\n")
+	_, err = config.LoadFromDir(tmp, tmp)
+	checks.Check("unknown value rejected", err != nil && strings.Contains(err.Error(), "on_missing_api_key"), err)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll missing API key checks passed.")
+}
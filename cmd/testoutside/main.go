@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Commits by hand while GitPulse isn't running and checks a restarted engine
+// imports those commits into the history (ai_generated: false), then that a
+// flush imports a hand-made commit without duplicating its own, and that
+// with import_outside_commits off they're only counted:
+//
+//	go run ./cmd/testoutside
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testoutside")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	cfg.ImportOutsideCommits = true
+	historyPath := filepath.Join(tmp, ".gitpulse", "history.json")
+
+	fmt.Println("=== first run ===")
+	eng := newEngine(cfg)
+	checks.Check("existing history not imported", eng.ReconcileOutsideCommits() == 0, "")
+	eng.Stop()
+
+	fmt.Println("=== commits while stopped ===")
+	gittest.Write(filepath.Join(tmp, "a.go"), "package main\n\nvar a = 1\n")
+	commit(tmp, "feat: add a", "2024-03-01T10:00:00Z", "a.go")
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\nworld\n")
+	commit(tmp, "docs: extend README", "2024-03-02T10:00:00Z", "README.md")
+
+	eng = newEngine(cfg)
+	checks.Check("both found on restart", eng.ReconcileOutsideCommits() == 2, "")
+	records := history(historyPath)
+	checks.Check("both imported", len(records) == 2, len(records))
+	if len(records) == 2 {
+		first := records[0]
+		checks.Check("not AI generated", !first.AIGenerated && !records[1].AIGenerated, first)
+		checks.Check("message kept", first.Message == "feat: add a", first.Message)
+		checks.Check("commit date kept", first.CreatedAt.Format("2006-01-02") == "2024-03-01", first.CreatedAt)
+		checks.Check("file stats", len(first.Files) == 1 && first.Files[0].Path == "a.go" && first.Files[0].LinesAdded == 3, first.Files)
+		checks.Check("review coverage skipped", first.ReviewMode == store.ReviewModeSkipped, first.ReviewMode)
+	}
+	checks.Check("nothing new the second time", eng.ReconcileOutsideCommits() == 0, "")
+
+	fmt.Println("=== commit by hand while running ===")
+	gittest.Write(filepath.Join(tmp, "b.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "b.go", Type: watcher.Created}}})
+	gittest.Write(filepath.Join(tmp, "c.go"), "package main\n")
+	commit(tmp, "feat: add c", "", "c.go")
+	eng.Flush()
+	records = history(historyPath)
+	var own, hand int
+	for _, r := range records[2:] {
+		if r.AIGenerated {
+			own++
+		} else if r.Message == "feat: add c" {
+			hand++
+		}
+	}
+	checks.Check("hand-made commit imported after the flush", hand == 1, hand)
+	checks.Check("own commit recorded once", own == 1 && len(records) == 4, len(records))
+	eng.Stop()
+
+	fmt.Println("=== amend window ===")
+	cfg.AmendWindowSeconds = 3600
+	eng = newEngine(cfg)
+	gittest.Write(filepath.Join(tmp, "e.go"), "package main\n")
+	commit(tmp, "feat: add e by hand", "", "e.go")
+	checks.Check("hand-made HEAD imported", eng.ReconcileOutsideCommits() == 1, "")
+	// Touches the same file, so only the amend window decides
+	gittest.Write(filepath.Join(tmp, "e.go"), "package main\n\nvar e = 1\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "e.go", Type: watcher.Modified}}})
+	eng.Flush()
+	eng.Stop()
+	cfg.AmendWindowSeconds = 0
+	subjects := strings.Split(strings.TrimSpace(gittest.Run(tmp, "git", "log", "-2", "--format=%s")), "\n")
+	checks.Check("hand-made commit not amended", len(subjects) == 2 && subjects[1] == "feat: add e by hand", subjects)
+	content := gittest.Run(tmp, "git", "show", "HEAD~1:e.go")
+	checks.Check("hand-made commit keeps its content", content == "package main\n", content)
+
+	fmt.Println("=== backdated commit ===")
+	eng = newEngine(cfg)
+	gittest.Write(filepath.Join(tmp, "g.go"), "package main\n")
+	commit(tmp, "feat: add g", "2024-03-03T10:00:00Z", "g.go")
+	checks.Check("imported", eng.ReconcileOutsideCommits() == 1, "")
+	eng.Stop()
+	records = history(historyPath)
+	var messages []string
+	for _, r := range records {
+		messages = append(messages, r.Message)
+	}
+	inOrder := sort.SliceIsSorted(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+	checks.Check("placed by its date, not appended", inOrder && len(records) == 7 && records[2].Message == "feat: add g", messages)
+
+	fmt.Println("=== import off ===")
+	cfg.ImportOutsideCommits = false
+	eng = newEngine(cfg)
+	defer eng.Stop()
+	gittest.Write(filepath.Join(tmp, "d.go"), "package main\n")
+	commit(tmp, "feat: add d", "", "d.go")
+	checks.Check("counted", eng.ReconcileOutsideCommits() == 1, "")
+	checks.Check("not imported", len(history(historyPath)) == 7, len(history(historyPath)))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll outside commit checks passed.")
+}
+
+func newEngine(cfg *config.Config) *engine.Engine {
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	return eng
+}
+
+// commit commits files by hand, with the given author date if set.
+func commit(dir, message, date string, files ...string) {
+	gittest.Run(dir, "git", append([]string{"add", "--"}, files...)...)
+	cmd := exec.Command("git", "commit", "-q", "-m", message)
+	cmd.Dir = dir
+	if date != "" {
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		gittest.Fail("commit: "+string(out), err)
+	}
+}
+
+// history reads the commit records from path.
+func history(path string) []store.CommitRecord {
+	s, err := store.New(path)
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	return s.All()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+)
+
+// Checks that AI fix patches apply across line-ending styles, using a real
+// CRLF file on disk (no API key needed):
+//
+//	go run ./cmd/testpatch
+func main() {
+	checks := &gittest.Checks{}
+
+	dir, err := os.MkdirTemp("", "gitpulse-testpatch")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// ── CRLF file, LF patch from the model ──
+	fmt.Println("=== CRLF file, LF patch ===")
+	crlfPath := filepath.Join(dir, "crlf.go")
+	crlf := "package main\r\n\r\nfunc f(x *int) int {\r\n\treturn *x\r\n}\r\n"
+	if err := os.WriteFile(crlfPath, []byte(crlf), 0644); err != nil {
+		gittest.Fail("write CRLF file", err)
+	}
+	data, _ := os.ReadFile(crlfPath)
+	fixed, ok := ai.ApplyPatch(string(data),
+		"func f(x *int) int {\n\treturn *x\n}",
+		"func f(x *int) int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn *x\n}")
+	want := "package main\r\n\r\nfunc f(x *int) int {\r\n\tif x == nil {\r\n\t\treturn 0\r\n\t}\r\n\treturn *x\r\n}\r\n"
+	checks.Check("patch applies", ok, fixed)
+	checks.Check("file keeps CRLF line endings", fixed == want, fixed)
+
+	// ── LF file, CRLF patch ──
+	fmt.Println("=== LF file, CRLF patch ===")
+	lf := "a\nb\nc\n"
+	fixed, ok = ai.ApplyPatch(lf, "a\r\nb", "a\r\nB")
+	checks.Check("patch applies", ok, fixed)
+	checks.Check("file keeps LF line endings", fixed == "a\nB\nc\n", fixed)
+
+	// ── exact match still wins, missing code still fails ──
+	fmt.Println("=== exact and missing ===")
+	fixed, ok = ai.ApplyPatch(lf, "b\n", "x\n")
+	checks.Check("exact match replaced once", ok && fixed == "a\nx\nc\n", fixed)
+	_, ok = ai.ApplyPatch(crlf, "return *y", "return 0")
+	checks.Check("missing old_code rejected", !ok, "")
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll patch checks passed.")
+}
@@ -114,7 +114,7 @@ func main() {
 
 	// ── Step 5: Claude refinement + commit messages ──
 	fmt.Println("\n=== Step 5: Claude RefineAndCommit ===")
-	aiClient := ai.NewClient(cfg.AI.APIKey, cfg.AI.Model)
+	aiClient := ai.NewClient(cfg.AI.APIKey, cfg.AI.Model, nil)
 
 	refined, err := aiClient.RefineAndCommit(groups)
 	if err != nil {
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/dashboard"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Buffers changes and checks the plan the engine writes for the dashboard
+// (pending files, proposed groups and messages), served by GET /api/plan,
+// then approves it with POST /api/plan/flush and checks the plan empties
+// and the commits are made:
+//
+//	go run ./cmd/testplan
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testplan")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+
+	historyPath := filepath.Join(tmp, ".gitpulse", "history.json")
+	s, err := store.New(historyPath)
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	srv := dashboard.NewServer(s, historyPath)
+	srv.SetAuthToken("secret")
+	srv.SetFlushFunc(func() error {
+		eng.PushNow()
+		return nil
+	})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	fmt.Println("=== no daemon plan yet ===")
+	plan := getPlan(ts.URL)
+	checks.Check("empty plan", len(plan.Pending) == 0 && len(plan.Groups) == 0, plan)
+
+	fmt.Println("=== pending changes ===")
+	gittest.Write(filepath.Join(tmp, "auth", "login.go"), "package auth\n")
+	gittest.Write(filepath.Join(tmp, "auth", "token.go"), "package auth\n")
+	gittest.Write(filepath.Join(tmp, "docs", "guide.md"), "# Guide\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "auth/login.go", Type: watcher.Created},
+		{Path: "auth/token.go", Type: watcher.Created},
+	}})
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "docs/guide.md", Type: watcher.Created},
+		{Path: "auth/login.go", Type: watcher.Modified},
+	}})
+
+	plan = getPlan(ts.URL)
+	checks.Check("pending files listed once each", len(plan.Pending) == 3, plan.Pending)
+	checks.Check("latest change type wins", len(plan.Pending) == 3 && plan.Pending[0].Path == "auth/login.go" && plan.Pending[0].Status == "modified", plan.Pending)
+	var authGroup *store.PlanGroup
+	for i, g := range plan.Groups {
+		if strings.Join(g.Files, ",") == "auth/login.go,auth/token.go" {
+			authGroup = &plan.Groups[i]
+		}
+	}
+	checks.Check("auth files proposed as one commit", authGroup != nil, plan.Groups)
+	checks.Check("proposed message uses the package scope", authGroup != nil && strings.HasPrefix(authGroup.Message, "feat(auth): "), authGroup)
+	checks.Check("docs proposed separately", len(plan.Groups) == 2, len(plan.Groups))
+
+	fmt.Println("=== approve from the dashboard ===")
+	checks.Check("flush needs the token", post(ts.URL+"/api/plan/flush", "") == http.StatusUnauthorized, "")
+	checks.Check("flush accepted", post(ts.URL+"/api/plan/flush", "secret") == http.StatusAccepted, "")
+	plan = getPlan(ts.URL)
+	checks.Check("plan empty after the flush", len(plan.Pending) == 0 && len(plan.Groups) == 0, plan)
+	status := gittest.Run(tmp, "git", "status", "--porcelain", "--", "auth", "docs")
+	checks.Check("changes committed", strings.TrimSpace(status) == "", status)
+
+	fmt.Println("=== daemon stopped ===")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "auth/login.go", Type: watcher.Modified}}})
+	eng.Stop()
+	_, err = os.Stat(filepath.Join(tmp, ".gitpulse", "plan.json"))
+	checks.Check("plan file removed", os.IsNotExist(err), err)
+	plan = getPlan(ts.URL)
+	checks.Check("empty plan served", len(plan.Pending) == 0 && len(plan.Groups) == 0, plan)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll plan checks passed.")
+}
+
+// getPlan GETs /api/plan.
+func getPlan(base string) store.Plan {
+	var plan store.Plan
+	resp, err := http.Get(base + "/api/plan")
+	if err != nil {
+		gittest.Fail("GET /api/plan", err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&plan); err != nil {
+		gittest.Fail("decode plan", err)
+	}
+	return plan
+}
+
+// post POSTs to url with token (if any) and returns the status code.
+func post(url, token string) int {
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		gittest.Fail("build request", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		gittest.Fail("POST "+url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
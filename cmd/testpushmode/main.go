@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Checks push_mode against a local bare remote: manual commits on flush but
+// only pushes on PushNow (`gitpulse push`), never keeps commits local even on
+// PushNow, and the deprecated auto_push: false still means never. Offline
+// commit messages, so no API key is needed:
+//
+//	go run ./cmd/testpushmode
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testpushmode")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	// ── Step 1: legacy auto_push: false ──
+	fmt.Println("=== Step 1: Legacy auto_push ===")
+	legacy := filepath.Join(tmp, "legacy")
+	gittest.Write(filepath.Join(legacy, ".gitpulse", "config.yaml"), "auto_push: false\n")
+	cfg, err := config.LoadFromDir(legacy, legacy)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	checks.Check("auto_push: false reads as push_mode: never", cfg.PushMode == config.PushModeNever, cfg.PushMode)
+	gittest.Write(filepath.Join(legacy, ".gitpulse", "config.yaml"), "push_mode: sometimes\n")
+	_, err = config.LoadFromDir(legacy, legacy)
+	checks.Check("unknown push_mode is rejected", err != nil, err)
+
+	// ── Step 2: manual ──
+	fmt.Println("\n=== Step 2: push_mode: manual ===")
+	repo, remote := setup(tmp, "manual")
+	eng := newEngine(repo, config.PushModeManual)
+	commitFile(eng, repo, "a.go")
+	checks.Check("flush commits but does not push", !onRemote(repo, remote), "pushed")
+	eng.PushNow()
+	checks.Check("PushNow pushes", onRemote(repo, remote), "not pushed")
+	checks.Check("store marks the commit pushed", len(openStore(repo).GetUnpushedCommits()) == 0, len(openStore(repo).GetUnpushedCommits()))
+	eng.Stop()
+
+	// ── Step 3: never ──
+	fmt.Println("\n=== Step 3: push_mode: never ===")
+	repo, remote = setup(tmp, "never")
+	eng = newEngine(repo, config.PushModeNever)
+	commitFile(eng, repo, "b.go")
+	eng.PushNow()
+	checks.Check("PushNow does not push", !onRemote(repo, remote), "pushed")
+	records := openStore(repo).All()
+	checks.Check("record is marked local-only", len(records) == 1 && records[0].LocalOnly && !records[0].Pushed, records)
+	eng.Stop()
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll push_mode checks passed.")
+}
+
+// setup creates a repo under tmp/name with one commit pushed to a bare remote.
+func setup(tmp, name string) (repo, remote string) {
+	r, err := gittest.New(filepath.Join(tmp, name))
+	if err != nil {
+		gittest.Fail("set up remote", err)
+	}
+	return r.Dir, r.Bare
+}
+
+func newEngine(repo, mode string) *engine.Engine {
+	cfg, err := config.LoadFromDir(repo, repo)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = mode
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	return eng
+}
+
+func commitFile(eng *engine.Engine, repo, name string) {
+	gittest.Write(filepath.Join(repo, name), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: name, Type: watcher.Created}}})
+	eng.Flush()
+}
+
+// onRemote reports whether the remote's main has the local HEAD.
+func onRemote(repo, remote string) bool {
+	head := strings.TrimSpace(gittest.Run(repo, "git", "rev-parse", "HEAD"))
+	return strings.TrimSpace(gittest.Run(remote, "git", "rev-parse", "main")) == head
+}
+
+func openStore(repo string) *store.Store {
+	s, err := store.New(filepath.Join(repo, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	return s
+}
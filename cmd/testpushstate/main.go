@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Simulates a push that succeeds while recording it in history.json fails,
+// then checks that the store heals itself from the remote on the next start.
+// Uses a throwaway repo + bare remote and offline commit messages:
+//
+//	go run ./cmd/testpushstate
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testpushstate")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	// ── Step 1: repo with a bare remote ──
+	fmt.Println("=== Step 1: Set up repo and remote ===")
+	remote, err := gittest.New(tmp)
+	if err != nil {
+		gittest.Fail("set up remote", err)
+	}
+	repo := remote.Dir
+
+	// ── Step 2: GitPulse commits a change (manual push mode) ──
+	fmt.Println("\n=== Step 2: Commit via engine ===")
+	cfg, err := config.LoadFromDir(repo, repo)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeManual
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(repo, "main.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.Stop()
+
+	historyPath := filepath.Join(repo, ".gitpulse", "history.json")
+	s, err := store.New(historyPath)
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	unpushed := s.GetUnpushedCommits()
+	checks.Check("one unpushed commit recorded", len(unpushed) == 1, len(unpushed))
+	if len(unpushed) != 1 {
+		os.Exit(1)
+	}
+	hash := unpushed[0].Hash
+
+	// ── Step 3: push succeeds, MarkPushed's flush fails ──
+	fmt.Println("\n=== Step 3: Push, then fail to record it ===")
+	gittest.Run(repo, "git", "push", "-q", "origin", "main")
+	gitpulseDir := filepath.Join(repo, ".gitpulse")
+	if err := os.Rename(gitpulseDir, gitpulseDir+".away"); err != nil {
+		gittest.Fail("hide .gitpulse", err)
+	}
+	err = s.MarkPushed([]string{hash}, "origin", "main")
+	if err := os.Rename(gitpulseDir+".away", gitpulseDir); err != nil {
+		gittest.Fail("restore .gitpulse", err)
+	}
+	checks.Check("MarkPushed reports the flush failure", err != nil, err)
+	checks.Check("failed MarkPushed leaves the record unpushed in memory", !s.GetByHash(hash).Pushed, s.GetByHash(hash).Pushed)
+
+	// ── Step 4: next start reconciles from the remote ──
+	fmt.Println("\n=== Step 4: Restart and reconcile ===")
+	eng, err = engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	fixed := eng.ReconcilePushState()
+	checks.Check("reconcile corrects one record", fixed == 1, fixed)
+	checks.Check("reconcile is idempotent", eng.ReconcilePushState() == 0, "non-zero")
+	eng.Stop()
+
+	s, err = store.New(historyPath)
+	if err != nil {
+		gittest.Fail("reopen history", err)
+	}
+	r := s.GetByHash(hash)
+	checks.Check("history.json now shows the commit as pushed", r != nil && r.Pushed && r.Remote == "origin" && r.Branch == "main", r)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll push-state checks passed.")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Starts the engine where .gitpulse can't be created (a plain file is in the
+// way, which also holds when running as root) and checks that it still
+// commits with an in-memory history, unless require_persistent_history is set:
+//
+//	go run ./cmd/testreadonly
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testreadonly")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	gittest.Write(filepath.Join(tmp, ".gitpulse"), "not a directory\n")
+
+	fmt.Println("=== require_persistent_history: true ===")
+	cfg.RequirePersistentHistory = true
+	_, err = engine.New(cfg, ui.New(nil))
+	checks.Check("engine refuses to start", err != nil, err)
+
+	fmt.Println("\n=== require_persistent_history: false (default) ===")
+	cfg.RequirePersistentHistory = false
+	eng, err := engine.New(cfg, ui.New(nil))
+	checks.Check("engine starts with an in-memory history", err == nil, err)
+	if err != nil {
+		os.Exit(1)
+	}
+	gittest.Write(filepath.Join(tmp, "main.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.Stop()
+
+	files := strings.Fields(gittest.Run(tmp, "git", "show", "--name-only", "--format=", "HEAD"))
+	checks.Check("change still committed", strings.Join(files, ",") == "main.go", files)
+	info, err := os.Stat(filepath.Join(tmp, ".gitpulse"))
+	checks.Check(".gitpulse left as it was", err == nil && !info.IsDir(), err)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll unwritable-history checks passed.")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+var filesRe = regexp.MustCompile(`(?m)^\s*Files: (.*)$`)
+
+// fakeAPI answers refine prompts with refined (or, if empty, one group per
+// pre-group) and any other prompt with a plain commit message.
+type fakeAPI struct {
+	refined string
+}
+
+func (f *fakeAPI) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req struct {
+		Messages []struct {
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	prompt := req.Messages[0].Content
+
+	text := "feat: change files"
+	if strings.HasPrefix(prompt, "You are a git commit assistant. Analyze") {
+		text = f.refined
+		if text == "" {
+			var refined []map[string]interface{}
+			for _, g := range filesRe.FindAllStringSubmatch(prompt, -1) {
+				refined = append(refined, map[string]interface{}{
+					"files": strings.Split(g[1], ", "), "reason": "r", "commit_message": "feat: change " + g[1],
+				})
+			}
+			b, _ := json.Marshal(refined)
+			text = string(b)
+		}
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+	})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(body))),
+		Request:    r,
+	}, nil
+}
+
+// diff returns a one-line change to path as git diff prints it.
+func diff(path string) string {
+	return fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -1 +1 @@\n-package main\n+package main // %s\n", path, path, path, path, path)
+}
+
+// Runs RefineAndCommit against a fake API and checks every returned group
+// carries the diffs of exactly its own files, in order, so the review that
+// follows has content: when the model moves files between groups, when it
+// keeps them in batches (ai.max_groups_per_request) and when its reply
+// isn't JSON and the pre-groups are kept. No real API calls:
+//
+//	go run ./cmd/testrefinediffs
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testrefinediffs")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	pregroups := func() []grouper.FileGroup {
+		return []grouper.FileGroup{
+			{Files: []string{"a.go", "b.go"}, Reason: "same package", Diffs: diff("a.go") + diff("b.go")},
+			{Files: []string{"c.go"}, Reason: "singleton", Diffs: diff("c.go")},
+		}
+	}
+	// ownDiffs reports whether every group's Diffs is its files' diffs
+	ownDiffs := func(groups []grouper.FileGroup) bool {
+		for _, g := range groups {
+			var want strings.Builder
+			for _, f := range g.Files {
+				want.WriteString(diff(f))
+			}
+			if g.Diffs == "" || g.Diffs != want.String() {
+				return false
+			}
+		}
+		return true
+	}
+	files := func(groups []grouper.FileGroup) string {
+		var out []string
+		for _, g := range groups {
+			out = append(out, strings.Join(g.Files, "+"))
+		}
+		return strings.Join(out, " ")
+	}
+
+	fmt.Println("=== files moved between groups ===")
+	api := &fakeAPI{refined: `[{"files":["a.go"],"reason":"r","commit_message":"feat: a"},{"files":["c.go","b.go"],"reason":"r","commit_message":"feat: b and c"}]`}
+	refined, err := ai.NewClient("test-key", "test-model", &http.Client{Transport: api}).RefineAndCommit(pregroups())
+	checks.Check("refine succeeds", err == nil, err)
+	checks.Check("regrouped", files(refined) == "a.go c.go+b.go", files(refined))
+	checks.Check("every group has its files' diffs", ownDiffs(refined), refined)
+
+	fmt.Println("\n=== batched ===")
+	api = &fakeAPI{}
+	c := ai.NewClient("test-key", "test-model", &http.Client{Transport: api})
+	c.SetMaxGroupsPerRequest(1)
+	refined, err = c.RefineAndCommit(pregroups())
+	checks.Check("refine succeeds", err == nil, err)
+	checks.Check("groups kept", files(refined) == "a.go+b.go c.go", files(refined))
+	checks.Check("every group has its files' diffs", ownDiffs(refined), refined)
+
+	fmt.Println("\n=== reply isn't JSON ===")
+	api = &fakeAPI{refined: "Sure! Here are your groups."}
+	refined, err = ai.NewClient("test-key", "test-model", &http.Client{Transport: api}).RefineAndCommit(pregroups())
+	checks.Check("refine succeeds", err == nil, err)
+	checks.Check("pre-groups kept", files(refined) == "a.go+b.go c.go", files(refined))
+	checks.Check("every group has its files' diffs", ownDiffs(refined), refined)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll refine diff checks passed.")
+}
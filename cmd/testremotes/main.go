@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Pushes to three remotes, one of which is missing: the other two still get
+// the commit, the store records exactly which remotes did, and the next
+// `gitpulse push` (PushNow) catches up the one that failed:
+//
+//	go run ./cmd/testremotes
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testremotes")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	// ── Step 1: a repo with origin, mirror and a not-yet-created backup ──
+	fmt.Println("=== Step 1: Set up repo with three remotes ===")
+	remote, err := gittest.New(tmp)
+	if err != nil {
+		gittest.Fail("set up remote", err)
+	}
+	repo := remote.Dir
+	if _, err := remote.AddRemote("mirror"); err != nil {
+		gittest.Fail("add mirror", err)
+	}
+	gittest.Run(repo, "git", "remote", "add", "backup", filepath.Join(tmp, "backup.git"))
+
+	// ── Step 2: flush with push_mode: auto ──
+	fmt.Println("\n=== Step 2: Commit and push to every remote ===")
+	cfg, err := config.LoadFromDir(repo, repo)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.Remotes = []string{"origin", "backup", "mirror"}
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(repo, "main.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Created}}})
+	eng.Flush()
+
+	head := strings.TrimSpace(gittest.Run(repo, "git", "rev-parse", "HEAD"))
+	checks.Check("origin has the commit", remoteHead(tmp, "origin") == head, remoteHead(tmp, "origin"))
+	checks.Check("mirror has the commit despite backup failing first", remoteHead(tmp, "mirror") == head, remoteHead(tmp, "mirror"))
+
+	s := openStore(repo)
+	r := s.GetByHash(head)
+	checks.Check("record lists the remotes that succeeded", r != nil && strings.Join(r.PushedRemotes, ",") == "origin,mirror", r)
+	checks.Check("record counts as pushed", r != nil && r.Pushed && r.Remote == "origin", r)
+	checks.Check("still unpushed to backup", len(s.GetUnpushedTo("backup")) == 1, len(s.GetUnpushedTo("backup")))
+	checks.Check("nothing unpushed to origin", len(s.GetUnpushedTo("origin")) == 0, len(s.GetUnpushedTo("origin")))
+
+	// ── Step 3: the backup remote appears; gitpulse push catches it up ──
+	fmt.Println("\n=== Step 3: Create backup, push again ===")
+	gittest.Run(tmp, "git", "init", "-q", "--bare", filepath.Join(tmp, "backup.git"))
+	eng.PushNow()
+	eng.Stop()
+
+	checks.Check("backup has the commit", remoteHead(tmp, "backup") == head, remoteHead(tmp, "backup"))
+	r = openStore(repo).GetByHash(head)
+	checks.Check("record now lists all three remotes", r != nil && strings.Join(r.PushedRemotes, ",") == "origin,mirror,backup", r)
+
+	// ── Step 4: a single remote still works through remote: ──
+	fmt.Println("\n=== Step 4: Single remote ===")
+	cfg.Remotes = nil
+	checks.Check("remote alone is the push target", strings.Join(cfg.PushRemotes(), ",") == "origin", cfg.PushRemotes())
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll multi-remote checks passed.")
+}
+
+func remoteHead(tmp, name string) string {
+	cmd := exec.Command("git", "rev-parse", "main")
+	cmd.Dir = filepath.Join(tmp, name+".git")
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func openStore(repo string) *store.Store {
+	s, err := store.New(filepath.Join(repo, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	return s
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Simulates an in-progress rebase (.git/rebase-merge, .git/rebase-apply)
+// and merge (.git/MERGE_HEAD): flushing is held with the changes still
+// buffered, and they're committed automatically once the marker is gone.
+// Then flushes in a fresh repo with no commits, making the root commit:
+//
+//	go run ./cmd/testrepostate
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testrepostate")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+
+	head := gittest.Run(tmp, "git", "rev-parse", "HEAD")
+	markers := []struct {
+		name string
+		dir  bool
+	}{
+		{"rebase-merge", true},
+		{"rebase-apply", true},
+		{"MERGE_HEAD", false},
+	}
+	for i, m := range markers {
+		fmt.Printf("=== %s ===\n", m.name)
+		marker := filepath.Join(tmp, ".git", m.name)
+		if m.dir {
+			if err := os.MkdirAll(marker, 0755); err != nil {
+				gittest.Fail("create "+m.name, err)
+			}
+		} else {
+			gittest.Write(marker, head)
+		}
+
+		file := fmt.Sprintf("file%d.go", i)
+		gittest.Write(filepath.Join(tmp, file), "package main\n")
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: file, Type: watcher.Created}}})
+		before := commitCount(tmp)
+		eng.Flush()
+		checks.Check("flush held", commitCount(tmp) == before, commitCount(tmp))
+		checks.Check("changes still buffered", eng.PendingCount() == 1, eng.PendingCount())
+
+		// Finishing the operation clears the marker; the held flush resumes
+		if err := os.RemoveAll(marker); err != nil {
+			gittest.Fail("remove "+m.name, err)
+		}
+		resumed := false
+		for j := 0; j < 100 && !resumed; j++ {
+			time.Sleep(100 * time.Millisecond)
+			resumed = commitCount(tmp) == before+1 && eng.PendingCount() == 0
+		}
+		checks.Check("committed once the operation finished", resumed, commitCount(tmp)-before)
+	}
+
+	// ── no HEAD yet: the first flush makes the root commit ──
+	fmt.Println("=== empty repo ===")
+	empty, err := os.MkdirTemp("", "gitpulse-testrepostate-empty")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(empty)
+	gittest.InitRepo(empty)
+	cfg, err = config.LoadFromDir(empty, empty)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	first, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(empty, "main.go"), "package main\n")
+	gittest.Write(filepath.Join(empty, "docs", "guide.md"), "# Guide\n")
+	first.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "main.go", Type: watcher.Created},
+		{Path: "docs/guide.md", Type: watcher.Created},
+	}})
+	first.Flush()
+	pending := first.PendingCount()
+	first.Stop()
+	out, err := exec.Command("git", "-C", empty, "rev-parse", "--verify", "-q", "HEAD").CombinedOutput()
+	checks.Check("first flush committed", err == nil && pending == 0, string(out))
+	if err != nil {
+		os.Exit(1)
+	}
+	root := strings.Fields(gittest.Run(empty, "git", "rev-list", "--max-parents=0", "HEAD"))
+	checks.Check("root commit has no parent", len(root) == 1, root)
+	tracked := strings.Fields(gittest.Run(empty, "git", "ls-tree", "-r", "--name-only", "HEAD"))
+	checks.Check("every file committed", strings.Join(tracked, ",") == "docs/guide.md,main.go", tracked)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll rebase/merge state checks passed.")
+}
+
+// commitCount returns how many commits HEAD has.
+func commitCount(dir string) int {
+	return len(strings.Fields(gittest.Run(dir, "git", "rev-list", "HEAD")))
+}
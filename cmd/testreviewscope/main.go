@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// countingRepo is the real repo counting GetFileDiff calls per file.
+type countingRepo struct {
+	*git.Manager
+	mu    sync.Mutex
+	diffs map[string]int
+}
+
+func (r *countingRepo) GetFileDiff(path string) (string, error) {
+	r.mu.Lock()
+	r.diffs[path]++
+	r.mu.Unlock()
+	return r.Manager.GetFileDiff(path)
+}
+
+// recordingReviewer is the offline client with a reviewer that records the
+// files and diffs of each call. The first review finds a blocker in
+// pkg/a.go (related to pkg/c.go) and one in other/d.go; later ones find
+// nothing.
+type recordingReviewer struct {
+	*ai.OfflineClient
+	calls []string // sorted file list per call
+	diffs []string
+}
+
+func (c *recordingReviewer) ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error) {
+	var files []string
+	var diffs strings.Builder
+	for _, g := range groups {
+		files = append(files, g.Files...)
+		diffs.WriteString(g.Diffs)
+	}
+	sort.Strings(files)
+	c.calls = append(c.calls, strings.Join(files, " "))
+	c.diffs = append(c.diffs, diffs.String())
+	if len(c.calls) > 1 {
+		return &ai.ReviewResult{}, nil
+	}
+	return &ai.ReviewResult{
+		Findings: []ai.ReviewFinding{{
+			File:             "pkg/a.go",
+			StartLine:        3,
+			EndLine:          3,
+			Severity:         ai.SeverityError,
+			Description:      "wrong argument order",
+			RelatedLocations: []ai.Location{{File: "pkg/c.go", StartLine: 3, EndLine: 3}},
+		}, {
+			File:        "other/d.go",
+			StartLine:   1,
+			EndLine:     1,
+			Severity:    ai.SeverityWarning,
+			Description: "unused package",
+		}},
+		HasBlockers: true,
+	}, nil
+}
+
+// Runs the interactive review loop through a manual fix of one file and
+// checks that only that file is re-diffed and only it and its related
+// location are re-reviewed, that findings on untouched files stay, and that
+// a "fix" that changes nothing doesn't call the reviewer again:
+//
+//	go run ./cmd/testreviewscope
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testreviewscope")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.ReviewMinLines = 0
+	cfg.ConfirmGrouping = false
+
+	manager, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	repo := &countingRepo{Manager: manager, diffs: make(map[string]int)}
+	reviewer := &recordingReviewer{OfflineClient: ai.NewOfflineClient()}
+	stdin := make(chan string)
+	eng, err := engine.NewWithDeps(cfg, ui.New(stdin), repo, reviewer)
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	eng.Interactive = true
+
+	files := []string{"pkg/a.go", "pkg/b.go", "pkg/c.go", "other/d.go"}
+	var changes []watcher.FileChange
+	for _, f := range files {
+		gittest.Write(filepath.Join(tmp, f), "package x\n\nfunc f() {}\n")
+		changes = append(changes, watcher.FileChange{Path: f, Type: watcher.Created})
+	}
+	eng.Submit(watcher.ChangeSet{Files: changes})
+
+	done := make(chan struct{})
+	go func() {
+		eng.Flush()
+		close(done)
+	}()
+	answer := func(s string) {
+		select {
+		case stdin <- s:
+		case <-done:
+			gittest.Fail("answer "+fmt.Sprintf("%q", s), fmt.Errorf("flush finished early"))
+		case <-time.After(10 * time.Second):
+			gittest.Fail("answer "+fmt.Sprintf("%q", s), fmt.Errorf("no prompt"))
+		}
+	}
+
+	fmt.Println("=== Manual fix of one file ===")
+	answer("1") // fix manually
+	gittest.Write(filepath.Join(tmp, "pkg/a.go"), "package x\n\nfunc f() { fixed() }\n")
+	answer("") // re-review
+
+	fmt.Println("=== Manual fix that changes nothing ===")
+	answer("1")
+	answer("")
+	answer("3") // continue anyway
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		gittest.Fail("finish flush", fmt.Errorf("timed out"))
+	}
+	eng.Stop()
+
+	checks.Check("first review covers every file", len(reviewer.calls) > 0 && reviewer.calls[0] == "other/d.go pkg/a.go pkg/b.go pkg/c.go", reviewer.calls)
+	checks.Check("re-review covers the fixed file and its related location", len(reviewer.calls) > 1 && reviewer.calls[1] == "pkg/a.go pkg/c.go", reviewer.calls)
+	checks.Check("re-review sees the fix", len(reviewer.diffs) > 1 && strings.Contains(reviewer.diffs[1], "fixed()"), reviewer.diffs)
+	checks.Check("unchanged files aren't re-reviewed", len(reviewer.calls) == 2, reviewer.calls)
+	checks.Check("fixed file re-diffed", repo.diffs["pkg/a.go"] == 2, repo.diffs)
+	checks.Check("untouched files diffed once", repo.diffs["pkg/b.go"] == 1 && repo.diffs["pkg/c.go"] == 1 && repo.diffs["other/d.go"] == 1, repo.diffs)
+	committed, _ := exec.Command("git", "-C", tmp, "show", "HEAD:pkg/a.go").Output()
+	checks.Check("fixed content committed", strings.Contains(string(committed), "fixed()"), string(committed))
+	checks.Check("every file committed after continuing", strings.Contains(gittest.Run(tmp, "git", "ls-files"), "other/d.go"), gittest.Run(tmp, "git", "ls-files"))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll review scope checks passed.")
+}
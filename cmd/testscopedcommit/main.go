@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Watches a subdirectory of a repo while the user has unrelated changes
+// staged elsewhere (outside the watch root and inside it), and checks that
+// GitPulse commits only its own files and leaves the rest staged:
+//
+//	go run ./cmd/testscopedcommit
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testscopedcommit")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	// ── Step 1: repo with app/ (watched) and other/ (not watched) ──
+	fmt.Println("=== Step 1: Set up repo ===")
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "app", "main.go"), "package main\n")
+	gittest.Write(filepath.Join(tmp, "app", "notes.md"), "notes\n")
+	gittest.Write(filepath.Join(tmp, "other", "lib.go"), "package other\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	// ── Step 2: the user stages work of their own ──
+	fmt.Println("\n=== Step 2: Stage unrelated changes by hand ===")
+	gittest.Write(filepath.Join(tmp, "other", "lib.go"), "package other\n\nconst X = 1\n")
+	gittest.Write(filepath.Join(tmp, "other", "new.go"), "package other\n")
+	gittest.Write(filepath.Join(tmp, "app", "notes.md"), "notes, staged by hand\n")
+	gittest.Run(tmp, "git", "add", "other/lib.go", "other/new.go", "app/notes.md")
+
+	// ── Step 3: GitPulse commits a change in app/ ──
+	fmt.Println("\n=== Step 3: Commit via engine (watching app/) ===")
+	watch := filepath.Join(tmp, "app")
+	cfg, err := config.LoadFromDir(watch, watch)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	gittest.Write(filepath.Join(watch, "main.go"), "package main\n\nfunc main() {}\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Modified}}})
+	eng.Flush()
+	eng.Stop()
+
+	committed := strings.Fields(gittest.Run(tmp, "git", "show", "--name-only", "--format=", "HEAD"))
+	checks.Check("commit holds only app/main.go", strings.Join(committed, ",") == "app/main.go", committed)
+
+	staged := strings.Fields(gittest.Run(tmp, "git", "diff", "--cached", "--name-only"))
+	checks.Check("hand-staged files are still staged", strings.Join(staged, ",") == "app/notes.md,other/lib.go,other/new.go", staged)
+	checks.Check("app/main.go is clean in the index", !strings.Contains(gittest.Run(tmp, "git", "status", "--porcelain", "app/main.go"), "main.go"), gittest.Run(tmp, "git", "status", "--porcelain"))
+	checks.Check("staged content is unchanged", strings.Contains(gittest.Run(tmp, "git", "diff", "--cached", "other/lib.go"), "+const X = 1"), "missing")
+	checks.Check("index entry for app/main.go matches HEAD", gittest.Run(tmp, "git", "diff-index", "--cached", "--quiet", "HEAD", "--", "app/main.go") == "", "dirty")
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll scoped-commit checks passed.")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Checks that a snoozed engine keeps buffering without committing — neither
+// Flush nor the safety timer fires — and that Resume lets the safety timer
+// commit the buffered changes. Uses a throwaway repo and offline commit
+// messages:
+//
+//	go run ./cmd/testsnooze
+func main() {
+	repo, err := os.MkdirTemp("", "gitpulse-testsnooze")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(repo)
+
+	// ── Step 1: repo with one commit, 1s safety timer ──
+	fmt.Println("=== Step 1: Set up repo ===")
+	gittest.InitRepo(repo)
+	gittest.Write(filepath.Join(repo, "README.md"), "root\n")
+	gittest.Run(repo, "git", "add", ".")
+	gittest.Run(repo, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(repo, repo)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+	cfg.DebounceSeconds = 1
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+
+	checks := &gittest.Checks{}
+	commits := func() int {
+		return strings.Count(gittest.Run(repo, "git", "log", "--oneline"), "\n")
+	}
+
+	// ── Step 2: snoozed — change buffers, nothing commits ──
+	fmt.Println("\n=== Step 2: Snooze ===")
+	eng.Snooze(time.Now().Add(time.Hour))
+	gittest.Write(filepath.Join(repo, "main.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "main.go", Type: watcher.Created}}})
+	eng.Flush()
+	time.Sleep(2 * time.Second)
+	checks.Check("no commit while snoozed", commits() == 1, commits())
+	checks.Check("change still buffered", eng.PendingCount() == 1, eng.PendingCount())
+
+	// ── Step 3: resume — safety timer commits the buffered change ──
+	fmt.Println("\n=== Step 3: Resume ===")
+	eng.Resume()
+	time.Sleep(3 * time.Second)
+	checks.Check("buffered change committed after resume", commits() == 2, commits())
+	checks.Check("buffer drained", eng.PendingCount() == 0, eng.PendingCount())
+
+	// ── Step 4: a short snooze expires on its own ──
+	fmt.Println("\n=== Step 4: Snooze expiry ===")
+	eng.Snooze(time.Now().Add(500 * time.Millisecond))
+	_, snoozed := eng.SnoozedUntil()
+	checks.Check("snoozed", snoozed, snoozed)
+	time.Sleep(time.Second)
+	_, snoozed = eng.SnoozedUntil()
+	checks.Check("snooze expired", !snoozed, snoozed)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nPASS: snooze buffers without committing and resume flushes.")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Leaves a modified, a deleted, a new and some ignored files in a repo
+// before the engine starts, then checks that sync_existing_changes_on_start
+// buffers exactly the first three after startup_grace_seconds (and nothing
+// when off), skips files already pending, and that they get committed:
+//
+//	go run ./cmd/teststartsync
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-teststartsync")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "a.go"), "package main\n")
+	gittest.Write(filepath.Join(tmp, "b.go"), "package main\n")
+	gittest.Write(filepath.Join(tmp, "pending.go"), "package main\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	// Work in progress from before GitPulse started
+	gittest.Write(filepath.Join(tmp, "a.go"), "package main\n\nfunc a() {}\n")
+	os.Remove(filepath.Join(tmp, "b.go"))
+	gittest.Write(filepath.Join(tmp, "c.go"), "package main\n")
+	gittest.Write(filepath.Join(tmp, "debug.log"), "noise\n")
+	gittest.Write(filepath.Join(tmp, "node_modules", "x", "index.js"), "module.exports = 1\n")
+	gittest.Write(filepath.Join(tmp, "pending.go"), "package main\n\nfunc p() {}\n")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = ai.ProviderNone
+	cfg.PushMode = config.PushModeNever
+	cfg.StartupGraceSeconds = 1
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	newEngine := func() *engine.Engine {
+		eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, ai.NewOfflineClient())
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		return eng
+	}
+
+	fmt.Println("=== off ===")
+	checks.Check("off by default", !cfg.SyncExistingChangesOnStart, cfg.SyncExistingChangesOnStart)
+	eng := newEngine()
+	go eng.Run()
+	time.Sleep(2 * time.Second)
+	checks.Check("nothing buffered", eng.PendingCount() == 0, eng.PendingCount())
+	eng.Stop()
+
+	fmt.Println("=== on ===")
+	cfg.SyncExistingChangesOnStart = true
+	eng = newEngine()
+	go eng.Run()
+	time.Sleep(300 * time.Millisecond)
+	checks.Check("waits for the grace period", eng.PendingCount() == 0, eng.PendingCount())
+	time.Sleep(2 * time.Second)
+	checks.Check("buffered after it", eng.PendingCount() == 4, eng.PendingCount())
+	eng.Stop()
+
+	fmt.Println("=== already pending ===")
+	eng = newEngine()
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "pending.go", Type: watcher.Modified}}})
+	n := eng.SyncExistingChanges()
+	checks.Check("skips pending files and ignore_patterns", n == 3, n)
+	checks.Check("pending total", eng.PendingCount() == 4, eng.PendingCount())
+	eng.Flush()
+	eng.Stop()
+
+	tree := gittest.Run(tmp, "git", "ls-tree", "-r", "--name-only", "HEAD")
+	checks.Check("new file committed", strings.Contains(tree, "c.go"), tree)
+	checks.Check("deletion committed", !strings.Contains(tree, "b.go"), tree)
+	checks.Check("ignored files left out", !strings.Contains(tree, "debug.log") && !strings.Contains(tree, "node_modules"), tree)
+	a := gittest.Run(tmp, "git", "show", "HEAD:a.go")
+	checks.Check("modification committed", strings.Contains(a, "func a()"), a)
+	status := strings.TrimSpace(gittest.Run(tmp, "git", "status", "--porcelain", "--", "a.go", "b.go", "c.go", "pending.go"))
+	checks.Check("nothing left over", status == "", status)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll startup sync checks passed.")
+}
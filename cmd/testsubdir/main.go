@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Runs the engine with watch_path set to a subdirectory of the repo and checks
+// that the change is committed under its repo-relative path. Uses a throwaway
+// repo and offline commit messages, so no API key or remote is needed:
+//
+//	go run ./cmd/testsubdir
+func main() {
+	repo, err := os.MkdirTemp("", "gitpulse-testsubdir")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(repo)
+
+	// ── Step 1: repo with one commit, watch dir = repo/services/api ──
+	fmt.Println("=== Step 1: Set up repo ===")
+	gittest.InitRepo(repo)
+	gittest.Write(filepath.Join(repo, "README.md"), "root\n")
+	gittest.Run(repo, "git", "add", ".")
+	gittest.Run(repo, "git", "commit", "-q", "-m", "init")
+
+	watchDir := filepath.Join(repo, "services", "api")
+	gittest.Write(filepath.Join(watchDir, "handler.go"), "package api\n")
+	fmt.Println("  Watching", watchDir)
+
+	// ── Step 2: feed a watcher-relative change and flush ──
+	fmt.Println("\n=== Step 2: Flush change from subdirectory ===")
+	cfg, err := config.LoadFromDir(watchDir, watchDir)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "handler.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.Stop()
+
+	// ── Step 3: the commit must contain services/api/handler.go ──
+	fmt.Println("\n=== Step 3: Verify commit ===")
+	out := gittest.Run(repo, "git", "show", "--name-only", "--format=%s", "HEAD")
+	fmt.Print(indent(out))
+	if !strings.Contains(out, "services/api/handler.go") {
+		fmt.Fprintln(os.Stderr, "FAIL: services/api/handler.go was not committed")
+		os.Exit(1)
+	}
+	fmt.Println("\nPASS: subdirectory change committed with its repo-relative path.")
+}
+
+func indent(s string) string {
+	return "  " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n  ") + "\n"
+}
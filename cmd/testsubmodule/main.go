@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Moves a submodule to a new commit alongside an ordinary edit in the parent
+// repo and checks that GitPulse commits the pointer update on its own,
+// without diffing or staging the submodule's files in the parent:
+//
+//	go run ./cmd/testsubmodule
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testsubmodule")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	// ── Step 1: a library repo, and an app repo that has it as a submodule ──
+	fmt.Println("=== Step 1: Set up repo with a submodule ===")
+	lib := filepath.Join(tmp, "lib")
+	app := filepath.Join(tmp, "app")
+	for _, dir := range []string{lib, app} {
+		gittest.InitRepo(dir)
+	}
+	gittest.Write(filepath.Join(lib, "lib.go"), "package lib\n")
+	gittest.Run(lib, "git", "add", ".")
+	gittest.Run(lib, "git", "commit", "-q", "-m", "init lib")
+
+	gittest.Write(filepath.Join(app, "main.go"), "package main\n")
+	gittest.Run(app, "git", "add", ".")
+	gittest.Run(app, "git", "commit", "-q", "-m", "init app")
+	gittest.Run(app, "git", "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "vendor/lib")
+	gittest.Run(app, "git", "commit", "-q", "-m", "add lib submodule")
+
+	// ── Step 2: move the submodule forward and edit the app ──
+	fmt.Println("\n=== Step 2: Commit inside the submodule, edit the app ===")
+	sub := filepath.Join(app, "vendor", "lib")
+	gittest.Run(sub, "git", "config", "user.email", "test@gitpulse")
+	gittest.Run(sub, "git", "config", "user.name", "test")
+	gittest.Write(filepath.Join(sub, "lib.go"), "package lib\n\nconst Version = 2\n")
+	gittest.Run(sub, "git", "commit", "-q", "-am", "bump version")
+	subHead := strings.TrimSpace(gittest.Run(sub, "git", "rev-parse", "HEAD"))
+	gittest.Write(filepath.Join(sub, "scratch.go"), "package lib\n") // uncommitted, in the submodule
+	gittest.Write(filepath.Join(app, "main.go"), "package main\n\nfunc main() {}\n")
+
+	// ── Step 3: flush through the engine ──
+	fmt.Println("\n=== Step 3: Flush via engine ===")
+	cfg, err := config.LoadFromDir(app, app)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{
+		{Path: "vendor/lib/lib.go", Type: watcher.Modified},
+		{Path: "vendor/lib/scratch.go", Type: watcher.Created},
+		{Path: "main.go", Type: watcher.Modified},
+	}})
+	eng.Flush()
+	eng.Stop()
+
+	fmt.Println("\n=== Results ===")
+	subjects := strings.Split(strings.TrimSpace(gittest.Run(app, "git", "log", "--format=%H %s", "HEAD~2..HEAD")), "\n")
+
+	bump := ""
+	for _, line := range subjects {
+		hash, subject, _ := strings.Cut(line, " ")
+		if strings.HasPrefix(subject, "chore: bump submodule") {
+			bump = hash
+			checks.Check("bump commit message", subject == "chore: bump submodule vendor/lib to "+subHead[:7], subject)
+		}
+	}
+	checks.Check("submodule update committed", bump != "", subjects)
+	if bump != "" {
+		files := strings.Fields(gittest.Run(app, "git", "show", "--name-only", "--format=", bump))
+		checks.Check("bump commit holds only the gitlink", strings.Join(files, ",") == "vendor/lib", files)
+	}
+
+	entry := strings.Fields(gittest.Run(app, "git", "ls-tree", "HEAD", "vendor/lib"))
+	checks.Check("gitlink points at the submodule's HEAD", len(entry) == 4 && entry[0] == "160000" && entry[2] == subHead, entry)
+
+	tracked := strings.Fields(gittest.Run(app, "git", "ls-files"))
+	checks.Check("submodule files not added to the parent", strings.Join(tracked, ",") == ".gitmodules,main.go,vendor/lib", tracked)
+	checks.Check("main.go committed", !strings.Contains(gittest.Run(app, "git", "status", "--porcelain"), "main.go"), gittest.Run(app, "git", "status", "--porcelain"))
+
+	status := strings.TrimRight(gittest.Run(app, "git", "submodule", "status"), "\n")
+	checks.Check("submodule no longer out of date", strings.HasPrefix(status, " "+subHead), status)
+	checks.Check("submodule's own work left alone", strings.Contains(gittest.Run(sub, "git", "status", "--porcelain"), "scratch.go"), gittest.Run(sub, "git", "status", "--porcelain"))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll submodule checks passed.")
+}
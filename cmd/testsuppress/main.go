@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// source has one risky() call per way of suppressing it, and one that isn't.
+const source = `package api
+
+func a() { risky() } // gitpulse:ignore-review
+// gitpulse:ignore-review
+func b() { risky() }
+// gitpulse:ignore-review-start
+func gen1() { risky() }
+func gen2() { risky() }
+// gitpulse:ignore-review-end
+func c() { risky() }
+`
+
+// flaggingReviewer is the offline client with a reviewer that reports every
+// risky() call in api/handler.go as an error.
+type flaggingReviewer struct {
+	*ai.OfflineClient
+}
+
+func (c flaggingReviewer) ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error) {
+	finding := func(start, end int) ai.ReviewFinding {
+		return ai.ReviewFinding{
+			File:        "api/handler.go",
+			StartLine:   start,
+			EndLine:     end,
+			Severity:    ai.SeverityError,
+			Description: "risky() can panic",
+		}
+	}
+	return &ai.ReviewResult{
+		Findings:    []ai.ReviewFinding{finding(3, 3), finding(5, 5), finding(7, 8), finding(10, 10)},
+		HasBlockers: true,
+	}, nil
+}
+
+func (c flaggingReviewer) RefineAndReview(groups []grouper.FileGroup) ([]grouper.FileGroup, *ai.ReviewResult, error) {
+	groups, _ = c.RefineAndCommit(groups)
+	review, err := c.ReviewCode(groups)
+	return groups, review, err
+}
+
+// Flushes a file whose flagged lines are all but one marked with
+// gitpulse:ignore-review (trailing, on the line before, and a -start/-end
+// region): only the unmarked finding is recorded and blocks:
+//
+//	go run ./cmd/testsuppress
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testsuppress")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.PushMode = config.PushModeNever
+	cfg.ReviewMinLines = 0
+
+	repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+	if err != nil {
+		gittest.Fail("open repo", err)
+	}
+	eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, flaggingReviewer{ai.NewOfflineClient()})
+	if err != nil {
+		gittest.Fail("create engine", err)
+	}
+	defer eng.Stop()
+
+	gittest.Write(filepath.Join(tmp, "api", "handler.go"), source)
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "api/handler.go", Type: watcher.Created}}})
+	eng.Flush() // non-interactive: the review is recorded but doesn't block
+
+	s, err := store.New(filepath.Join(tmp, ".gitpulse", "history.json"))
+	if err != nil {
+		gittest.Fail("open history", err)
+	}
+	records := s.GetByFile("api/handler.go")
+	if len(records) != 1 || records[0].Review == nil {
+		gittest.Fail("find the reviewed commit", fmt.Errorf("got %v", records))
+	}
+	review := records[0].Review
+	checks.Check("suppressed findings dropped", len(review.Findings) == 1, review.Findings)
+	checks.Check("unmarked finding kept", len(review.Findings) == 1 && review.Findings[0].StartLine == 10, review.Findings)
+	checks.Check("still blocks", review.HasBlockers, review)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll review suppression checks passed.")
+}
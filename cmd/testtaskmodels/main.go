@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// countingAI counts which calls it gets, standing in for the Claude client
+// of one model.
+type countingAI struct {
+	*ai.OfflineClient
+	refines  int
+	reviews  int
+	combined int
+}
+
+func (c *countingAI) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	c.refines++
+	return c.OfflineClient.RefineWithHint(groups, hint)
+}
+
+func (c *countingAI) ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error) {
+	c.reviews++
+	return &ai.ReviewResult{}, nil
+}
+
+func (c *countingAI) RefineAndReview(groups []grouper.FileGroup) ([]grouper.FileGroup, *ai.ReviewResult, error) {
+	c.combined++
+	groups, _ = c.OfflineClient.RefineAndCommit(groups)
+	return groups, &ai.ReviewResult{}, nil
+}
+
+// Checks ai.commit_model / ai.review_model: both default to ai.model, and
+// with a separate review client the review goes to it while grouping and
+// messages stay on the commit client, also for async reviews. Uses a
+// throwaway repo and fake AI clients:
+//
+//	go run ./cmd/testtaskmodels
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testtaskmodels")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "README.md"), "hello\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	fmt.Println("=== config ===")
+	gittest.Write(filepath.Join(tmp, "config.yaml"), "ai:\n  model: base-model\n")
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	checks.Check("commit model defaults to ai.model", cfg.CommitModel() == "base-model", cfg.CommitModel())
+	checks.Check("review model defaults to ai.model", cfg.ReviewModel() == "base-model", cfg.ReviewModel())
+	gittest.Write(filepath.Join(tmp, "config.yaml"), "ai:\n  model: base-model\n  commit_model: cheap-model\n  review_model: strong-model\n")
+	cfg, err = config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	checks.Check("commit_model overrides", cfg.CommitModel() == "cheap-model", cfg.CommitModel())
+	checks.Check("review_model overrides", cfg.ReviewModel() == "strong-model", cfg.ReviewModel())
+	os.Remove(filepath.Join(tmp, "config.yaml"))
+
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.CombineRefineAndReview = true
+	code := "package main\n\nfunc a() int {\n\treturn 1\n}\n\nfunc b() int {\n\treturn 2\n}\n"
+
+	flush := func(commitAI, reviewAI *countingAI, file string) {
+		repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+		if err != nil {
+			gittest.Fail("open repo", err)
+		}
+		eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, commitAI)
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		if reviewAI != nil {
+			eng.SetReviewAI(reviewAI)
+		}
+		gittest.Write(filepath.Join(tmp, file), code)
+		eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: file, Type: watcher.Created}}})
+		eng.Flush()
+		eng.Stop()
+	}
+
+	fmt.Println("=== separate review model ===")
+	commitAI := &countingAI{OfflineClient: ai.NewOfflineClient()}
+	reviewAI := &countingAI{OfflineClient: ai.NewOfflineClient()}
+	flush(commitAI, reviewAI, "a.go")
+	checks.Check("grouping on the commit model", commitAI.refines == 1, commitAI.refines)
+	checks.Check("review on the review model", reviewAI.reviews == 1 && commitAI.reviews == 0, fmt.Sprint(reviewAI.reviews, commitAI.reviews))
+	checks.Check("no combined call across two models", commitAI.combined == 0 && reviewAI.combined == 0, commitAI.combined+reviewAI.combined)
+	checks.Check("review model doesn't group", reviewAI.refines == 0, reviewAI.refines)
+
+	fmt.Println("=== async review ===")
+	cfg.AI.ReviewMode = config.ReviewModeAsync
+	commitAI = &countingAI{OfflineClient: ai.NewOfflineClient()}
+	reviewAI = &countingAI{OfflineClient: ai.NewOfflineClient()}
+	flush(commitAI, reviewAI, "b.go")
+	checks.Check("async review on the review model", reviewAI.reviews == 1 && commitAI.reviews == 0, fmt.Sprint(reviewAI.reviews, commitAI.reviews))
+	cfg.AI.ReviewMode = config.ReviewModeBlocking
+
+	fmt.Println("=== one model ===")
+	cfg.AI.CommitModel, cfg.AI.ReviewModel = "", ""
+	commitAI = &countingAI{OfflineClient: ai.NewOfflineClient()}
+	flush(commitAI, nil, "c.go")
+	checks.Check("one combined call as before", commitAI.combined == 1 && commitAI.reviews == 0, fmt.Sprint(commitAI.combined, commitAI.reviews))
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll task model checks passed.")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// countingAI is the offline client recording the files of every refine call.
+type countingAI struct {
+	*ai.OfflineClient
+	refined [][]string
+}
+
+func (c *countingAI) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	var files []string
+	for _, g := range groups {
+		files = append(files, g.Files...)
+	}
+	c.refined = append(c.refined, files)
+	return c.OfflineClient.RefineWithHint(groups, hint)
+}
+
+func (c *countingAI) RefineAndCommit(groups []grouper.FileGroup) ([]grouper.FileGroup, error) {
+	return c.RefineWithHint(groups, "")
+}
+
+const (
+	original = "package fmtd\n\nfunc Add(a, b int) int {\n\treturn a+b\n}\n"
+	gofmted  = "package fmtd\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"
+	changed  = "package fmtd\n\nfunc Add(a, b int) int {\n\treturn a - b\n}\n"
+)
+
+// Flushes a whitespace-only change (a gofmt pass) and checks it's committed
+// as "style: format code" without an AI call, also next to a real change,
+// and that skip_ai_for_whitespace_only: false, non-whitespace edits and
+// moved lines still go to the AI:
+//
+//	go run ./cmd/testwhitespace
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testwhitespace")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	gittest.InitRepo(tmp)
+	gittest.Write(filepath.Join(tmp, "fmtd", "add.go"), original)
+	gittest.Write(filepath.Join(tmp, "feat", "feat.go"), "package feat\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "init")
+
+	cfg, err := config.LoadFromDir(tmp, tmp)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	checks.Check("on by default", cfg.SkipAIForWhitespaceOnly, cfg.SkipAIForWhitespaceOnly)
+	cfg.PushMode = config.PushModeNever
+	cfg.AI.CodeReview = false
+	cfg.AttributionTrailer = false
+
+	flush := func(files map[string]string) *countingAI {
+		repo, err := git.New(tmp, cfg.Remote, cfg.Branch)
+		if err != nil {
+			gittest.Fail("open repo", err)
+		}
+		client := &countingAI{OfflineClient: ai.NewOfflineClient()}
+		eng, err := engine.NewWithDeps(cfg, ui.New(nil), repo, client)
+		if err != nil {
+			gittest.Fail("create engine", err)
+		}
+		var changes []watcher.FileChange
+		for path, content := range files {
+			gittest.Write(filepath.Join(tmp, path), content)
+			changes = append(changes, watcher.FileChange{Path: path, Type: watcher.Modified})
+		}
+		eng.Submit(watcher.ChangeSet{Files: changes})
+		eng.Flush()
+		eng.Stop()
+		return client
+	}
+	subject := func(rev string) string {
+		return strings.TrimSpace(gittest.Run(tmp, "git", "log", "-1", "--format=%s", rev))
+	}
+	filesOf := func(rev string) string {
+		return strings.TrimSpace(gittest.Run(tmp, "git", "show", "--name-only", "--format=", rev))
+	}
+
+	fmt.Println("=== whitespace-only flush ===")
+	client := flush(map[string]string{"fmtd/add.go": gofmted})
+	checks.Check("no AI call", len(client.refined) == 0, client.refined)
+	checks.Check("committed as style: format code", subject("HEAD") == "style: format code", subject("HEAD"))
+
+	fmt.Println("\n=== next to a real change ===")
+	gittest.Write(filepath.Join(tmp, "fmtd", "add.go"), original)
+	gittest.Run(tmp, "git", "commit", "-q", "-am", "undo format")
+	client = flush(map[string]string{"fmtd/add.go": gofmted, "feat/feat.go": "package feat\n\nfunc New() {}\n"})
+	checks.Check("AI only sees the real change", len(client.refined) == 1 && strings.Join(client.refined[0], ",") == "feat/feat.go", client.refined)
+	checks.Check("formatting committed last", subject("HEAD") == "style: format code" && filesOf("HEAD") == "fmtd/add.go", subject("HEAD"))
+	checks.Check("real change committed with its own message", filesOf("HEAD~1") == "feat/feat.go" && subject("HEAD~1") != "style: format code", subject("HEAD~1"))
+
+	fmt.Println("\n=== not whitespace ===")
+	client = flush(map[string]string{"fmtd/add.go": changed})
+	checks.Check("AI called for a real edit", len(client.refined) == 1, client.refined)
+	checks.Check("not a style commit", subject("HEAD") != "style: format code", subject("HEAD"))
+
+	fmt.Println("\n=== moved lines ===")
+	// Reordering statements changes behavior even though no text changes
+	gittest.Write(filepath.Join(tmp, "fmtd", "run.go"), "package fmtd\n\nfunc Run() {\n\tdefer stop()\n\tstart()\n}\n")
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "add run")
+	client = flush(map[string]string{"fmtd/run.go": "package fmtd\n\nfunc Run() {\n\tstart()\n\tdefer stop()\n}\n"})
+	checks.Check("move within a hunk goes to the AI", len(client.refined) == 1, client.refined)
+	checks.Check("not a style commit", subject("HEAD") != "style: format code", subject("HEAD"))
+	var steps []string
+	for i := 1; i <= 20; i++ {
+		steps = append(steps, fmt.Sprintf("\tstep%d()", i))
+	}
+	body := func(lines []string) string {
+		return "package fmtd\n\nfunc Steps() {\n" + strings.Join(lines, "\n") + "\n}\n"
+	}
+	gittest.Write(filepath.Join(tmp, "fmtd", "steps.go"), body(steps))
+	gittest.Run(tmp, "git", "add", ".")
+	gittest.Run(tmp, "git", "commit", "-q", "-m", "add steps")
+	moved := append(append(append([]string{}, steps[1:18]...), steps[0]), steps[18:]...)
+	client = flush(map[string]string{"fmtd/steps.go": body(moved)})
+	checks.Check("move across hunks goes to the AI", len(client.refined) == 1, client.refined)
+	checks.Check("not a style commit", subject("HEAD") != "style: format code", subject("HEAD"))
+
+	fmt.Println("\n=== skip_ai_for_whitespace_only: false ===")
+	cfg.SkipAIForWhitespaceOnly = false
+	client = flush(map[string]string{"fmtd/add.go": "package fmtd\n\nfunc Add(a, b int) int {\n\treturn a  -  b\n}\n"})
+	checks.Check("AI called for whitespace", len(client.refined) == 1, client.refined)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll whitespace-only checks passed.")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/git/gittest"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// Creates a linked worktree (`git worktree add`) and commits through it with
+// the engine, then checks the commit landed on the worktree's branch and not
+// on the main checkout. Also checks bare repos are rejected with a clear
+// error. Offline commit messages, no remote needed:
+//
+//	go run ./cmd/testworktree
+func main() {
+	tmp, err := os.MkdirTemp("", "gitpulse-testworktree")
+	if err != nil {
+		gittest.Fail("create temp dir", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	checks := &gittest.Checks{}
+
+	// ── Step 1: main repo + linked worktree on branch "feature" ──
+	fmt.Println("=== Step 1: Set up worktree ===")
+	remote, err := gittest.New(tmp)
+	if err != nil {
+		gittest.Fail("set up repo", err)
+	}
+	mainRepo := remote.Dir
+	wt := filepath.Join(tmp, "feature-wt")
+	gittest.Run(mainRepo, "git", "worktree", "add", "-q", "-b", "feature", wt)
+	fmt.Println("  Worktree at", wt)
+
+	// ── Step 2: commit through the worktree ──
+	fmt.Println("\n=== Step 2: Commit via engine in worktree ===")
+	cfg, err := config.LoadFromDir(wt, wt)
+	if err != nil {
+		gittest.Fail("load config", err)
+	}
+	cfg.AI.Provider = "none"
+	cfg.PushMode = config.PushModeNever
+
+	eng, err := engine.New(cfg, ui.New(nil))
+	if err != nil {
+		gittest.Fail("create engine in worktree", err)
+	}
+	gittest.Write(filepath.Join(wt, "feature.go"), "package main\n")
+	eng.Submit(watcher.ChangeSet{Files: []watcher.FileChange{{Path: "feature.go", Type: watcher.Created}}})
+	eng.Flush()
+	eng.Stop()
+
+	// ── Step 3: verify with the git CLI ──
+	fmt.Println("\n=== Step 3: Verify ===")
+	files := gittest.Run(wt, "git", "show", "--name-only", "--format=", "feature")
+	checks.Check("commit on feature contains feature.go", strings.Contains(files, "feature.go"), strings.TrimSpace(files))
+	mainLog := gittest.Run(mainRepo, "git", "log", "--format=%s", "main")
+	checks.Check("main branch untouched", strings.TrimSpace(mainLog) == "init", strings.TrimSpace(mainLog))
+	status := gittest.Run(wt, "git", "status", "--porcelain")
+	checks.Check("worktree index matches the commit", !strings.Contains(status, "feature.go"), strings.TrimSpace(status))
+	mainStatus := gittest.Run(mainRepo, "git", "status", "--porcelain")
+	checks.Check("main checkout's index untouched", strings.TrimSpace(mainStatus) == "", strings.TrimSpace(mainStatus))
+
+	// ── Step 4: bare repos get a clear error ──
+	fmt.Println("\n=== Step 4: Bare repo ===")
+	_, err = git.New(remote.Bare, "origin", "auto")
+	checks.Check("bare repo rejected", err != nil && strings.Contains(err.Error(), "bare repository"), err)
+
+	if checks.Failed() {
+		os.Exit(1)
+	}
+	fmt.Println("\nAll worktree checks passed.")
+}
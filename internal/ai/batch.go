@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// errTooLarge means a single group is too large for one request, so there is
+// nothing left to split.
+var errTooLarge = errors.New("group too large for one request")
+
+// SetMaxGroupsPerRequest caps how many groups one refine, review or classify
+// request carries (ai.max_groups_per_request); bigger flushes are sent in batches and
+// the results merged. 0 or less sends every group at once.
+func (c *Client) SetMaxGroupsPerRequest(n int) {
+	c.maxGroups = n
+}
+
+// batches splits groups into runs of at most maxGroups.
+func (c *Client) batches(groups []grouper.FileGroup) [][]grouper.FileGroup {
+	if c.maxGroups <= 0 || len(groups) <= c.maxGroups {
+		return [][]grouper.FileGroup{groups}
+	}
+	var batches [][]grouper.FileGroup
+	for start := 0; start < len(groups); start += c.maxGroups {
+		end := start + c.maxGroups
+		if end > len(groups) {
+			end = len(groups)
+		}
+		batches = append(batches, groups[start:end])
+	}
+	return batches
+}
+
+// splitOnTooLarge runs call on groups and, when the API says the request is
+// too large, halves groups and runs each half the same way, concatenating
+// the results in order. A single group that is still too large fails with
+// errTooLarge.
+func splitOnTooLarge(groups []grouper.FileGroup, call func([]grouper.FileGroup) ([]grouper.FileGroup, error)) ([]grouper.FileGroup, error) {
+	result, err := call(groups)
+	if err == nil || !tooLarge(err) {
+		return result, err
+	}
+	if len(groups) <= 1 {
+		return nil, fmt.Errorf("%w: %v", errTooLarge, err)
+	}
+
+	mid := len(groups) / 2
+	left, err := splitOnTooLarge(groups[:mid], call)
+	if err != nil {
+		return nil, err
+	}
+	right, err := splitOnTooLarge(groups[mid:], call)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
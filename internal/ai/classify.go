@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// ClassifyChanges asks the model which conventional-commit type each file's
+// change is, for grouping_strategy: type and hybrid. Every file in groups
+// gets a type; one the model leaves out, or gives a type that isn't
+// allowed, is "chore". Groups are sent in batches (see
+// SetMaxGroupsPerRequest) and halved when the API says a request is too
+// large; a group too large on its own leaves its files "chore".
+func (c *Client) ClassifyChanges(groups []grouper.FileGroup) (map[string]string, error) {
+	reply := make(map[string]string)
+	for _, batch := range c.batches(groups) {
+		_, err := splitOnTooLarge(batch, func(b []grouper.FileGroup) ([]grouper.FileGroup, error) {
+			r, err := c.classifyBatch(b)
+			if len(b) == 1 && tooLarge(err) {
+				return b, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			for f, typ := range r {
+				reply[f] = typ
+			}
+			return b, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	types := make(map[string]string)
+	for _, g := range groups {
+		for _, f := range g.Files {
+			typ := strings.ToLower(strings.TrimSpace(reply[f]))
+			if !typeAllowed(c.commitTypes, typ) {
+				typ = "chore"
+			}
+			types[f] = typ
+		}
+	}
+	return types, nil
+}
+
+// classifyBatch is one classification request for groups, returning the
+// model's reply as is.
+func (c *Client) classifyBatch(groups []grouper.FileGroup) (map[string]string, error) {
+	var sb strings.Builder
+	sb.WriteString("You are a git commit assistant. Classify the change made to each file below by its conventional commit type.\n")
+	sb.WriteString(fmt.Sprintf("The type MUST be one of: %s\n", strings.Join(c.commitTypes, ", ")))
+	sb.WriteString("Judge each file by what its own diff does, not by its group: a bug fix is fix, new behavior is feat, ")
+	sb.WriteString("restructuring without a behavior change is refactor, and so on.\n\n")
+	sb.WriteString("Respond with ONLY valid JSON mapping every file path to its type, in this exact format:\n")
+	sb.WriteString(`{"path/to/file.go":"fix","path/to/other.go":"feat"}`)
+	sb.WriteString("\n\nChanges:\n\n")
+	writeGroups(&sb, groups)
+
+	text, _, err := c.callClaudeWithTokens(sb.String(), 2048, true)
+	if err != nil {
+		return nil, fmt.Errorf("claude API call failed: %w", err)
+	}
+
+	text = stripCodeFences(text)
+
+	var reply map[string]string
+	if err := json.Unmarshal([]byte(text), &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse classification: %w (raw: %s)", err, truncate(text, 200))
+	}
+	return reply, nil
+}
+
+// ClassifyChanges types each file on its own, the way offline commit
+// messages are typed: docs, test, or feat.
+func (o *OfflineClient) ClassifyChanges(groups []grouper.FileGroup) (map[string]string, error) {
+	fileDiffs := grouper.FileDiffs(groups)
+	types := make(map[string]string)
+	for _, g := range groups {
+		for _, f := range g.Files {
+			typ, _ := classify(fileDiffs[f], []string{f})
+			if !typeAllowed(o.commitTypes, typ) {
+				typ = "chore"
+			}
+			types[f] = typ
+		}
+	}
+	return types, nil
+}
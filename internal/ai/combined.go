@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// ErrCombinedUnparsed means the combined refine + review response wasn't the
+// expected JSON; callers should fall back to separate RefineAndCommit and
+// ReviewCode calls.
+var ErrCombinedUnparsed = errors.New("combined refine + review response did not parse")
+
+// RefineAndReview does RefineAndCommit and ReviewCode in one API call,
+// halving round-trips for small flushes (ai.combine_refine_and_review). Any
+// failure, including a response that doesn't parse (ErrCombinedUnparsed),
+// returns an error and no partial result.
+func (c *Client) RefineAndReview(groups []grouper.FileGroup) ([]grouper.FileGroup, *ReviewResult, error) {
+	var sb strings.Builder
+	sb.WriteString("You are a git commit assistant and an expert code reviewer. Analyze the following pre-grouped file changes.\n\n")
+	sb.WriteString("Part A — commits:\n")
+	c.writeRefineInstructions(&sb, "")
+	sb.WriteString("Part B — review. Identify:\n")
+	c.writeReviewChecklist(&sb)
+	sb.WriteString("Use start_line/end_line for ranges and related_locations for issues spanning files.\n")
+	sb.WriteString("If you find NO issues, use an empty findings array.\n\n")
+	sb.WriteString("Respond with ONLY valid JSON in this exact format:\n")
+	sb.WriteString(`{"groups":[{"files":["path/to/file.go"],"reason":"why grouped","commit_message":"feat: description"}],"findings":[` + findingFormat + `]}`)
+	sb.WriteString("\n\nPre-grouped changes:\n\n")
+	writeGroups(&sb, groups)
+
+	text, model, err := c.callClaudeJSON(sb.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("claude API call failed: %w", err)
+	}
+
+	text = stripCodeFences(text)
+
+	var resp struct {
+		Groups   []refinedGroup   `json:"groups"`
+		Findings *[]ReviewFinding `json:"findings"` // nil when missing, so a dropped half is caught
+	}
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v (raw: %s)", ErrCombinedUnparsed, err, truncate(text, 200))
+	}
+	if len(resp.Groups) == 0 || resp.Findings == nil {
+		return nil, nil, fmt.Errorf("%w: missing groups or findings (raw: %s)", ErrCombinedUnparsed, truncate(text, 200))
+	}
+
+	findings := *resp.Findings
+	normalizeFindings(findings)
+
+	return c.buildRefined(groups, resp.Groups, model), &ReviewResult{
+		Findings:    findings,
+		HasBlockers: hasBlockers(findings),
+	}, nil
+}
@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// NewHTTPClient builds the HTTP client for API calls. proxyURL (ai.proxy_url)
+// sends every request through that proxy; when empty the standard
+// HTTPS_PROXY / HTTP_PROXY / NO_PROXY environment variables apply.
+// caCertPath (ai.ca_cert_path) is a PEM bundle trusted in addition to the
+// system roots, e.g. for a proxy that re-signs TLS with a corporate CA.
+func NewHTTPClient(proxyURL, caCertPath string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ai.proxy_url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	if caCertPath != "" {
+		pem, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ai.ca_cert_path: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in ai.ca_cert_path %s", caCertPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
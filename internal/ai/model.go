@@ -3,28 +3,102 @@ package ai
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/firasastwani/gitpulse/internal/grouper"
 )
 
 const anthropicAPI = "https://api.anthropic.com/v1/messages"
 
+// defaultCommitTypes is the standard conventional-commit type set, used when
+// no custom list is configured.
+var defaultCommitTypes = []string{"feat", "fix", "refactor", "perf", "docs", "test", "style", "build", "ci", "chore", "revert"}
+
+// conventionalRe matches a conventional commit subject: type(scope)!: description
+var conventionalRe = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?(!)?:\s*(.*)$`)
+
 // Client handles communication with the Claude API.
 type Client struct {
-	apiKey string
-	model  string
+	apiKey         string
+	model          string
+	fallbackModels []string
+	commitTypes    []string
+	commitLanguage string // "" or English = no language instruction
+	reviewFocus    []string
+	maxGroups      int          // groups per refine/review/classify request; 0 = all in one
+	limiter        *rateLimiter // paces every API request; nil = unlimited
+	httpClient     *http.Client
+
+	temperature *float64 // ai.temperature; nil = API default
+	topP        *float64 // ai.top_p; nil = API default
+}
+
+// NewClient creates a new Claude API client that sends its requests with
+// httpClient (see NewHTTPClient). A nil httpClient uses a default one that
+// honors the standard proxy environment variables.
+func NewClient(apiKey, model string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient, _ = NewHTTPClient("", "") // can't fail without a proxy or CA file
+	}
+	return &Client{
+		apiKey:      apiKey,
+		model:       model,
+		commitTypes: defaultCommitTypes,
+		reviewFocus: defaultReviewFocus,
+		httpClient:  httpClient,
+	}
 }
 
-// NewClient creates a new Claude API client.
-func NewClient(apiKey, model string) *Client {
+// WithModel returns a client with the same key and settings that asks model
+// instead, e.g. for ai.review_model. The two share the rate limit.
+func (c *Client) WithModel(model string) *Client {
 	return &Client{
-		apiKey: apiKey,
-		model:  model,
+		apiKey:         c.apiKey,
+		model:          model,
+		fallbackModels: c.fallbackModels,
+		commitTypes:    c.commitTypes,
+		commitLanguage: c.commitLanguage,
+		reviewFocus:    c.reviewFocus,
+		maxGroups:      c.maxGroups,
+		limiter:        c.limiter,
+		httpClient:     c.httpClient,
+		temperature:    c.temperature,
+		topP:           c.topP,
+	}
+}
+
+// SetCommitTypes overrides the allowed conventional-commit types (e.g. to add
+// "wip" or "hotfix"). An empty list keeps the standard set.
+func (c *Client) SetCommitTypes(types []string) {
+	if len(types) == 0 {
+		c.commitTypes = defaultCommitTypes
+		return
+	}
+	c.commitTypes = types
+}
+
+// SetCommitLanguage asks for commit messages in lang (ai.commit_language),
+// e.g. "es" or "ja". The conventional-commit type and scope stay English so
+// the messages still parse. "" or English adds no instruction.
+func (c *Client) SetCommitLanguage(lang string) {
+	c.commitLanguage = strings.TrimSpace(lang)
+}
+
+// languageInstruction returns the prompt line asking for the configured
+// commit language, or "" for English.
+func (c *Client) languageInstruction() string {
+	switch strings.ToLower(c.commitLanguage) {
+	case "", "en", "english":
+		return ""
 	}
+	return fmt.Sprintf("Write the commit message description and body in this language: %s. "+
+		"Keep the conventional-commit type and scope (e.g. 'feat(auth):') in English.", c.commitLanguage)
 }
 
 // anthropicRequest is the request body for the Anthropic Messages API.
@@ -32,6 +106,9 @@ type anthropicRequest struct {
 	Model     string    `json:"model"`
 	MaxTokens int       `json:"max_tokens"`
 	Messages  []message `json:"messages"`
+
+	Temperature *float64 `json:"temperature,omitempty"` // omitted = API default
+	TopP        *float64 `json:"top_p,omitempty"`
 }
 
 type message struct {
@@ -55,20 +132,146 @@ type apiError struct {
 	Message string `json:"message"`
 }
 
-// callClaude sends a prompt to the Claude API and returns the text response.
-func (c *Client) callClaude(prompt string) (string, error) {
-	return c.callClaudeWithTokens(prompt, 1024)
+// callClaude sends a prompt to the Claude API and returns the text response
+// and the model that wrote it.
+func (c *Client) callClaude(prompt string) (string, string, error) {
+	return c.callClaudeWithTokens(prompt, 1024, false)
 }
 
-// callClaudeWithTokens sends a prompt with a custom max_tokens limit.
-func (c *Client) callClaudeWithTokens(prompt string, maxTokens int) (string, error) {
+// callClaudeJSON is callClaude for prompts whose reply is parsed as JSON,
+// sent with a lower temperature (see maxJSONTemperature).
+func (c *Client) callClaudeJSON(prompt string) (string, string, error) {
+	return c.callClaudeWithTokens(prompt, 1024, true)
+}
+
+// maxJSONTemperature caps the temperature of calls that must return JSON
+// (grouping, review, fixes): less sampling noise, fewer unparseable replies.
+const maxJSONTemperature = 0.2
+
+// maxRetries is how many extra attempts an overloaded model gets before
+// falling back to the next model in the chain.
+const maxRetries = 2
+
+// retryBackoff is the base delay between retries (doubled each attempt).
+var retryBackoff = time.Second
+
+// statusError is a non-200 response from the API.
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// overloaded reports whether err means the model is temporarily unavailable
+// (rate limited, overloaded, or a 5xx), so retrying or falling back may help.
+func overloaded(err error) bool {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	switch se.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, 529:
+		return true
+	}
+	return false
+}
+
+// tooLarge reports whether err means the request was over the model's
+// context window ("prompt is too long") or the API's size limit, so a
+// smaller batch may go through.
+func tooLarge(err error) bool {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	switch se.StatusCode {
+	case http.StatusRequestEntityTooLarge:
+		return true
+	case http.StatusBadRequest:
+		body := strings.ToLower(se.Body)
+		return strings.Contains(body, "prompt is too long") || strings.Contains(body, "too many tokens")
+	}
+	return false
+}
+
+// SetFallbackModels sets the ordered list of models to try when the primary
+// model stays overloaded after retries.
+func (c *Client) SetFallbackModels(models []string) {
+	c.fallbackModels = models
+}
+
+// SetRequestsPerMinute caps how many API requests this client sends per
+// minute (ai.requests_per_minute), retries included; calls over the limit
+// wait their turn. 0 or less removes the cap.
+func (c *Client) SetRequestsPerMinute(n int) {
+	c.limiter = newRateLimiter(n)
+}
+
+// SetSampling sets the temperature and top_p sent with every request
+// (ai.temperature, ai.top_p); nil leaves the API default. JSON calls use at
+// most maxJSONTemperature unless top_p is set, since some models reject the
+// two together.
+func (c *Client) SetSampling(temperature, topP *float64) {
+	c.temperature = temperature
+	c.topP = topP
+}
+
+// sampling returns the temperature and top_p for a request.
+func (c *Client) sampling(jsonReply bool) (temperature, topP *float64) {
+	temperature, topP = c.temperature, c.topP
+	if jsonReply && topP == nil && (temperature == nil || *temperature > maxJSONTemperature) {
+		t := maxJSONTemperature
+		temperature = &t
+	}
+	return temperature, topP
+}
+
+// callClaudeWithTokens sends a prompt with a custom max_tokens limit;
+// jsonReply marks prompts whose reply is parsed as JSON. Overload errors are
+// retried with backoff, then the prompt moves down the fallback model chain
+// before giving up. Returns the reply and the model that answered, per call,
+// since async reviews and flushes share the client.
+func (c *Client) callClaudeWithTokens(prompt string, maxTokens int, jsonReply bool) (string, string, error) {
+	models := append([]string{c.model}, c.fallbackModels...)
+
+	var lastErr error
+	for _, model := range models {
+		for attempt := 0; attempt <= maxRetries; attempt++ {
+			if attempt > 0 {
+				time.Sleep(retryBackoff << (attempt - 1))
+			}
+
+			text, err := c.callModel(model, prompt, maxTokens, jsonReply)
+			if err == nil {
+				return text, model, nil
+			}
+			if !overloaded(err) {
+				return "", "", err
+			}
+			lastErr = fmt.Errorf("%s: %w", model, err)
+		}
+	}
+
+	return "", "", lastErr
+}
+
+// callModel sends a single request to the given model, once the rate
+// limiter allows it.
+func (c *Client) callModel(model, prompt string, maxTokens int, jsonReply bool) (string, error) {
+	c.limiter.wait()
+
 	reqBody := anthropicRequest{
-		Model:     c.model,
+		Model:     model,
 		MaxTokens: maxTokens,
 		Messages: []message{
 			{Role: "user", Content: prompt},
 		},
 	}
+	reqBody.Temperature, reqBody.TopP = c.sampling(jsonReply)
 
 	body, err := json.Marshal(reqBody)
 	if err != nil {
@@ -84,7 +287,7 @@ func (c *Client) callClaudeWithTokens(prompt string, maxTokens int) (string, err
 	req.Header.Set("x-api-key", c.apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -96,7 +299,7 @@ func (c *Client) callClaudeWithTokens(prompt string, maxTokens int) (string, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(respBody))
+		return "", &statusError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var apiResp anthropicResponse
@@ -125,78 +328,116 @@ func (c *Client) callClaudeWithTokens(prompt string, maxTokens int) (string, err
 //
 // If the API call fails, returns the original groups unchanged (graceful fallback).
 func (c *Client) RefineAndCommit(groups []grouper.FileGroup) ([]grouper.FileGroup, error) {
+	return c.RefineWithHint(groups, "")
+}
+
+// RefineWithHint is RefineAndCommit with an extra grouping instruction, e.g.
+// to split more aggressively after the user rejected a grouping. Groups are
+// sent in batches (see SetMaxGroupsPerRequest), so files only move between
+// groups of the same batch.
+func (c *Client) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	var refined []grouper.FileGroup
+	for _, batch := range c.batches(groups) {
+		r, err := splitOnTooLarge(batch, func(b []grouper.FileGroup) ([]grouper.FileGroup, error) {
+			r, err := c.refineBatch(b, hint)
+			if len(b) == 1 && tooLarge(err) {
+				// Too large even on its own: it keeps the generic message
+				// while the rest still get AI ones
+				b[0].CommitMessage = "chore: auto-commit changes"
+				return b, nil
+			}
+			return r, err
+		})
+		if err != nil {
+			return groups, err
+		}
+		refined = append(refined, r...)
+	}
+	return refined, nil
+}
+
+// refineBatch is one refine request for groups.
+func (c *Client) refineBatch(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
 	var sb strings.Builder
 	sb.WriteString("You are a git commit assistant. Analyze the following pre-grouped file changes and:\n")
-	sb.WriteString("1. Refine the groupings if files should be moved between groups\n")
-	sb.WriteString("2. Generate a specific, descriptive conventional commit message for each group.\n")
-	sb.WriteString("   - The message MUST describe WHAT changed, not just that something changed.\n")
-	sb.WriteString("   - BAD:  'refactor(ui): update logger implementation'\n")
-	sb.WriteString("   - GOOD: 'feat(ui): add interactive code review prompts with severity-colored findings display'\n")
-	sb.WriteString("   - BAD:  'chore: auto-commit changes'\n")
-	sb.WriteString("   - GOOD: 'feat(config): add CodeReview toggle to AIConfig for optional pre-push review'\n")
-	sb.WriteString("   - Include the specific behavior or feature, not generic verbs like 'update' or 'modify'\n\n")
+	c.writeRefineInstructions(&sb, hint)
 	sb.WriteString("Respond with ONLY valid JSON in this exact format:\n")
 	sb.WriteString(`[{"files":["path/to/file.go"],"reason":"why grouped","commit_message":"feat: description"}]`)
 	sb.WriteString("\n\nPre-grouped changes:\n\n")
+	writeGroups(&sb, groups)
 
-	for i, g := range groups {
-		sb.WriteString(fmt.Sprintf("Group %d (%s):\n", i+1, g.Reason))
-		sb.WriteString(fmt.Sprintf("  Files: %s\n", strings.Join(g.Files, ", ")))
-		if g.Diffs != "" {
-			sb.WriteString(fmt.Sprintf("  Diff:\n%s\n", g.Diffs))
-		}
-		sb.WriteString("\n")
-	}
-
-	text, err := c.callClaude(sb.String())
+	text, model, err := c.callClaudeJSON(sb.String())
 	if err != nil {
 		return groups, fmt.Errorf("claude API call failed: %w", err)
 	}
 
 	text = stripCodeFences(text)
 
-	var refined []struct {
-		Files         []string `json:"files"`
-		Reason        string   `json:"reason"`
-		CommitMessage string   `json:"commit_message"`
-	}
-
+	var refined []refinedGroup
 	if err := json.Unmarshal([]byte(text), &refined); err != nil {
 		// fallback: keep original groups, generate commit messages individually
 		for i := range groups {
-			msg, msgErr := c.GenerateCommitMessage(groups[i].Diffs, groups[i].Files)
+			msg, msgModel, msgErr := c.GenerateCommitMessage(groups[i].Diffs, groups[i].Files)
 			if msgErr == nil {
 				groups[i].CommitMessage = msg
+				groups[i].Model = msgModel
 			}
 		}
 		return groups, nil
 	}
 
-	// Build file -> diff lookup from original groups so diffs survive refinement
-	// Parse the original diffs to extract per-file diff sections
-	fileDiffs := make(map[string]string)
-	for _, g := range groups {
-		if len(g.Files) == 1 {
-			// Single file group: entire diff belongs to that file
-			fileDiffs[g.Files[0]] = g.Diffs
-		} else {
-			// Multiple files: split diff by file headers
-			diffSections := strings.Split(g.Diffs, "diff --git")
-			for _, section := range diffSections {
-				if section == "" {
-					continue
-				}
-				section = "diff --git" + section
-				// Extract filename from diff header
-				for _, f := range g.Files {
-					if strings.Contains(section, " a/"+f+" ") || strings.Contains(section, " b/"+f+" ") {
-						fileDiffs[f] = section
-						break
-					}
-				}
-			}
+	return c.buildRefined(groups, refined, model), nil
+}
+
+// writeRefineInstructions writes the regroup + commit message instructions
+// shared by RefineWithHint and RefineAndReview.
+func (c *Client) writeRefineInstructions(sb *strings.Builder, hint string) {
+	sb.WriteString("1. Refine the groupings if files should be moved between groups\n")
+	sb.WriteString("2. Generate a specific, descriptive conventional commit message for each group.\n")
+	sb.WriteString("   - The message MUST describe WHAT changed, not just that something changed.\n")
+	sb.WriteString("   - BAD:  'refactor(ui): update logger implementation'\n")
+	sb.WriteString("   - GOOD: 'feat(ui): add interactive code review prompts with severity-colored findings display'\n")
+	sb.WriteString("   - BAD:  'chore: auto-commit changes'\n")
+	sb.WriteString("   - GOOD: 'feat(config): add CodeReview toggle to AIConfig for optional pre-push review'\n")
+	sb.WriteString("   - Include the specific behavior or feature, not generic verbs like 'update' or 'modify'\n")
+	sb.WriteString("   - Use a group's suggested scope (its Go package or directory) as the scope, e.g. 'feat(engine): ...', unless the files you put together call for another\n")
+	sb.WriteString(fmt.Sprintf("   - The commit type MUST be one of: %s\n", strings.Join(c.commitTypes, ", ")))
+	if lang := c.languageInstruction(); lang != "" {
+		sb.WriteString("   - " + lang + "\n")
+	}
+	sb.WriteString("\n")
+	if hint != "" {
+		sb.WriteString("Grouping instruction: " + hint + "\n\n")
+	}
+}
+
+// writeGroups writes each pre-group's reason, files, suggested scope and diff.
+func writeGroups(sb *strings.Builder, groups []grouper.FileGroup) {
+	for i, g := range groups {
+		sb.WriteString(fmt.Sprintf("Group %d (%s):\n", i+1, g.Reason))
+		sb.WriteString(fmt.Sprintf("  Files: %s\n", strings.Join(g.Files, ", ")))
+		if g.Scope != "" {
+			sb.WriteString(fmt.Sprintf("  Suggested scope: %s\n", g.Scope))
 		}
+		if g.Diffs != "" {
+			sb.WriteString(fmt.Sprintf("  Diff:\n%s\n", g.Diffs))
+		}
+		sb.WriteString("\n")
 	}
+}
+
+// refinedGroup is one group as the model returns it.
+type refinedGroup struct {
+	Files         []string `json:"files"`
+	Reason        string   `json:"reason"`
+	CommitMessage string   `json:"commit_message"`
+}
+
+// buildRefined turns the groups model returned into FileGroups, rebuilding
+// each group's diff from the original per-file diffs.
+func (c *Client) buildRefined(groups []grouper.FileGroup, refined []refinedGroup, model string) []grouper.FileGroup {
+	// Build file -> diff lookup from original groups so diffs survive refinement
+	fileDiffs := grouper.FileDiffs(groups)
 
 	refinedGroups := make([]grouper.FileGroup, len(refined))
 	for i, r := range refined {
@@ -210,12 +451,13 @@ func (c *Client) RefineAndCommit(groups []grouper.FileGroup) ([]grouper.FileGrou
 		refinedGroups[i] = grouper.FileGroup{
 			Files:         r.Files,
 			Reason:        r.Reason,
-			CommitMessage: r.CommitMessage,
+			CommitMessage: c.normalizeCommitMessage(r.CommitMessage),
 			Diffs:         combinedDiffs.String(),
+			Model:         model,
 		}
 	}
 
-	return refinedGroups, nil
+	return refinedGroups
 }
 
 // stripCodeFences removes markdown code fences that Claude sometimes wraps around JSON.
@@ -230,31 +472,82 @@ func stripCodeFences(s string) string {
 	return strings.TrimSpace(s)
 }
 
-// GenerateCommitMessage generates a commit message for a single group's diff.
-// Used as fallback when RefineAndCommit fails for individual groups.
-func (c *Client) GenerateCommitMessage(diff string, files []string) (string, error) {
+// GenerateCommitMessage generates a commit message for a single group's diff,
+// and returns it with the model that wrote it. Used as fallback when
+// RefineAndCommit fails for individual groups.
+func (c *Client) GenerateCommitMessage(diff string, files []string) (string, string, error) {
 	prompt := fmt.Sprintf(
 		"Generate a single git commit message using conventional commits format "+
-			"(feat/fix/refactor/chore/docs/test).\n\n"+
+			"(%s).\n\n"+
 			"The message MUST be specific about WHAT changed — describe the actual behavior or feature added.\n"+
 			"BAD:  'refactor(engine): update engine implementation'\n"+
 			"GOOD: 'feat(engine): add AI code review gate with interactive fix/continue prompt before push'\n"+
 			"Avoid generic verbs like 'update', 'modify', 'change' — say what was actually done.\n\n"+
 			"Files changed: %s\n\nDiff:\n%s\n\n"+
 			"Respond with ONLY the commit message, nothing else.",
-		strings.Join(files, ", "), diff,
+		strings.Join(c.commitTypes, "/"), strings.Join(files, ", "), diff,
 	)
+	if lang := c.languageInstruction(); lang != "" {
+		prompt += "\n" + lang
+	}
 
-	msg, err := c.callClaude(prompt)
+	msg, model, err := c.callClaude(prompt)
 	if err != nil {
-		return "chore: auto-commit changes", fmt.Errorf("claude API call failed: %w", err)
+		return "chore: auto-commit changes", "", fmt.Errorf("claude API call failed: %w", err)
+	}
+
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return "chore: auto-commit changes", "", nil
 	}
 
+	return c.normalizeCommitMessage(msg), model, nil
+}
+
+// normalizeCommitMessage makes sure the subject line uses an allowed
+// conventional-commit type. A type that isn't allowed is rewritten to
+// "chore"; any other subject, including prose like "Update: handle nil
+// config", is kept whole behind a "chore: " prefix. The body is left
+// untouched.
+func (c *Client) normalizeCommitMessage(msg string) string {
 	msg = strings.TrimSpace(msg)
 	if msg == "" {
-		return "chore: auto-commit changes", nil
+		return msg
+	}
+
+	subject, body, hasBody := strings.Cut(msg, "\n")
+	m := conventionalRe.FindStringSubmatch(subject)
+	if m == nil || !looksConventional(c.commitTypes, m) {
+		subject = "chore: " + subject
+	} else {
+		typ := strings.ToLower(m[1])
+		if !typeAllowed(c.commitTypes, typ) {
+			typ = "chore"
+		}
+		subject = typ + m[2] + m[3] + ": " + m[4]
 	}
 
-	return msg, nil
+	if hasBody {
+		return subject + "\n" + body
+	}
+	return subject
+}
+
+// looksConventional reports whether a conventionalRe match is meant as a
+// conventional-commit type rather than a capitalized word ending in a colon:
+// the type is allowed, lowercase, or followed by a scope or "!".
+func looksConventional(types []string, m []string) bool {
+	return typeAllowed(types, m[1]) || m[1] == strings.ToLower(m[1]) || m[2] != "" || m[3] != ""
+}
+
+// typeAllowed reports whether typ is in types, the configured commit type
+// list. Shared by the Claude and offline clients.
+func typeAllowed(types []string, typ string) bool {
+	for _, t := range types {
+		if strings.EqualFold(t, typ) {
+			return true
+		}
+	}
+	return false
 }
 
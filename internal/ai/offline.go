@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// ProviderNone is the ai.provider value that disables all model calls.
+const ProviderNone = "none"
+
+// ErrOffline is returned for operations that need a model (AI fixes).
+var ErrOffline = errors.New("AI is disabled (ai.provider: none)")
+
+// OfflineClient builds commit messages deterministically from the heuristic
+// groups and file names, without any API call. Used with --no-ai or
+// ai.provider: none (CI, no API key, developing GitPulse itself).
+type OfflineClient struct {
+	commitTypes []string
+}
+
+// NewOfflineClient creates an OfflineClient with the standard commit types.
+func NewOfflineClient() *OfflineClient {
+	return &OfflineClient{commitTypes: defaultCommitTypes}
+}
+
+// SetCommitTypes overrides the allowed conventional-commit types. Generated
+// types that aren't allowed fall back to "chore".
+func (o *OfflineClient) SetCommitTypes(types []string) {
+	if len(types) == 0 {
+		o.commitTypes = defaultCommitTypes
+		return
+	}
+	o.commitTypes = types
+}
+
+// RefineAndCommit keeps the heuristic groups as-is and fills in a
+// deterministic commit message for each.
+func (o *OfflineClient) RefineAndCommit(groups []grouper.FileGroup) ([]grouper.FileGroup, error) {
+	for i := range groups {
+		groups[i].CommitMessage = o.message(groups[i].Diffs, groups[i].Files, groups[i].Scope)
+	}
+	return groups, nil
+}
+
+// RefineWithHint ignores the hint — there's no model to re-run offline.
+func (o *OfflineClient) RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error) {
+	return o.RefineAndCommit(groups)
+}
+
+// GenerateCommitMessage returns a deterministic message for one group, and
+// no model.
+func (o *OfflineClient) GenerateCommitMessage(diff string, files []string) (string, string, error) {
+	return o.message(diff, files, ""), "", nil
+}
+
+// ReviewCode never finds anything — there is no reviewer offline.
+func (o *OfflineClient) ReviewCode(groups []grouper.FileGroup) (*ReviewResult, error) {
+	return &ReviewResult{}, nil
+}
+
+// RefineAndReview is RefineAndCommit plus an empty review.
+func (o *OfflineClient) RefineAndReview(groups []grouper.FileGroup) ([]grouper.FileGroup, *ReviewResult, error) {
+	groups, _ = o.RefineAndCommit(groups)
+	return groups, &ReviewResult{}, nil
+}
+
+// GenerateFix always fails with ErrOffline.
+func (o *OfflineClient) GenerateFix(filePath string, finding ReviewFinding, primaryContent string, relatedContents map[string]string) (string, error) {
+	return "", ErrOffline
+}
+
+// message builds e.g. "feat(auth): update auth.go, token.go". scope is the
+// group's suggested scope; "" uses the files' common directory.
+func (o *OfflineClient) message(diff string, files []string, scope string) string {
+	typ, verb := classify(diff, files)
+	if !typeAllowed(o.commitTypes, typ) {
+		typ = "chore"
+	}
+
+	subject := typ
+	if scope == "" {
+		scope = grouper.CommonDir(files)
+	}
+	if scope != "" {
+		subject += "(" + scope + ")"
+	}
+	return fmt.Sprintf("%s: %s %s", subject, verb, describeFiles(files))
+}
+
+// classify picks a commit type and verb from file names and the diff:
+// docs-only and test-only groups get docs/test, everything else feat; the
+// verb is "add"/"remove" when every file was created/deleted.
+func classify(diff string, files []string) (typ, verb string) {
+	docs, tests := true, true
+	for _, f := range files {
+		switch strings.ToLower(filepath.Ext(f)) {
+		case ".md", ".rst", ".txt":
+		default:
+			docs = false
+		}
+		base := filepath.Base(f)
+		if !strings.HasSuffix(strings.TrimSuffix(base, filepath.Ext(base)), "_test") &&
+			!strings.Contains(base, ".test.") && !strings.Contains(base, ".spec.") {
+			tests = false
+		}
+	}
+
+	switch {
+	case len(files) == 0:
+		typ = "chore"
+	case docs:
+		typ = "docs"
+	case tests:
+		typ = "test"
+	default:
+		typ = "feat"
+	}
+
+	sections := strings.Count(diff, "diff --git")
+	switch {
+	case sections > 0 && sections == len(files) && strings.Count(diff, "--- /dev/null") == sections:
+		verb = "add"
+	case sections > 0 && sections == len(files) && strings.Count(diff, "+++ /dev/null") == sections:
+		verb = "remove"
+	default:
+		verb = "update"
+	}
+	return typ, verb
+}
+
+// describeFiles lists up to three base names, e.g. "a.go, b.go and 2 more".
+func describeFiles(files []string) string {
+	const maxNamed = 3
+	names := make([]string, 0, maxNamed)
+	for i, f := range files {
+		if i == maxNamed {
+			break
+		}
+		names = append(names, filepath.Base(f))
+	}
+	s := strings.Join(names, ", ")
+	if extra := len(files) - len(names); extra > 0 {
+		s += fmt.Sprintf(" and %d more", extra)
+	}
+	if s == "" {
+		s = "files"
+	}
+	return s
+}
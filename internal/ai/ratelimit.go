@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"sync"
+	"time"
+)
+
+// rateBurst is how many requests may go out back to back before the
+// limiter starts spacing them.
+const rateBurst = 5
+
+// rateLimiter is a token bucket shared by every call a Client makes: it
+// holds up to rateBurst tokens and refills one every interval. A nil
+// *rateLimiter never waits.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// newRateLimiter allows perMinute requests per minute on average, or
+// returns nil (no limit) when perMinute <= 0.
+func newRateLimiter(perMinute int) *rateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	burst := float64(min(rateBurst, perMinute))
+	return &rateLimiter{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    burst,
+		tokens:   burst,
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until a request may be sent. Callers that arrive while the
+// bucket is empty each reserve the next free slot, so a burst goes out one
+// interval apart in arrival order.
+func (l *rateLimiter) wait() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens * float64(l.interval))
+	}
+	l.mu.Unlock()
+
+	time.Sleep(delay)
+}
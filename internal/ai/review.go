@@ -15,6 +15,30 @@ const (
 	SeverityInfo    = "info"    // suggestions/ Does not block push
 )
 
+// reviewFocusChecklist maps review_focus keys to the checklist line used in the prompt.
+var reviewFocusChecklist = map[string]string{
+	"bugs":        "Bugs and logic errors",
+	"security":    "Security vulnerabilities",
+	"nil_safety":  "Nil pointer / index out of bounds risks",
+	"concurrency": "Race conditions or concurrency issues",
+	"mistakes":    "Obvious mistakes (typos in logic, wrong variable, missing error handling)",
+	"performance": "Performance problems (needless allocations, N+1 calls, quadratic loops on large inputs)",
+}
+
+// defaultReviewFocus is the full checklist used when no focus is configured.
+var defaultReviewFocus = []string{"bugs", "security", "nil_safety", "concurrency", "mistakes"}
+
+// SetReviewFocus limits the review checklist to the given areas (e.g.
+// "security", "performance"). Unknown areas are passed to the model verbatim.
+// An empty list restores the default full checklist.
+func (c *Client) SetReviewFocus(focus []string) {
+	if len(focus) == 0 {
+		c.reviewFocus = defaultReviewFocus
+		return
+	}
+	c.reviewFocus = focus
+}
+
 // Location represents a specific code location (a line range in a file).
 type Location struct {
 	File      string `json:"file"`
@@ -44,24 +68,41 @@ type ReviewResult struct {
 // Returns a ReviewResult with the findings. If no issues are found, Findings
 // will be empty and HasBlockers will be false, allowing the push to continue
 // If the API call fails, it returns an error and the push continues
+// Groups are reviewed in batches (see SetMaxGroupsPerRequest) and the
+// findings merged.
 func (c *Client) ReviewCode(groups []grouper.FileGroup) (*ReviewResult, error) {
+	result := &ReviewResult{}
+	for _, batch := range c.batches(groups) {
+		var findings []ReviewFinding
+		_, err := splitOnTooLarge(batch, func(b []grouper.FileGroup) ([]grouper.FileGroup, error) {
+			r, err := c.reviewBatch(b)
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, r.Findings...)
+			return b, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = result.With(findings)
+	}
+	return result, nil
+}
+
+// reviewBatch is one review request for groups.
+func (c *Client) reviewBatch(groups []grouper.FileGroup) (*ReviewResult, error) {
 
 	var sb strings.Builder
 
 	// prompting
 	sb.WriteString("You are an expert code reviewer. Analyze the following file diffs and identify:\n")
-	sb.WriteString("1. Bugs and logic errors\n")
-	sb.WriteString("2. Security vulnerabilities\n")
-	sb.WriteString("3. Nil pointer / index out of bounds risks\n")
-	sb.WriteString("4. Race conditions or concurrency issues\n")
-	sb.WriteString("5. Obvious mistakes (typos in logic, wrong variable, missing error handling)\n\n")
-	sb.WriteString("Do NOT flag style issues, naming preferences, or minor nits.\n")
-	sb.WriteString("Only report genuine problems that could cause bugs or security issues.\n\n")
+	c.writeReviewChecklist(&sb)
 	sb.WriteString("If you find NO issues, respond with an empty JSON array: []\n\n")
 	sb.WriteString("For issues spanning multiple lines, use start_line and end_line to indicate the range.\n")
 	sb.WriteString("For issues involving multiple files, include related_locations to reference the connected code.\n\n")
 	sb.WriteString("Respond with ONLY valid JSON in this exact format:\n")
-	sb.WriteString(`[{"file":"path/to/file.go","start_line":42,"end_line":50,"severity":"error|warning|info","description":"what is wrong","suggestion":"how to fix it","related_locations":[{"file":"path/to/other.go","start_line":10,"end_line":12}]}]`)
+	sb.WriteString("[" + findingFormat + "]")
 	sb.WriteString("\n\nFile diffs to review:\n\n")
 
 	for i, g := range groups {
@@ -73,7 +114,7 @@ func (c *Client) ReviewCode(groups []grouper.FileGroup) (*ReviewResult, error) {
 		sb.WriteString("\n")
 	}
 
-	text, err := c.callClaude(sb.String())
+	text, _, err := c.callClaudeJSON(sb.String())
 
 	if err != nil {
 		return nil, fmt.Errorf("code review API call failed: %w", err)
@@ -88,6 +129,36 @@ func (c *Client) ReviewCode(groups []grouper.FileGroup) (*ReviewResult, error) {
 		return nil, fmt.Errorf("failed to parse review response: %w (raw: %s)", err, truncate(text, 200))
 	}
 
+	normalizeFindings(findings)
+
+	result := &ReviewResult{
+		Findings:    findings,
+		HasBlockers: hasBlockers(findings),
+	}
+
+	return result, nil
+}
+
+// findingFormat is the JSON shape of one review finding, for prompts.
+const findingFormat = `{"file":"path/to/file.go","start_line":42,"end_line":50,"severity":"error|warning|info","description":"what is wrong","suggestion":"how to fix it","related_locations":[{"file":"path/to/other.go","start_line":10,"end_line":12}]}`
+
+// writeReviewChecklist writes the review_focus checklist and the rules for
+// what (not) to report, shared by ReviewCode and RefineAndReview.
+func (c *Client) writeReviewChecklist(sb *strings.Builder) {
+	for i, focus := range c.reviewFocus {
+		item, ok := reviewFocusChecklist[strings.ToLower(focus)]
+		if !ok {
+			item = "Issues related to " + focus
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, item))
+	}
+	sb.WriteString("\nOnly report issues in the areas listed above.\n")
+	sb.WriteString("Do NOT flag style issues, naming preferences, or minor nits.\n")
+	sb.WriteString("Only report genuine problems, not speculative ones.\n\n")
+}
+
+// normalizeFindings fixes up severities and line ranges the model returned.
+func normalizeFindings(findings []ReviewFinding) {
 	// Validate and normalize severity values
 	for i := range findings {
 		switch findings[i].Severity {
@@ -103,13 +174,31 @@ func (c *Client) ReviewCode(groups []grouper.FileGroup) (*ReviewResult, error) {
 			findings[i].EndLine = findings[i].StartLine
 		}
 	}
+}
 
-	result := &ReviewResult{
-		Findings:    findings,
-		HasBlockers: hasBlockers(findings),
+// Without returns a copy of r with every finding for which drop returns true
+// removed, recomputing HasBlockers from what's left.
+func (r *ReviewResult) Without(drop func(ReviewFinding) bool) *ReviewResult {
+	kept := make([]ReviewFinding, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		if !drop(f) {
+			kept = append(kept, f)
+		}
 	}
+	return &ReviewResult{
+		Findings:    kept,
+		HasBlockers: hasBlockers(kept),
+	}
+}
 
-	return result, nil
+// With returns a copy of r with findings appended, recomputing HasBlockers.
+func (r *ReviewResult) With(findings []ReviewFinding) *ReviewResult {
+	all := make([]ReviewFinding, 0, len(r.Findings)+len(findings))
+	all = append(append(all, r.Findings...), findings...)
+	return &ReviewResult{
+		Findings:    all,
+		HasBlockers: hasBlockers(all),
+	}
 }
 
 // fixPatch is the JSON response format for targeted code fixes.
@@ -156,7 +245,7 @@ func (c *Client) GenerateFix(filePath string, finding ReviewFinding, primaryCont
 	sb.WriteString(`{"old_code":"exact lines to replace","new_code":"corrected lines"}`)
 	sb.WriteString("\n")
 
-	text, err := c.callClaudeWithTokens(sb.String(), 2048)
+	text, _, err := c.callClaudeWithTokens(sb.String(), 2048, true)
 	if err != nil {
 		return "", fmt.Errorf("fix generation failed for %s: %w", filePath, err)
 	}
@@ -173,14 +262,35 @@ func (c *Client) GenerateFix(filePath string, finding ReviewFinding, primaryCont
 	}
 
 	// Apply the patch via string replacement
-	if !strings.Contains(primaryContent, patch.OldCode) {
+	fixed, ok := ApplyPatch(primaryContent, patch.OldCode, patch.NewCode)
+	if !ok {
 		return "", fmt.Errorf("old_code not found in %s — patch cannot be applied", filePath)
 	}
-
-	fixed := strings.Replace(primaryContent, patch.OldCode, patch.NewCode, 1)
 	return fixed, nil
 }
 
+// ApplyPatch replaces the first occurrence of oldCode in content with newCode.
+// Line endings are matched loosely: if oldCode isn't found verbatim, it is
+// retried with its line endings converted to CRLF and to LF, and newCode is
+// written with whichever style matched, so the file keeps its original EOLs
+// (the model usually answers with LF even for CRLF files).
+// Returns false if oldCode can't be found either way.
+func ApplyPatch(content, oldCode, newCode string) (string, bool) {
+	if strings.Contains(content, oldCode) {
+		return strings.Replace(content, oldCode, newCode, 1), true
+	}
+
+	oldLF := strings.ReplaceAll(oldCode, "\r\n", "\n")
+	newLF := strings.ReplaceAll(newCode, "\r\n", "\n")
+	for _, eol := range []string{"\r\n", "\n"} {
+		old := strings.ReplaceAll(oldLF, "\n", eol)
+		if strings.Contains(content, old) {
+			return strings.Replace(content, old, strings.ReplaceAll(newLF, "\n", eol), 1), true
+		}
+	}
+	return content, false
+}
+
 // hasBlockers returns true if any finding has severity "error" or "warning".
 func hasBlockers(findings []ReviewFinding) bool {
 	for _, f := range findings {
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 
@@ -12,19 +14,183 @@ import (
 type Config struct {
 	WatchPath       string   `yaml:"watch_path"`
 	DebounceSeconds int      `yaml:"debounce_seconds"` // safety timer — auto-flushes if user forgets to `gitpulse push`
-	AutoPush        bool     `yaml:"auto_push"`
+	PushMode        string   `yaml:"push_mode"`        // "auto", "manual" (only `gitpulse push` pushes) or "never" (commits stay local)
 	Remote          string   `yaml:"remote"`
-	Branch          string   `yaml:"branch"`
+	Remotes         []string `yaml:"remotes"`
+	Branch          string   `yaml:"branch"` // "auto" (or empty) pushes the currently checked-out branch
 	AI              AIConfig `yaml:"ai"`
 	IgnorePatterns  []string `yaml:"ignore_patterns"`
+
+	CoalesceAtomicSaves bool `yaml:"coalesce_atomic_saves"` // report an editor's write-temp-then-rename save as one Modified change, dropping the temp files (default: true)
+
+	CommitReviewFooter bool     `yaml:"commit_review_footer"` // append a GitPulse-Review footer to commit messages when a review ran
+	CommitTypes        []string `yaml:"commit_types"`         // allowed conventional-commit types; others are rewritten to "chore"
+
+	AttributionTrailer bool `yaml:"attribution_trailer"` // append a "Generated-by: GitPulse <version> (model: <model>)" trailer to every GitPulse commit (default: true)
+
+	CommitSubjectMaxLength int `yaml:"commit_subject_max_length"` // longer subjects have their overflow moved into the body (0 = off)
+	CommitBodyWrap         int `yaml:"commit_body_wrap"`          // wrap that overflow at this many columns (0 = don't wrap)
+
+	IncludeDiffStatInBody bool `yaml:"include_diffstat_in_body"` // append a `git diff --stat`-style summary to the commit body
+
+	AutoGitkeep bool `yaml:"auto_gitkeep"` // add a .gitkeep to new empty directories so they're committed (default: skip them)
+
+	FallbackEncoding string `yaml:"fallback_encoding"` // how files that aren't UTF-8 (or UTF-16 with a BOM) are read for AI diffs and fixes: "latin1" (default) or "none" (skip AI processing for them)
+
+	PreserveManualStaging bool `yaml:"preserve_manual_staging"` // commit files staged by hand as their own commit instead of leaving them staged
+
+	GroupingRules     []GroupingRule    `yaml:"grouping_rules"`     // file type clusters for files that would otherwise be singletons
+	GroupingOverrides GroupingOverrides `yaml:"grouping_overrides"` // force files into their own commit or into the same commit
+	ConfirmGrouping   bool              `yaml:"confirm_grouping"`   // interactive: accept the AI grouping, revert to heuristic, or re-run it splitting more
+
+	GroupingStrategy string `yaml:"grouping_strategy"` // "directory" (default: heuristic groups refined by the AI), "type" (one commit per change type the AI assigns, e.g. all fixes together) or "hybrid" (one per type within each directory group)
+
+	SkipAIForWhitespaceOnly bool `yaml:"skip_ai_for_whitespace_only"` // commit groups whose diff only changes whitespace as "style: format code" without an AI call (default: true)
+
+	RejectDegenerateGrouping bool `yaml:"reject_degenerate_grouping"` // when the AI lumps unrelated files into one generic commit, ask it again, then fall back to the heuristic groups
+
+	ImportOutsideCommits bool `yaml:"import_outside_commits"` // add commits made without GitPulse (e.g. while it wasn't running) to the history as ai_generated: false
+
+	GroupRootDepth int `yaml:"group_root_depth"` // group by the first N directories (e.g. 2 = services/api) instead of the parent dir (0 = parent dir)
+
+	GeneratedFileRules []GeneratedFileRule `yaml:"generated_file_rules"` // always commit generated files with the source they come from (e.g. *.proto -> *.pb.go)
+
+	MaxCommitsPerHour int `yaml:"max_commits_per_hour"` // hold flushes once this many commits were made in the last hour; they coalesce into the next allowed one (0 = no cap)
+
+	AmendWindowSeconds int `yaml:"amend_window_seconds"` // fold changes into the previous unpushed GitPulse commit if it's this recent (0 = off)
+
+	AllowForcePush bool `yaml:"allow_force_push"` // let an amend rewrite an already-pushed commit and force-push it, after a warning and an interactive confirmation (default false)
+
+	DiffConcurrency int `yaml:"diff_concurrency"` // max parallel per-file diff fetches during a flush
+
+	LargeCommitLines int `yaml:"large_commit_lines"` // warn (and offer to split) when a group changes more lines than this (0 = off)
+
+	SplitByTimeGapSeconds int `yaml:"split_by_time_gap_seconds"` // flush edits separated by a quiet period this long as separate batches (0 = off)
+
+	DismissDays int `yaml:"dismiss_days"` // how long a dismissed review finding stays dismissed (0 = forever)
+
+	ReviewMinLines int `yaml:"review_min_lines"` // skip the AI review when fewer lines than this changed in total (0 = always review)
+
+	AuthorDate string `yaml:"author_date"` // "first_change" (author date = earliest edit in the group) or "now"; committer date is always now
+
+	OptInMarker string `yaml:"opt_in_marker"` // when set, only auto-commit files whose content contains this string (e.g. "// gitpulse:track")
+
+	EnvFile string `yaml:"env_file"` // explicit .env path (relative to the project dir); takes precedence over discovered .env files
+
+	RequirePersistentHistory bool `yaml:"require_persistent_history"` // refuse to start if .gitpulse/history.json can't be written, instead of keeping history in memory
+
+	FlushOnBranchSwitch bool `yaml:"flush_on_branch_switch"` // when HEAD moves to another branch, commit the pending changes to the branch they were made on
+
+	SyncExistingChangesOnStart bool `yaml:"sync_existing_changes_on_start"` // after startup_grace_seconds, buffer the files git status already shows as changed when GitPulse starts
+	StartupGraceSeconds        int  `yaml:"startup_grace_seconds"`          // wait this long after startup before syncing existing changes (default 5)
+
+	CommitImmediatelyPatterns []string `yaml:"commit_immediately_patterns"` // files committed on every save, on their own, instead of waiting for a flush (globs, e.g. TODO.md)
+
+	LargeBinaryPromptMB int `yaml:"large_binary_prompt_mb"` // ask before committing a new binary file at least this big; non-interactive runs skip it (0 = off)
+
+	Notify NotifyConfig `yaml:"notify"` // where to alert about findings from ai.review_mode: async
+
+	LegacyAutoPush *bool `yaml:"auto_push,omitempty"` // deprecated: auto_push: false is read as push_mode: never
+}
+
+// push_mode values.
+const (
+	PushModeAuto   = "auto"   // push after every flush
+	PushModeManual = "manual" // commit on every flush, push only on `gitpulse push`
+	PushModeNever  = "never"  // commit and record, never push or check the remote
+)
+
+// fallback_encoding values.
+const (
+	EncodingLatin1 = "latin1" // read non-UTF-8 files as ISO-8859-1 and write fixes back the same way
+	EncodingNone   = "none"   // skip AI processing for files that aren't UTF-8 or UTF-16 with a BOM
+)
+
+// ai.review_mode values.
+const (
+	ReviewModeBlocking = "blocking" // review before committing; blockers hold the commit (interactive)
+	ReviewModeAsync    = "async"    // commit and push right away, review in the background and notify
+)
+
+// ai.on_missing_api_key values.
+const (
+	MissingAPIKeyWarn      = "warn"       // warn at startup and on every flush, commit with heuristic messages
+	MissingAPIKeyDisableAI = "disable_ai" // run as with ai.provider: none
+	MissingAPIKeyFail      = "fail"       // refuse to start
+)
+
+// grouping_strategy values.
+const (
+	GroupingStrategyDirectory = "directory" // heuristic groups (directory, name affinity, file type) refined by the AI
+	GroupingStrategyType      = "type"      // one commit per conventional-commit type the AI assigns each file's change
+	GroupingStrategyHybrid    = "hybrid"    // one commit per type within each heuristic group
+)
+
+// author_date values.
+const (
+	AuthorDateFirstChange = "first_change"
+	AuthorDateNow         = "now"
+)
+
+// GroupingRule clusters files matching any of Patterns (e.g. "*.md") into one group named Name.
+type GroupingRule struct {
+	Name     string   `yaml:"name"`
+	Patterns []string `yaml:"patterns"`
+}
+
+// GroupingOverrides are applied after heuristic grouping and again after AI
+// refinement. A file matching both lists is kept separate.
+type GroupingOverrides struct {
+	ForceSeparate []GroupingRule `yaml:"force_separate"` // matching files always get their own commit, one per rule
+	ForceTogether []GroupingRule `yaml:"force_together"` // matching files always share a commit, one per rule
+}
+
+// GeneratedFileRule ties generated files to their source: the "*" in Source
+// (e.g. "*.proto") captures a stem that is substituted into each Generated
+// pattern (e.g. "*.pb.go"), matched in any directory.
+type GeneratedFileRule struct {
+	Source    string   `yaml:"source"`
+	Generated []string `yaml:"generated"`
 }
 
 // AIConfig holds AI provider settings.
 type AIConfig struct {
 	Provider   string `yaml:"provider"`
 	Model      string `yaml:"model"`
-	APIKey     string `yaml:"api_key"`     // can also use ANTHROPIC_API_KEY env var
+	APIKey     string `yaml:"api_key"`     // can also use ANTHROPIC_API_KEY env var; keyring:<service>[/<account>] or exec:<command> fetch it at startup
 	CodeReview bool   `yaml:"code_review"` // enable AI code review before push (default: true)
+
+	OnMissingAPIKey string `yaml:"on_missing_api_key"` // with no API key: "warn" (default; warn loudly, heuristic messages only), "disable_ai" (as if provider: none) or "fail" (refuse to start)
+
+	FallbackModels []string `yaml:"fallback_models"` // tried in order when the primary model is overloaded
+	ReviewFocus    []string `yaml:"review_focus"`    // review checklist: bugs, security, nil_safety, concurrency, mistakes, performance
+
+	MaxReviewIterations int `yaml:"max_review_iterations"` // fix rounds before asking keep trying / continue / abort (default 3)
+
+	CombineRefineAndReview bool `yaml:"combine_refine_and_review"` // small flushes: refine groups and review in one API call (falls back to two)
+
+	RequestsPerMinute int `yaml:"requests_per_minute"` // pace API requests to at most this many per minute, retries included (0 = unlimited)
+
+	MaxGroupsPerRequest int `yaml:"max_groups_per_request"` // refine/review/classify at most this many groups per API call (0 = all at once); prompts that are too long are split anyway
+
+	ReviewMode string `yaml:"review_mode"` // "blocking" (review before committing) or "async" (commit and push first, review in the background)
+
+	Temperature *float64 `yaml:"temperature"` // sampling temperature, 0-1 (unset = API default)
+	TopP        *float64 `yaml:"top_p"`       // nucleus sampling, 0-1 (unset = API default)
+
+	CommitModel string `yaml:"commit_model"` // model for grouping and commit messages (unset = model)
+	ReviewModel string `yaml:"review_model"` // model for code review and fixes (unset = model)
+
+	CommitLanguage string `yaml:"commit_language"` // language of commit message descriptions, e.g. es or ja; types stay English (default en)
+
+	ProxyURL   string `yaml:"proxy_url"`    // proxy for API calls (unset = HTTPS_PROXY / HTTP_PROXY env vars)
+	CACertPath string `yaml:"ca_cert_path"` // extra PEM root CAs for API calls, e.g. a corporate proxy's
+}
+
+// NotifyConfig holds the channels GitPulse alerts on.
+type NotifyConfig struct {
+	Desktop      bool   `yaml:"desktop"`       // desktop notification (notify-send on Linux, osascript on macOS)
+	SlackWebhook string `yaml:"slack_webhook"` // Slack incoming webhook URL; can also use GITPULSE_SLACK_WEBHOOK env var
 }
 
 // Load reads and parses the YAML config file.
@@ -47,6 +213,27 @@ func Load(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.resolvePushMode(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveReviewMode(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveFallbackEncoding(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveOnMissingAPIKey(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveGroupingStrategy(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateSampling(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateProxy(); err != nil {
+		return nil, err
+	}
 
 	// Override API key from env var if set (check both names)
 	if envKey := os.Getenv("CLAUDE_API_KEY"); envKey != "" {
@@ -54,18 +241,20 @@ func Load(path string) (*Config, error) {
 	} else if envKey := os.Getenv("ANTHROPIC_API_KEY"); envKey != "" {
 		cfg.AI.APIKey = envKey
 	}
+	if webhook := os.Getenv("GITPULSE_SLACK_WEBHOOK"); webhook != "" {
+		cfg.Notify.SlackWebhook = webhook
+	}
+	if err := cfg.resolveAPIKey(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
 
 // LoadFromDir looks for config in dir: dir/config.yaml, then dir/.gitpulse/config.yaml.
 // If watchPath is non-empty and no config found, returns default config with WatchPath set to watchPath.
-// Loads .env from dir so the project's API key is used even when running with -C from another directory.
+// Loads .env files (see loadEnv) so the project's API key is used even when running with -C from another directory.
 func LoadFromDir(dir, watchPath string) (*Config, error) {
-	// Load project's .env first (so -C /path/to/repo still picks up that repo's .env)
-	_ = godotenv.Load(filepath.Join(dir, ".env"))
-	// Then cwd .env so local overrides work
-	_ = godotenv.Load()
 	cfg := defaultConfig()
 
 	try := []string{
@@ -83,40 +272,284 @@ func LoadFromDir(dir, watchPath string) (*Config, error) {
 		if err := yaml.Unmarshal(data, cfg); err != nil {
 			return nil, err
 		}
-		if watchPath != "" {
-			cfg.WatchPath = watchPath
-		}
-		if envKey := os.Getenv("CLAUDE_API_KEY"); envKey != "" {
-			cfg.AI.APIKey = envKey
-		} else if envKey := os.Getenv("ANTHROPIC_API_KEY"); envKey != "" {
-			cfg.AI.APIKey = envKey
-		}
-		return cfg, nil
+		break
+	}
+	if err := cfg.resolvePushMode(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveReviewMode(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveFallbackEncoding(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveOnMissingAPIKey(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveGroupingStrategy(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateSampling(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateProxy(); err != nil {
+		return nil, err
 	}
 
-	// No config in dir — use defaults and set watch path
+	// No config in dir (or config without watch_path override) — set watch path
 	if watchPath != "" {
 		cfg.WatchPath = watchPath
 	}
+	if err := loadEnv(dir, cfg.EnvFile); err != nil {
+		return nil, err
+	}
+	if cfg.AI.CACertPath != "" && !filepath.IsAbs(cfg.AI.CACertPath) {
+		cfg.AI.CACertPath = filepath.Join(dir, cfg.AI.CACertPath)
+	}
 	if envKey := os.Getenv("CLAUDE_API_KEY"); envKey != "" {
 		cfg.AI.APIKey = envKey
 	} else if envKey := os.Getenv("ANTHROPIC_API_KEY"); envKey != "" {
 		cfg.AI.APIKey = envKey
 	}
+	if webhook := os.Getenv("GITPULSE_SLACK_WEBHOOK"); webhook != "" {
+		cfg.Notify.SlackWebhook = webhook
+	}
+	if err := cfg.resolveAPIKey(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+// loadEnv loads .env files into the process environment. godotenv never
+// overrides a variable that is already set, so the first file loaded wins and
+// the precedence is:
+//
+//  1. variables already exported in the shell
+//  2. env_file from config (relative paths are resolved against dir)
+//  3. the nearest .env found walking up from dir, stopping at the repo root
+//     (the directory containing .git) or the home directory
+//  4. .env in the current working directory
+//
+// A configured env_file that doesn't exist is an error; missing .env files are not.
+func loadEnv(dir, envFile string) error {
+	if envFile != "" {
+		if !filepath.IsAbs(envFile) {
+			envFile = filepath.Join(dir, envFile)
+		}
+		if err := godotenv.Load(envFile); err != nil {
+			return fmt.Errorf("failed to load env_file %s: %w", envFile, err)
+		}
+	}
+	if nearest := findEnvFile(dir); nearest != "" {
+		_ = godotenv.Load(nearest)
+	}
+	_ = godotenv.Load()
+	return nil
+}
+
+// findEnvFile returns the nearest .env at or above dir, or "" if none exists
+// before reaching the repo root or the home directory.
+func findEnvFile(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+
+	for {
+		candidate := filepath.Join(abs, ".env")
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+		if _, err := os.Stat(filepath.Join(abs, ".git")); err == nil {
+			return "" // repo root — don't leak into unrelated parents
+		}
+		if abs == home {
+			return ""
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return ""
+		}
+		abs = parent
+	}
+}
+
+// resolvePushMode maps the deprecated auto_push boolean onto push_mode and
+// validates the result.
+func (c *Config) resolvePushMode() error {
+	if c.PushMode == "" {
+		c.PushMode = PushModeAuto
+	}
+	if c.LegacyAutoPush != nil && !*c.LegacyAutoPush && c.PushMode == PushModeAuto {
+		c.PushMode = PushModeNever
+	}
+	c.LegacyAutoPush = nil
+
+	switch c.PushMode {
+	case PushModeAuto, PushModeManual, PushModeNever:
+		return nil
+	}
+	return fmt.Errorf("invalid push_mode %q (want %s, %s or %s)", c.PushMode, PushModeAuto, PushModeManual, PushModeNever)
+}
+
+// resolveReviewMode defaults ai.review_mode to blocking and validates it.
+func (c *Config) resolveReviewMode() error {
+	switch c.AI.ReviewMode {
+	case "":
+		c.AI.ReviewMode = ReviewModeBlocking
+		return nil
+	case ReviewModeBlocking, ReviewModeAsync:
+		return nil
+	}
+	return fmt.Errorf("invalid ai.review_mode %q (want %s or %s)", c.AI.ReviewMode, ReviewModeBlocking, ReviewModeAsync)
+}
+
+// resolveFallbackEncoding defaults fallback_encoding to latin1 and validates it.
+func (c *Config) resolveFallbackEncoding() error {
+	switch c.FallbackEncoding {
+	case "":
+		c.FallbackEncoding = EncodingLatin1
+		return nil
+	case EncodingLatin1, EncodingNone:
+		return nil
+	}
+	return fmt.Errorf("invalid fallback_encoding %q (want %s or %s)", c.FallbackEncoding, EncodingLatin1, EncodingNone)
+}
+
+// resolveOnMissingAPIKey defaults ai.on_missing_api_key to warn and validates it.
+func (c *Config) resolveOnMissingAPIKey() error {
+	switch c.AI.OnMissingAPIKey {
+	case "":
+		c.AI.OnMissingAPIKey = MissingAPIKeyWarn
+		return nil
+	case MissingAPIKeyWarn, MissingAPIKeyDisableAI, MissingAPIKeyFail:
+		return nil
+	}
+	return fmt.Errorf("invalid ai.on_missing_api_key %q (want %s, %s or %s)", c.AI.OnMissingAPIKey, MissingAPIKeyWarn, MissingAPIKeyDisableAI, MissingAPIKeyFail)
+}
+
+// resolveGroupingStrategy defaults grouping_strategy to directory and
+// validates it.
+func (c *Config) resolveGroupingStrategy() error {
+	switch c.GroupingStrategy {
+	case "":
+		c.GroupingStrategy = GroupingStrategyDirectory
+		return nil
+	case GroupingStrategyDirectory, GroupingStrategyType, GroupingStrategyHybrid:
+		return nil
+	}
+	return fmt.Errorf("invalid grouping_strategy %q (want %s, %s or %s)", c.GroupingStrategy,
+		GroupingStrategyDirectory, GroupingStrategyType, GroupingStrategyHybrid)
+}
+
+// validateSampling checks ai.temperature and ai.top_p are within the API's
+// 0-1 range.
+func (c *Config) validateSampling() error {
+	if t := c.AI.Temperature; t != nil && (*t < 0 || *t > 1) {
+		return fmt.Errorf("invalid ai.temperature %v (want 0 to 1)", *t)
+	}
+	if p := c.AI.TopP; p != nil && (*p <= 0 || *p > 1) {
+		return fmt.Errorf("invalid ai.top_p %v (want more than 0, up to 1)", *p)
+	}
+	return nil
+}
+
+// validateProxy checks ai.proxy_url is an absolute http, https or socks5 URL.
+func (c *Config) validateProxy() error {
+	if c.AI.ProxyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.AI.ProxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid ai.proxy_url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid ai.proxy_url %q (want an http://, https:// or socks5:// URL)", u.Redacted())
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid ai.proxy_url %q (no host)", u.Redacted())
+	}
+	return nil
+}
+
+// CommitModel returns the model that groups changes and writes commit
+// messages: ai.commit_model when set, otherwise ai.model.
+func (c *Config) CommitModel() string {
+	if c.AI.CommitModel != "" {
+		return c.AI.CommitModel
+	}
+	return c.AI.Model
+}
+
+// ReviewModel returns the model that reviews code and writes fixes:
+// ai.review_model when set, otherwise ai.model.
+func (c *Config) ReviewModel() string {
+	if c.AI.ReviewModel != "" {
+		return c.AI.ReviewModel
+	}
+	return c.AI.Model
+}
+
+// PushRemotes returns the remotes to push to: remotes when set, otherwise
+// just remote. The first one is also the remote fetched and pulled from.
+func (c *Config) PushRemotes() []string {
+	if len(c.Remotes) > 0 {
+		return c.Remotes
+	}
+	return []string{c.Remote}
+}
+
 func defaultConfig() *Config {
 	return &Config{
-		WatchPath:       ".",
-		DebounceSeconds: 900, // 15 min safety net
-		AutoPush:        true,
-		Remote:          "origin",
-		Branch:          "main",
+		WatchPath:        ".",
+		DebounceSeconds:  900, // 15 min safety net
+		PushMode:         PushModeAuto,
+		DiffConcurrency:  8,
+		LargeCommitLines: 1000,
+		DismissDays:      30,
+		ReviewMinLines:   5,
+		AuthorDate:       AuthorDateFirstChange,
+		Remote:           "origin",
+		Branch:           "auto",
+
+		CommitSubjectMaxLength: 72,
+		CommitBodyWrap:         72,
+
+		AttributionTrailer: true,
+
+		CoalesceAtomicSaves: true,
+
+		LargeBinaryPromptMB: 10,
+
+		RejectDegenerateGrouping: true,
+
+		SkipAIForWhitespaceOnly: true,
+
+		StartupGraceSeconds: 5,
+
 		AI: AIConfig{
-			Provider:   "claude",
-			Model:      "claude-sonnet-4-20250514",
-			CodeReview: true,
+			Provider:    "claude",
+			Model:       "claude-sonnet-4-20250514",
+			CodeReview:  true,
+			ReviewFocus: []string{"bugs", "security", "nil_safety", "concurrency", "mistakes"},
+
+			OnMissingAPIKey: MissingAPIKeyWarn,
+
+			MaxReviewIterations: 3,
+
+			RequestsPerMinute: 50,
+
+			ReviewMode: ReviewModeBlocking,
+
+			CommitLanguage: "en",
+		},
+		CommitTypes: []string{"feat", "fix", "refactor", "perf", "docs", "test", "style", "build", "ci", "chore", "revert"},
+		GroupingRules: []GroupingRule{
+			{Name: "docs", Patterns: []string{"*.md", "*.rst", "*.txt"}},
+			{Name: "config", Patterns: []string{"*.yaml", "*.yml", "*.json", "*.toml"}},
 		},
 		IgnorePatterns: []string{
 			"*.log",
@@ -128,14 +561,15 @@ func defaultConfig() *Config {
 	}
 }
 
-// WriteDefault writes the default config to dir/.gitpulse/config.yaml (creates .gitpulse if needed).
+// WriteDefault writes the default config, with every key commented, to
+// dir/.gitpulse/config.yaml (creates .gitpulse if needed).
 func WriteDefault(dir string) (string, error) {
 	cfgDir := filepath.Join(dir, ".gitpulse")
 	if err := os.MkdirAll(cfgDir, 0755); err != nil {
 		return "", err
 	}
 	path := filepath.Join(cfgDir, "config.yaml")
-	data, err := yaml.Marshal(defaultConfig())
+	data, err := commentedDefault()
 	if err != nil {
 		return "", err
 	}
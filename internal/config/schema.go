@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fieldDocs describes every config key, by dotted yaml path ("[]" marks the
+// fields of list items). Used for the comments `gitpulse init` writes and for
+// `gitpulse config schema`; keys missing here are still listed, undescribed.
+var fieldDocs = map[string]string{
+	"watch_path":       "directory to watch (a repo or a subdirectory of one)",
+	"debounce_seconds": "safety timer: auto-flush this long after the last change if you forget to `gitpulse push`",
+	"push_mode":        "auto (push after every flush), manual (only `gitpulse push` pushes) or never (commits stay local)",
+	"remote":           "remote to push to and check for new commits",
+	"remotes":          "push to each of these remotes, e.g. [origin, mirror]; the first is fetched and pulled from (overrides remote)",
+	"branch":           "branch to push; auto (or empty) pushes the currently checked-out branch",
+
+	"ai":                           "AI provider settings",
+	"ai.provider":                  "claude, or none for offline commit messages and no review (same as --no-ai)",
+	"ai.model":                     "model for grouping, commit messages, review and fixes",
+	"ai.api_key":                   "API key; leave empty and set ANTHROPIC_API_KEY (or CLAUDE_API_KEY) instead, or fetch it at startup with keyring:<service>[/<account>] (OS keyring, account defaults to anthropic) or exec:<command> (its output, e.g. exec:op read op://vault/anthropic/key)",
+	"ai.code_review":               "review diffs for blockers before committing",
+	"ai.fallback_models":           "models tried in order when the primary model is overloaded",
+	"ai.review_focus":              "review checklist: bugs, security, nil_safety, concurrency, mistakes, performance",
+	"ai.max_review_iterations":     "fix rounds before asking: keep trying, continue or abort",
+	"ai.combine_refine_and_review": "for small flushes, refine groups and review in one API call (falls back to separate calls if the reply doesn't parse)",
+	"ai.requests_per_minute":       "pace API requests (retries included) to at most this many per minute; bursts wait their turn (0 = unlimited)",
+	"ai.max_groups_per_request":    "send at most this many groups per refine, review or classify call and merge the results (0 = all at once). A prompt the API rejects as too long is halved and retried regardless",
+	"ai.review_mode":               "blocking (review before committing; blockers hold the commit) or async (commit and push right away, review in the background, notify on blockers)",
+
+	"ai.temperature": "sampling temperature from 0 to 1 for commit messages; grouping, review and fixes (JSON replies) use at most 0.2 unless top_p is set. Unset = API default",
+	"ai.top_p":       "nucleus sampling from 0 to 1; unset = API default. Some models reject temperature and top_p together",
+
+	"ai.commit_model": "model for grouping and commit messages, e.g. a cheaper one; unset = ai.model",
+	"ai.review_model": "model for code review and fixes, e.g. a stronger one; unset = ai.model. When it differs from the commit model, combine_refine_and_review is skipped",
+
+	"ai.on_missing_api_key": "what to do when no API key is set (ai.api_key, ANTHROPIC_API_KEY or CLAUDE_API_KEY): warn (warn at startup and on every flush, commit with heuristic messages and no review), disable_ai (the same, quietly, as with provider: none) or fail (refuse to start)",
+
+	"ai.commit_language": "language commit messages are written in, e.g. es, ja or pt-BR; conventional-commit types and scopes stay in English (default en)",
+
+	"ai.proxy_url":    "proxy for API calls (http://, https:// or socks5://, credentials allowed); unset = the HTTPS_PROXY / HTTP_PROXY / NO_PROXY env vars",
+	"ai.ca_cert_path": "PEM file of extra root CAs trusted for API calls, e.g. a corporate proxy that re-signs TLS (relative to the project dir)",
+
+	"ignore_patterns":       "paths never watched or committed (globs; a trailing / matches a directory)",
+	"coalesce_atomic_saves": "report an editor's atomic save (Vim's rename-to-backup, or write-temp-then-rename) as one Modified change for the saved file, dropping the temp and backup files it passes through",
+	"commit_review_footer":  "append a GitPulse-Review footer to commit messages when a review ran",
+	"attribution_trailer":   "append a Generated-by: GitPulse <version> (model: <model>) trailer to every GitPulse commit, so reviewers can tell it was AI-assisted and by which model",
+	"commit_types":          "allowed conventional-commit types; others are rewritten to chore",
+
+	"commit_subject_max_length": "longer subjects keep the words that fit and move the rest into the body (0 = off)",
+	"commit_body_wrap":          "wrap the moved overflow at this many columns (0 = don't wrap)",
+
+	"include_diffstat_in_body": "append a `git diff --stat`-style summary (files, +/- counts) to the commit body",
+
+	"auto_gitkeep": "add a .gitkeep to new empty directories so they're committed (default: skip them)",
+
+	"fallback_encoding": "how files that aren't UTF-8 (or UTF-16 with a BOM) are read for AI diffs and fixes: \"latin1\" (default) or \"none\" (skip AI processing for them)",
+
+	"preserve_manual_staging": "commit files staged by hand as their own commit instead of leaving them staged",
+
+	"group_root_depth": "group by the first N directories (e.g. 2 = services/api) instead of the parent dir (0 = parent dir)",
+
+	"grouping_rules":                               "file type clusters for files that would otherwise be singletons",
+	"grouping_rules[].name":                        "cluster name, used in the group reason",
+	"grouping_rules[].patterns":                    "globs matched against the file name or repo-relative path",
+	"grouping_overrides":                           "applied after heuristic grouping and again after AI refinement",
+	"grouping_overrides.force_separate":            "matching files always get their own commit, one per rule (wins over force_together)",
+	"grouping_overrides.force_separate[].name":     "rule name, used in the group reason",
+	"grouping_overrides.force_separate[].patterns": "globs matched against the file name or repo-relative path",
+	"grouping_overrides.force_together":            "matching files always share a commit, one per rule",
+	"grouping_overrides.force_together[].name":     "rule name, used in the group reason",
+	"grouping_overrides.force_together[].patterns": "globs matched against the file name or repo-relative path",
+	"confirm_grouping":                             "interactive: accept the AI grouping, revert to the heuristic one, or re-run it splitting more",
+
+	"grouping_strategy": "directory (heuristic groups refined by the AI), type (the AI types each file's change; one commit per type, e.g. all fixes together) or hybrid (one commit per type within each directory group)",
+
+	"skip_ai_for_whitespace_only": "commit a group whose diff only changes whitespace (e.g. a gofmt pass) as \"style: format code\" without an AI call",
+
+	"reject_degenerate_grouping": "when the AI puts every file of several unrelated groups in one commit with a generic message (e.g. \"various changes\"), ask it once more to keep groups focused, then fall back to the heuristic groups",
+
+	"import_outside_commits": "on startup and after each flush, add commits made without GitPulse since it last saw HEAD (e.g. while it wasn't running) to the history as ai_generated: false; otherwise they're only counted in the log",
+
+	"generated_file_rules":             "always commit generated files with the changed source they come from",
+	"generated_file_rules[].source":    "source glob; its * captures the stem, e.g. *.proto",
+	"generated_file_rules[].generated": "generated globs with the stem substituted for *, e.g. *.pb.go",
+
+	"max_commits_per_hour": "hold flushes once this many commits were made in the last hour; they coalesce into the next allowed one (0 = no cap); `gitpulse push` isn't held",
+
+	"amend_window_seconds":      "fold changes into the previous unpushed GitPulse commit if it's this recent (0 = off)",
+	"diff_concurrency":          "max parallel per-file diff fetches during a flush",
+	"large_commit_lines":        "warn (and offer to split) when a group changes more lines than this (0 = off)",
+	"split_by_time_gap_seconds": "flush edits separated by a quiet period this long as separate batches (0 = off)",
+	"dismiss_days":              "how long a dismissed review finding stays dismissed (0 = forever)",
+	"review_min_lines":          "skip the AI review when fewer lines than this changed in total (0 = always review)",
+	"author_date":               "first_change (author date = earliest edit in the group) or now; committer date is always now",
+	"opt_in_marker":             "when set, only auto-commit files whose content contains this string, e.g. // gitpulse:track",
+	"env_file":                  "explicit .env path (relative to the project dir); takes precedence over discovered .env files",
+	"auto_push":                 "deprecated: auto_push: false is read as push_mode: never",
+
+	"allow_force_push": "let amend_window_seconds fold changes into a commit that was already pushed, then force-push (with lease) to replace it. Warns and asks for confirmation every time, and is never done unattended; off = such changes get a new commit",
+
+	"require_persistent_history": "refuse to start when .gitpulse/history.json can't be written, instead of keeping history in memory for the run",
+
+	"flush_on_branch_switch": "when HEAD moves to another branch (git checkout/switch), commit the pending changes it carried over to the branch they were made on",
+
+	"sync_existing_changes_on_start": "on daemon startup, buffer the files git status already shows as changed (work in progress from before GitPulse started), so the next flush commits them too",
+	"startup_grace_seconds":          "how long after startup sync_existing_changes_on_start waits before reading git status, letting the watcher settle first",
+
+	"commit_immediately_patterns": "files committed on every save, each batch of saves in its own commit, instead of waiting for `gitpulse push` or the safety timer (globs matched against the file name or repo-relative path)",
+
+	"large_binary_prompt_mb": "interactive: ask before committing a new (untracked) binary file at least this many MB, e.g. a build artifact; non-interactive runs skip it with a warning (0 = off)",
+
+	"notify":               "where to alert about blockers found by ai.review_mode: async",
+	"notify.desktop":       "desktop notification (notify-send on Linux, osascript on macOS)",
+	"notify.slack_webhook": "Slack incoming webhook URL; leave empty and set GITPULSE_SLACK_WEBHOOK instead",
+}
+
+// Field is one config key as listed by `gitpulse config schema`.
+type Field struct {
+	Key         string // dotted yaml path, "[]" for fields of list items
+	Type        string
+	Default     string // "" for list item fields and unset optional keys
+	Description string
+}
+
+// Schema lists every config key with its type and default, in file order.
+func Schema() []Field {
+	var fields []Field
+	schemaFields(reflect.ValueOf(defaultConfig()).Elem(), "", false, &fields)
+	return fields
+}
+
+func schemaFields(v reflect.Value, prefix string, item bool, fields *[]Field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		f := Field{Key: key, Type: typeName(sf.Type), Description: fieldDocs[key]}
+		if !item {
+			f.Default = defaultString(v.Field(i))
+		}
+		*fields = append(*fields, f)
+
+		switch {
+		case sf.Type.Kind() == reflect.Struct:
+			schemaFields(v.Field(i), key+".", item, fields)
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Struct:
+			schemaFields(reflect.New(sf.Type.Elem()).Elem(), key+"[].", true, fields)
+		}
+	}
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Struct:
+		return "object"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// defaultString renders a default value the way it would be written in YAML.
+func defaultString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Struct:
+		return ""
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return defaultString(v.Elem())
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return "[]"
+		}
+		var n yaml.Node
+		if err := n.Encode(v.Interface()); err != nil {
+			return ""
+		}
+		flowStyle(&n)
+		out, err := yaml.Marshal(&n)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func flowStyle(n *yaml.Node) {
+	n.Style |= yaml.FlowStyle
+	for _, c := range n.Content {
+		flowStyle(c)
+	}
+}
+
+// commentedDefault renders the default config with each key preceded by a
+// comment describing it (and its default, for scalars).
+func commentedDefault() ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(defaultConfig()); err != nil {
+		return nil, err
+	}
+	annotate(&doc, "")
+	doc.HeadComment = "GitPulse config. Run `gitpulse config schema` to list every key with its type and default."
+	return yaml.Marshal(&doc)
+}
+
+func annotate(n *yaml.Node, prefix string) {
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		key := prefix + k.Value
+		comment := fieldDocs[key]
+		if v.Kind == yaml.ScalarNode {
+			def := v.Value
+			if def == "" {
+				def = `""`
+			}
+			comment = strings.TrimSpace(comment + " (default: " + def + ")")
+		}
+		k.HeadComment = comment
+		if v.Kind == yaml.MappingNode {
+			annotate(v, key+".")
+		}
+	}
+}
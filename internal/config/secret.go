@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// ai.api_key prefixes that fetch the key when the config is loaded instead
+// of storing it in plain text.
+const (
+	keyringPrefix = "keyring:" // keyring:<service>[/<account>], from the OS keyring
+	execPrefix    = "exec:"    // exec:<command>, the command's output (e.g. `op read ...`)
+)
+
+// keyringDefaultAccount is the keyring account read when keyring: names
+// only a service.
+const keyringDefaultAccount = "anthropic"
+
+// secretCommandTimeout bounds an exec: command, which may wait on the user
+// (e.g. a password manager unlock prompt).
+const secretCommandTimeout = 60 * time.Second
+
+// resolveAPIKey replaces a keyring: or exec: ai.api_key with the key it
+// points at. A literal key is left as it is. Called after the environment
+// variables are applied, so ANTHROPIC_API_KEY / CLAUDE_API_KEY win and
+// nothing is run when one of them is set.
+func (c *Config) resolveAPIKey() error {
+	if c.AI.Provider == "none" {
+		return nil
+	}
+	key := c.AI.APIKey
+	switch {
+	case strings.HasPrefix(key, keyringPrefix):
+		service, account, _ := strings.Cut(strings.TrimPrefix(key, keyringPrefix), "/")
+		if service == "" {
+			return fmt.Errorf("invalid ai.api_key %q (want keyring:<service> or keyring:<service>/<account>)", key)
+		}
+		if account == "" {
+			account = keyringDefaultAccount
+		}
+		secret, err := keyring.Get(service, account)
+		if err != nil {
+			return fmt.Errorf("failed to read ai.api_key from the keyring (service %q, account %q): %w", service, account, err)
+		}
+		c.AI.APIKey = strings.TrimSpace(secret)
+
+	case strings.HasPrefix(key, execPrefix):
+		command := strings.TrimSpace(strings.TrimPrefix(key, execPrefix))
+		if command == "" {
+			return fmt.Errorf("invalid ai.api_key %q (want exec:<command>)", key)
+		}
+		secret, err := runSecretCommand(command)
+		if err != nil {
+			return fmt.Errorf("failed to run the ai.api_key command: %w", err)
+		}
+		c.AI.APIKey = secret
+	default:
+		return nil
+	}
+
+	if c.AI.APIKey == "" {
+		return fmt.Errorf("ai.api_key %q resolved to an empty key", key)
+	}
+	return nil
+}
+
+// runSecretCommand runs command through the shell and returns its trimmed
+// output. The output is never included in errors; stderr is.
+func runSecretCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%s: timed out after %s", command, secretCommandTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", command, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
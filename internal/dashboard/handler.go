@@ -1,10 +1,15 @@
 package dashboard
 
 import (
+	"crypto/subtle"
 	"embed"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/firasastwani/gitpulse/internal/store"
 )
@@ -14,13 +19,42 @@ var staticFS embed.FS
 
 // Server serves the GitPulse Effects Dashboard.
 type Server struct {
-	store *store.Store
-	path  string // history path for display
+	store     *store.Store
+	path      string // history path for display
+	authToken string // required as a Bearer token for mutating endpoints; empty disables them
+
+	planPath string       // the daemon's plan.json, next to the history
+	flush    func() error // asks the daemon to flush now (POST /api/plan/flush); nil disables it
 }
 
 // NewServer creates a dashboard server for the given store.
 func NewServer(s *store.Store, historyPath string) *Server {
-	return &Server{store: s, path: historyPath}
+	return &Server{store: s, path: historyPath, planPath: filepath.Join(filepath.Dir(historyPath), "plan.json")}
+}
+
+// SetFlushFunc sets how POST /api/plan/flush asks the daemon to commit its
+// pending changes (e.g. by signalling it like `gitpulse push`).
+func (s *Server) SetFlushFunc(flush func() error) {
+	s.flush = flush
+}
+
+// SetAuthToken sets the Bearer token required by mutating endpoints (e.g.
+// DELETE /api/commits/{hash}). With no token those endpoints are disabled.
+func (s *Server) SetAuthToken(token string) {
+	s.authToken = token
+}
+
+// authorized checks the request's Bearer token, writing an error response if it fails.
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if s.authToken == "" {
+		http.Error(w, "disabled: start the dashboard with -token (or GITPULSE_DASHBOARD_TOKEN) to enable", http.StatusForbidden)
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.authToken)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
 }
 
 // Handler returns an http.Handler for the dashboard.
@@ -32,9 +66,17 @@ func (s *Server) Handler() http.Handler {
 
 	// API
 	mux.HandleFunc("GET /api/stats", s.handleStats)
+	mux.HandleFunc("GET /api/stats/timeseries", s.handleTimeSeries)
 	mux.HandleFunc("GET /api/history", s.handleHistory)
 	mux.HandleFunc("GET /api/commits/", s.handleCommitByHash)
+	mux.HandleFunc("DELETE /api/commits/", s.handleDeleteCommit)
 	mux.HandleFunc("GET /api/files", s.handleFilesByPath)
+	mux.HandleFunc("GET /api/plan", s.handlePlan)
+	mux.HandleFunc("POST /api/plan/flush", s.handlePlanFlush)
+
+	// Probes for load balancers / k8s; never behind the auth token
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
 
 	return mux
 }
@@ -60,12 +102,42 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
+func (s *Server) handleTimeSeries(w http.ResponseWriter, r *http.Request) {
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = "day"
+	}
+	_ = s.store.Reload()
+	series, err := buildTimeSeries(s.store.All(), bucket)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
 func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	_ = s.store.Reload()
-	records := s.store.All()
-	// Copy before reversing so we don't mutate the store's internal slice
-	out := make([]store.CommitRecord, len(records))
-	copy(out, records)
+	// Optional quick filters (already newest first): on commit message, and
+	// to the commits that never got a blocking review
+	msg := r.URL.Query().Get("message")
+	unreviewed := r.URL.Query().Get("unreviewed") != ""
+	if msg != "" || unreviewed {
+		var records []store.CommitRecord
+		if unreviewed {
+			records = filterByMessage(s.store.GetUnreviewed(), msg)
+		} else {
+			records = s.store.GetByMessage(msg)
+		}
+		if records == nil {
+			records = []store.CommitRecord{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(records)
+		return
+	}
+	out := s.store.All() // already a copy, safe to reverse in place
 	// Reverse chronological (newest first)
 	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
 		out[i], out[j] = out[j], out[i]
@@ -74,6 +146,22 @@ func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(out)
 }
 
+// filterByMessage keeps the records whose message contains substr
+// (case-insensitive). An empty substr keeps them all.
+func filterByMessage(records []store.CommitRecord, substr string) []store.CommitRecord {
+	if substr == "" {
+		return records
+	}
+	needle := strings.ToLower(substr)
+	var kept []store.CommitRecord
+	for _, r := range records {
+		if strings.Contains(strings.ToLower(r.Message), needle) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
 func (s *Server) handleCommitByHash(w http.ResponseWriter, r *http.Request) {
 	hash := strings.TrimPrefix(r.URL.Path, "/api/commits/")
 	if hash == "" {
@@ -90,14 +178,157 @@ func (s *Server) handleCommitByHash(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(record)
 }
 
+// handlePlan serves the daemon's current plan: its pending changes and the
+// commits it proposes for them. Empty when no daemon is running.
+func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	plan, err := store.LoadPlan(s.planPath)
+	if err != nil {
+		http.Error(w, "failed to read plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// handlePlanFlush approves the plan: it asks the daemon to commit (and push,
+// per push_mode) its pending changes now, like `gitpulse push`.
+func (s *Server) handlePlanFlush(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	if s.flush == nil {
+		http.Error(w, "flushing is not available from this dashboard", http.StatusNotImplemented)
+		return
+	}
+	if err := s.flush(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "flush requested"})
+}
+
+// handleDeleteCommit removes a commit record (e.g. one whose diff leaked a
+// secret) from history.json. Git history is not rewritten.
+func (s *Server) handleDeleteCommit(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	hash := strings.TrimPrefix(r.URL.Path, "/api/commits/")
+	if hash == "" {
+		http.Error(w, "hash required", http.StatusBadRequest)
+		return
+	}
+	_ = s.store.Reload()
+	if err := s.store.Delete(hash); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"deleted": hash,
+		"note":    "Removed from GitPulse history (" + s.path + ") only. The git commit and its contents remain in the repository; rewrite git history separately if it contains a secret.",
+	})
+}
+
+// handleFilesByPath serves the commits touching ?path=, newest first.
+// Optional ?since= and ?until= (RFC 3339, or YYYY-MM-DD for whole days)
+// bound them by date and ?limit= caps how many come back, so files with a
+// long history stay quick to page through.
 func (s *Server) handleFilesByPath(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Query().Get("path")
+	q := r.URL.Query()
+	path := q.Get("path")
 	if path == "" {
 		http.Error(w, "path query param required", http.StatusBadRequest)
 		return
 	}
+	since, err := parseTimeParam(q.Get("since"), false)
+	if err != nil {
+		http.Error(w, "since: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	until, err := parseTimeParam(q.Get("until"), true)
+	if err != nil {
+		http.Error(w, "until: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
 	_ = s.store.Reload()
 	records := s.store.GetByFile(path)
+	if !since.IsZero() || !until.IsZero() {
+		if until.IsZero() {
+			until = time.Now()
+		}
+		records = keepHashes(records, s.store.GetByDateRange(since, until))
+	}
+
+	// Newest first, then bounded
+	out := make([]store.CommitRecord, 0, len(records))
+	for i := len(records) - 1; i >= 0 && (limit == 0 || len(out) < limit); i-- {
+		out = append(out, records[i])
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(records)
+	json.NewEncoder(w).Encode(out)
+}
+
+// parseTimeParam parses an RFC 3339 time or a YYYY-MM-DD date, which means
+// the start of that day or, with endOfDay, its last instant. "" is the zero
+// time.
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want RFC 3339 (2006-01-02T15:04:05Z) or a date (2006-01-02), got %q", v)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
+
+// keepHashes returns the records that also appear in other, by hash, in
+// their original order.
+func keepHashes(records, other []store.CommitRecord) []store.CommitRecord {
+	hashes := make(map[string]bool, len(other))
+	for _, r := range other {
+		hashes[r.Hash] = true
+	}
+	var kept []store.CommitRecord
+	for _, r := range records {
+		if hashes[r.Hash] {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// handleHealthz reports that the process is up and serving.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleReadyz reports whether the history file can be loaded: 503 if it
+// can't be read or is corrupt. A missing file just means no commits yet.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := s.store.Reload(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
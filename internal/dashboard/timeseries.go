@@ -0,0 +1,93 @@
+package dashboard
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// TimeBucket aggregates GitPulse activity for one time interval.
+type TimeBucket struct {
+	Timestamp      time.Time `json:"timestamp"` // start of the bucket (UTC)
+	Commits        int       `json:"commits"`
+	LinesAdded     int       `json:"lines_added"`
+	LinesRemoved   int       `json:"lines_removed"`
+	ReviewBlockers int       `json:"review_blockers"`
+}
+
+// bucketStart truncates t to the start of its hour, day, or week (Monday), in UTC.
+func bucketStart(t time.Time, bucket string) (time.Time, error) {
+	t = t.UTC()
+	switch bucket {
+	case "hour":
+		return t.Truncate(time.Hour), nil
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		return day.AddDate(0, 0, -offset), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown bucket %q (use hour, day, or week)", bucket)
+	}
+}
+
+// nextBucket returns the start of the bucket following start.
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case "hour":
+		return start.Add(time.Hour)
+	case "week":
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// buildTimeSeries buckets records by CreatedAt, oldest first. Empty buckets
+// between the first and last commit are included so charts show gaps as zero.
+func buildTimeSeries(records []store.CommitRecord, bucket string) ([]TimeBucket, error) {
+	byStart := make(map[time.Time]*TimeBucket)
+	var first, last time.Time
+
+	for _, r := range records {
+		start, err := bucketStart(r.CreatedAt, bucket)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := byStart[start]
+		if !ok {
+			b = &TimeBucket{Timestamp: start}
+			byStart[start] = b
+		}
+		b.Commits++
+		for _, f := range r.Files {
+			b.LinesAdded += f.LinesAdded
+			b.LinesRemoved += f.LinesRemoved
+		}
+		if r.Review != nil && r.Review.HasBlockers {
+			b.ReviewBlockers++
+		}
+
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	series := []TimeBucket{}
+	if len(byStart) == 0 {
+		return series, nil
+	}
+	for t := first; !t.After(last); t = nextBucket(t, bucket) {
+		if b, ok := byStart[t]; ok {
+			series = append(series, *b)
+		} else {
+			series = append(series, TimeBucket{Timestamp: t})
+		}
+	}
+	return series, nil
+}
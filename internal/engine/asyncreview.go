@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// maxNotifiedFindings is how many blockers a notification lists by name.
+const maxNotifiedFindings = 5
+
+// reviewInBackground reviews groups that were already committed (and
+// pushed) as hashes, for ai.review_mode: async. Each commit's findings are
+// recorded on its history record as a post-push review; blockers are logged
+// and sent to the configured notify channels. prefetched is a review that
+// came with the refine call, if any.
+func (e *Engine) reviewInBackground(groups []grouper.FileGroup, hashes []string, prefetched *ai.ReviewResult) {
+	e.asyncReviews.Add(1)
+	go func() {
+		defer e.asyncReviews.Done()
+
+		result, err := prefetched, error(nil)
+		if result == nil {
+			result, err = e.reviewAI.ReviewCode(groups)
+		}
+		if err != nil {
+			e.logger.Warn("Background AI review failed", "err", err)
+			return
+		}
+		result = e.filterDismissed(e.filterSuppressed(result))
+
+		var blocked []string
+		var blockers []ai.ReviewFinding
+		for i, g := range groups {
+			var findings []ai.ReviewFinding
+			hasBlockers := false
+			for _, f := range result.Findings {
+				if !touchesGroups(f, []grouper.FileGroup{g}) {
+					continue
+				}
+				findings = append(findings, f)
+				if f.Severity == ai.SeverityError || f.Severity == ai.SeverityWarning {
+					hasBlockers = true
+					blockers = append(blockers, f)
+				}
+			}
+			record := &store.ReviewRecord{
+				Findings:    convertFindingsForStore(findings),
+				HasBlockers: hasBlockers,
+				Async:       true,
+			}
+			if err := e.store.SetReview(hashes[i], record); err != nil {
+				e.logger.Warn("Failed to record background review", "commit", hashes[i][:7], "err", err)
+			}
+			if hasBlockers {
+				blocked = append(blocked, hashes[i][:7])
+			}
+		}
+
+		if len(blocked) == 0 {
+			e.logger.Info("Background AI review passed", "commits", len(hashes), "issues", len(result.Findings))
+			return
+		}
+		e.emit(Event{Type: EventReviewBlocked, Findings: blockers})
+		e.logger.Warn("Background AI review found blockers in commits already made", "commits", strings.Join(blocked, ", "), "issues", len(blockers))
+		e.logger.ReviewFindings(blockers)
+
+		if err := e.notifier.Send("GitPulse review found blockers", blockerSummary(blocked, blockers)); err != nil {
+			e.logger.Warn("Could not send review notification", "err", err)
+		}
+	}()
+}
+
+// blockerSummary is the notification text for blockers found in commits.
+func blockerSummary(commits []string, blockers []ai.ReviewFinding) string {
+	noun := "commit"
+	if len(commits) > 1 {
+		noun = "commits"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s in %s %s:", plural(len(blockers), "blocking finding"), noun, strings.Join(commits, ", "))
+	for i, f := range blockers {
+		if i == maxNotifiedFindings {
+			fmt.Fprintf(&b, "\n…and %d more", len(blockers)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n- [%s] %s:%d %s", f.Severity, f.File, f.StartLine, f.Description)
+	}
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// watchBranchSwitch starts tracking the checked-out branch for
+// flush_on_branch_switch.
+func (e *Engine) watchBranchSwitch() {
+	gitDir, err := e.git.GitDir()
+	if err != nil {
+		e.logger.Warn("Can't watch for branch switches (flush_on_branch_switch)", "err", err)
+		return
+	}
+	e.watcher.WatchHead(gitDir)
+	e.headBranch, _ = e.git.CurrentBranch()
+}
+
+// handleHeadChange commits the pending changes to the branch they were made
+// on once HEAD has moved to another one (flush_on_branch_switch). Git has no
+// hook that runs before a checkout, so this happens right after it: the
+// pending files the switch carried over are committed to the old branch as
+// they are in the working tree (the new branch is left alone, and they stay
+// changed there). Only called from Run's goroutine.
+func (e *Engine) handleHeadChange() {
+	branch, _ := e.git.CurrentBranch() // "" when detached
+	prev := e.headBranch
+	if branch == prev {
+		return
+	}
+	// A rebase detaches HEAD while it runs; that's not a switch
+	if op, err := e.git.InProgressOperation(); err == nil && op != "" {
+		return
+	}
+	e.headBranch = branch
+	if prev == "" {
+		return // HEAD was detached: there's no branch to commit to
+	}
+
+	// Pending files that are still changed: the ones the checkout carried
+	// over. The others were reverted, or replaced by the new branch's version.
+	changed, err := e.git.ChangedFiles()
+	if err != nil {
+		e.logger.Warn("Could not check pending changes after a branch switch", "err", err)
+		return
+	}
+	e.mu.Lock()
+	var files []string
+	for _, fc := range e.pending {
+		if changed[fc.Path] && !containsString(files, fc.Path) {
+			files = append(files, fc.Path)
+		}
+	}
+	changes := append([]watcher.FileChange(nil), e.pending...)
+	e.mu.Unlock()
+	if len(files) == 0 {
+		return
+	}
+	e.logger.Info("Branch switched — committing pending changes to the branch they were made on",
+		"from", prev, "to", branch, "files", strings.Join(files, ", "))
+
+	var model, message string
+	var fileChanges []store.FileChange
+	hash, err := e.git.CommitToBranch(prev, files, func(diff string) string {
+		msg, msgModel, err := e.ai.GenerateCommitMessage(diff, files)
+		if err != nil {
+			e.logger.Warn("AI commit message failed for the branch switch commit, using fallback", "err", err)
+		}
+		model = msgModel
+		fileChanges = parseDiffStats(diff, files)
+		stampChangeTimes(fileChanges, changes)
+		message = e.withDiffStat(e.formatMessage(msg), fileChanges) + e.commitTrailers(nil, model)
+		return message
+	})
+	if errors.Is(err, git.ErrNothingToCommit) {
+		e.logger.Info("Pending changes already match the previous branch", "branch", prev)
+		return
+	}
+	if err != nil {
+		e.logger.Error("Failed to commit pending changes to the previous branch — they stay pending on the new one", err, "branch", prev)
+		return
+	}
+
+	record := store.CommitRecord{
+		Hash:        hash,
+		Message:     message,
+		Files:       e.commitStats(hash, fileChanges),
+		GroupReason: "branch switch",
+		AIGenerated: true,
+		Model:       model,
+		Branch:      prev,
+		LocalOnly:   true, // not on the branch GitPulse pushes
+	}
+	skippedReview("committed on branch switch").apply(&record)
+	if err := e.store.Save(record); err != nil {
+		e.logger.Warn("Failed to save commit record", "err", err)
+	}
+	e.logger.CommitSuccess(hash, message)
+	e.emit(Event{Type: EventCommit, Hash: hash, Message: message, Files: files})
+
+	// They're committed where they belong; don't commit them here too
+	e.mu.Lock()
+	var kept []watcher.FileChange
+	for _, fc := range e.pending {
+		if !containsString(files, fc.Path) {
+			kept = append(kept, fc)
+		}
+	}
+	e.pending = kept
+	snapshot := append([]watcher.FileChange(nil), kept...)
+	e.mu.Unlock()
+	e.emit(Event{Type: EventPending, Pending: snapshot})
+	e.updatePlan()
+
+	e.logger.Info("Committed to "+prev+"; the files are still changed in the working tree on "+branch+"",
+		"files", strings.Join(files, ", "))
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"regexp"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// typeGroupingHint has the refine step write messages for the typed groups
+// without regrouping them.
+const typeGroupingHint = "Keep the groups exactly as given; do not move files between them. " +
+	"Each group holds one kind of change: use the type named in its reason as the commit type."
+
+// commitTypeRe matches the "type" of a conventional commit subject, up to
+// the optional scope and "!".
+var commitTypeRe = regexp.MustCompile(`^[A-Za-z]+(\([^)]*\))?!?:`)
+
+// groupByType forms one commit per conventional-commit type the AI gives
+// each file's change (grouping_strategy: type), or one per type within each
+// heuristic group (hybrid), and has the refine step write their messages.
+// ok is false when the classification failed and the caller should refine
+// the heuristic groups as usual.
+func (e *Engine) groupByType(groups []grouper.FileGroup) (typed []grouper.FileGroup, grouping string, ok bool) {
+	types, err := e.ai.ClassifyChanges(groups)
+	if err != nil {
+		e.logger.Warn("AI change classification failed, grouping by directory", "err", err)
+		return nil, "", false
+	}
+	typed = grouper.GroupByType(groups, types, e.cfg.GroupingStrategy == config.GroupingStrategyHybrid)
+	for i := range typed {
+		typed[i].Scope = grouper.ResolveScope(e.git.Root(), typed[i].Files)
+	}
+	e.logger.Info("Grouped by change type", "strategy", e.cfg.GroupingStrategy, "groups", len(typed))
+
+	grouping = store.GroupingAI
+	if e.cfg.AI.Provider == ai.ProviderNone {
+		grouping = store.GroupingHeuristic
+	}
+
+	// Messages come from the refine call; any group it reshaped anyway gets
+	// its own in finishRefine
+	refined, err := e.ai.RefineWithHint(append([]grouper.FileGroup(nil), typed...), typeGroupingHint)
+	if err != nil {
+		e.logger.Warn("AI refinement failed for the typed groups, writing their messages one by one", "err", err)
+	} else {
+		grouper.CopyMessages(typed, refined)
+	}
+	typed = e.finishRefine(groups, typed)
+
+	for i, g := range typed {
+		if g.Type != "" {
+			typed[i].CommitMessage = withCommitType(g.CommitMessage, g.Type)
+		}
+	}
+	return typed, grouping, true
+}
+
+// withCommitType gives a commit message the conventional-commit type typ,
+// keeping its scope and description.
+func withCommitType(message, typ string) string {
+	if loc := commitTypeRe.FindStringSubmatchIndex(message); loc != nil {
+		scope := ""
+		if loc[2] >= 0 {
+			scope = message[loc[2]:loc[3]]
+		}
+		bang := ""
+		if message[loc[1]-2] == '!' {
+			bang = "!"
+		}
+		return typ + scope + bang + ":" + message[loc[1]:]
+	}
+	return typ + ": " + message
+}
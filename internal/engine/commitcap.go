@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// commitWindow is the sliding window max_commits_per_hour is counted over.
+const commitWindow = time.Hour
+
+// recordCommits counts n commits made just now against max_commits_per_hour.
+func (e *Engine) recordCommits(n int) {
+	if e.cfg.MaxCommitsPerHour <= 0 || n == 0 {
+		return
+	}
+	e.timerMu.Lock()
+	defer e.timerMu.Unlock()
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		e.commitTimes = append(e.commitTimes, now)
+	}
+}
+
+// nextCommitSlot returns when max_commits_per_hour next lets a flush
+// commit, or the zero time if it can now. Callers must hold timerMu.
+func (e *Engine) nextCommitSlot() time.Time {
+	limit := e.cfg.MaxCommitsPerHour
+	if limit <= 0 {
+		return time.Time{}
+	}
+	cutoff := time.Now().Add(-commitWindow)
+	for len(e.commitTimes) > 0 && !e.commitTimes[0].After(cutoff) {
+		e.commitTimes = e.commitTimes[1:]
+	}
+	if len(e.commitTimes) < limit {
+		return time.Time{}
+	}
+	return e.commitTimes[len(e.commitTimes)-limit].Add(commitWindow)
+}
+
+// holdForCommitCap reports whether max_commits_per_hour has been reached.
+// If so, pending changes stay buffered (and keep coalescing) and a flush is
+// scheduled for when the oldest commit in the window ages out.
+func (e *Engine) holdForCommitCap() bool {
+	e.timerMu.Lock()
+	slot := e.nextCommitSlot()
+	if slot.IsZero() {
+		e.timerMu.Unlock()
+		return false
+	}
+	if e.safetyTimer != nil {
+		e.safetyTimer.Stop()
+	}
+	if e.capTimer != nil {
+		e.capTimer.Stop()
+	}
+	e.capTimer = time.AfterFunc(time.Until(slot), func() {
+		if e.PendingCount() > 0 {
+			e.Flush()
+		}
+	})
+	e.timerMu.Unlock()
+	e.emit(Event{Type: EventTimer, Deadline: slot})
+
+	e.logger.Info("Commit cap reached — keeping changes buffered until the next slot (`gitpulse push` commits now)",
+		"max_commits_per_hour", e.cfg.MaxCommitsPerHour, "until", slot.Format("15:04"), "pending", e.PendingCount())
+	return true
+}
+
+// requeue puts changes taken for a flush back at the front of pending.
+func (e *Engine) requeue(batches [][]watcher.FileChange) {
+	var files []watcher.FileChange
+	for _, b := range batches {
+		files = append(files, b...)
+	}
+	e.mu.Lock()
+	e.pending = append(files, e.pending...)
+	snapshot := append([]watcher.FileChange(nil), e.pending...)
+	e.mu.Unlock()
+	e.emit(Event{Type: EventPending, Pending: snapshot})
+	e.updatePlan()
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// conflictMarkers are the line prefixes git writes around unresolved merge
+// conflicts (||||||| is diff3 style). "=======" alone is left out: it is
+// also a Markdown/RST heading underline.
+var conflictMarkers = [][]byte{[]byte("<<<<<<<"), []byte(">>>>>>>"), []byte("|||||||")}
+
+// hasConflictMarkers reports whether data has a line starting with a
+// conflict marker followed by a space or the end of the line.
+func hasConflictMarkers(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		for _, m := range conflictMarkers {
+			if bytes.HasPrefix(line, m) && (len(line) == len(m) || line[len(m)] == ' ') {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conflictedFiles returns the changed files whose content still has
+// merge-conflict markers. Deleted files have none.
+func (e *Engine) conflictedFiles(files []watcher.FileChange) []string {
+	var conflicted []string
+	for _, fc := range files {
+		data, err := os.ReadFile(filepath.Join(e.git.Root(), fc.Path))
+		if err == nil && hasConflictMarkers(data) {
+			conflicted = append(conflicted, fc.Path)
+		}
+	}
+	return conflicted
+}
+
+// dropConflicted keeps files with unresolved merge conflicts out of the
+// flush. Interactive runs pause until the user resolves them (then they're
+// committed as usual) or chooses to skip them; non-interactive runs skip
+// them. A skipped file is picked up again the next time it's saved.
+func (e *Engine) dropConflicted(files []watcher.FileChange) []watcher.FileChange {
+	for {
+		conflicted := e.conflictedFiles(files)
+		if len(conflicted) == 0 {
+			return files
+		}
+		e.logger.Warn("MERGE CONFLICT MARKERS FOUND — these files will not be committed until they're resolved", "files", strings.Join(conflicted, ", "))
+
+		if e.Interactive {
+			recheck, err := e.logger.PromptConflicts(conflicted)
+			if err == nil && recheck {
+				continue
+			}
+		}
+
+		var kept []watcher.FileChange
+		for _, fc := range files {
+			if !containsString(conflicted, fc.Path) {
+				kept = append(kept, fc)
+			}
+		}
+		e.logger.Info("Skipping conflicted files", "files", len(conflicted))
+		return kept
+	}
+}
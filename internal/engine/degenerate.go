@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// degenerateMinFiles is the fewest files a single AI group must lump together
+// before it can count as a refusal to group.
+const degenerateMinFiles = 4
+
+// genericDescRe matches commit descriptions and grouping reasons that say
+// nothing about the change.
+var genericDescRe = regexp.MustCompile(`(?i)^(various|misc(ellaneous)?|multiple|several|assorted|general|some)\b|` +
+	`^(update|change|modify|edit)[sd]?( (the )?(files|code|project|codebase|stuff))?$|` +
+	`^(changes|updates|auto-commit changes|wip|work in progress)$`)
+
+// isGeneric reports whether a commit message (its subject's description,
+// after any "type(scope):") or grouping reason is a catch-all.
+func isGeneric(text string) bool {
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "\n")
+	if _, desc, ok := strings.Cut(text, ":"); ok {
+		text = desc
+	}
+	text = strings.TrimSuffix(strings.TrimSpace(text), ".")
+	return text == "" || genericDescRe.MatchString(text)
+}
+
+// degenerateGrouping reports whether the AI answered with one group holding
+// every file of several unrelated heuristic groups under a generic message or
+// reason, i.e. it didn't really group (reject_degenerate_grouping).
+func degenerateGrouping(heuristic, refined []grouper.FileGroup) bool {
+	if len(refined) != 1 || len(heuristic) < 2 || len(refined[0].Files) < degenerateMinFiles {
+		return false
+	}
+	return isGeneric(refined[0].CommitMessage) || isGeneric(refined[0].Reason)
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// GitRepo is the set of git operations the engine needs. *git.Manager is the
+// production implementation; tests can inject a fake via NewWithDeps to
+// assert the stage/commit/push sequence without a real repo or remote.
+type GitRepo interface {
+	Root() string
+	StagedFiles() ([]string, error)
+	ChangedFiles() (map[string]bool, error)
+	ModeChanged(path string) (bool, error)
+	GetStagedDiff() (string, error)
+	BeginDiffSession()
+	GetFileDiff(path string) (string, error)
+	Submodules() ([]git.Submodule, error)
+	Commit(message string) (string, error)
+	CommitFilesAt(files []string, message string, authorTime time.Time) (string, error)
+	AmendLastCommit(files []string, message string) (string, error)
+	HeadHash() (string, error)
+	InHead(path string) (bool, error)
+	IsPushed(hash string) (bool, error)
+	IsPushedTo(hash, remote string) (bool, error)
+	GetCommitDiff(hash string) (string, error)
+	DiffStat(hash string) ([]git.FileStat, error)
+	CommitsSince(since string, max int) ([]git.CommitInfo, error)
+	TargetBranch() (string, error)
+	CurrentBranch() (string, error)
+	GitDir() (string, error)
+	CommitToBranch(branch string, files []string, message func(diff string) string) (string, error)
+	Fetch() error
+	IsBehind() (bool, int, error)
+	Pull() (map[string]string, error)
+	InProgressOperation() (string, error)
+	PushTo(remote string) error
+	ForcePushTo(remote string) error
+}
+
+// AIClient is the set of model calls the engine makes. *ai.Client is the
+// production implementation.
+type AIClient interface {
+	RefineAndCommit(groups []grouper.FileGroup) ([]grouper.FileGroup, error)
+	RefineWithHint(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, error)
+	GenerateCommitMessage(diff string, files []string) (message, model string, err error)
+	ReviewCode(groups []grouper.FileGroup) (*ai.ReviewResult, error)
+	RefineAndReview(groups []grouper.FileGroup) ([]grouper.FileGroup, *ai.ReviewResult, error)
+	GenerateFix(filePath string, finding ai.ReviewFinding, primaryContent string, relatedContents map[string]string) (string, error)
+	ClassifyChanges(groups []grouper.FileGroup) (map[string]string, error)
+}
+
+var (
+	_ GitRepo  = (*git.Manager)(nil)
+	_ AIClient = (*ai.Client)(nil)
+	_ AIClient = (*ai.OfflineClient)(nil)
+)
@@ -0,0 +1,33 @@
+package engine
+
+import "github.com/firasastwani/gitpulse/internal/store"
+
+// commitStats replaces the line counts and statuses of changes, parsed from
+// the text diff before committing, with the committed tree's own stats (see
+// git.Manager.DiffStat), which get renames and binary files right. Diffs and
+// change times are kept; a rename's entry is the new path's, with OldPath
+// set. Falls back to changes as they are if git can't tell.
+func (e *Engine) commitStats(hash string, changes []store.FileChange) []store.FileChange {
+	stats, err := e.git.DiffStat(hash)
+	if err != nil {
+		e.logger.Warn("Could not read commit stats, keeping counts from the diff", "err", err)
+		return changes
+	}
+
+	byPath := make(map[string]store.FileChange, len(changes))
+	for _, fc := range changes {
+		byPath[fc.Path] = fc
+	}
+	result := make([]store.FileChange, 0, len(stats))
+	for _, s := range stats {
+		fc := byPath[s.Path]
+		fc.Path = s.Path
+		fc.OldPath = s.OldPath
+		fc.LinesAdded = s.Added
+		fc.LinesRemoved = s.Removed
+		fc.Status = s.Status
+		fc.Binary = s.Binary
+		result = append(result, fc)
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// gitkeepName is the placeholder file that makes git track an empty directory.
+const gitkeepName = ".gitkeep"
+
+// handleDirectories takes directory events out of files: git can't stage a
+// directory. With auto_gitkeep, each empty directory under one gets a
+// .gitkeep that is committed in its place; otherwise empty directories are
+// skipped. Files inside a directory come through their own events.
+func (e *Engine) handleDirectories(files []watcher.FileChange) []watcher.FileChange {
+	root := e.git.Root()
+	var kept []watcher.FileChange
+	var empty []string
+	for _, fc := range files {
+		info, err := os.Stat(filepath.Join(root, fc.Path))
+		if err != nil || !info.IsDir() {
+			kept = append(kept, fc)
+			continue
+		}
+		for _, dir := range emptyDirs(root, fc.Path) {
+			if containsString(empty, dir) {
+				continue
+			}
+			empty = append(empty, dir)
+			if !e.cfg.AutoGitkeep {
+				continue
+			}
+			keep := filepath.Join(dir, gitkeepName)
+			if err := os.WriteFile(filepath.Join(root, keep), nil, 0644); err != nil {
+				e.logger.Warn("Could not add .gitkeep", "dir", dir, "err", err)
+				continue
+			}
+			kept = append(kept, watcher.FileChange{Path: keep, Type: watcher.Created, Time: fc.Time})
+		}
+	}
+
+	if len(empty) > 0 {
+		if e.cfg.AutoGitkeep {
+			e.logger.Info("Added .gitkeep to new empty directories", "dirs", strings.Join(empty, ", "))
+		} else {
+			e.logger.Info("Skipping empty directories — git doesn't track them (auto_gitkeep: true adds a .gitkeep)", "dirs", strings.Join(empty, ", "))
+		}
+	}
+	return kept
+}
+
+// emptyDirs returns the directories at or under dir (relative to root) that
+// have no entries at all: the leaves a .gitkeep has to go in.
+func emptyDirs(root, dir string) []string {
+	var empty []string
+	filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if entries, err := os.ReadDir(path); err == nil && len(entries) == 0 {
+			if rel, err := filepath.Rel(root, path); err == nil {
+				empty = append(empty, rel)
+			}
+		}
+		return nil
+	})
+	return empty
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+)
+
+// Byte-order marks recognised at the start of a file.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// textEncoding is how a file's bytes were decoded to text, so an AI fix can
+// be written back in the same encoding, with the same byte-order mark.
+type textEncoding struct {
+	name string // "utf-8", "utf-16le", "utf-16be" or "latin1"
+	bom  []byte // byte-order mark the file started with, or nil
+}
+
+// decodeText decodes a file's content to UTF-8 text without its byte-order
+// mark. Content without a UTF-16 BOM that isn't valid UTF-8 is read with
+// fallback (config.EncodingLatin1), or refused with config.EncodingNone.
+// Binary content (a NUL byte) is refused too.
+func decodeText(data []byte, fallback string) (string, textEncoding, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data, binary.LittleEndian, textEncoding{name: "utf-16le", bom: bomUTF16LE})
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data, binary.BigEndian, textEncoding{name: "utf-16be", bom: bomUTF16BE})
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "", textEncoding{}, fmt.Errorf("binary content")
+	}
+
+	if bytes.HasPrefix(data, bomUTF8) {
+		if !utf8.Valid(data[len(bomUTF8):]) {
+			return "", textEncoding{}, fmt.Errorf("invalid UTF-8 after a UTF-8 byte-order mark")
+		}
+		return string(data[len(bomUTF8):]), textEncoding{name: "utf-8", bom: bomUTF8}, nil
+	}
+	if utf8.Valid(data) {
+		return string(data), textEncoding{name: "utf-8"}, nil
+	}
+	if fallback != config.EncodingLatin1 {
+		return "", textEncoding{}, fmt.Errorf("not valid UTF-8 (fallback_encoding: %s)", fallback)
+	}
+	return decodeLatin1(data), textEncoding{name: "latin1"}, nil
+}
+
+// decodeUTF16 decodes BOM-prefixed UTF-16 content.
+func decodeUTF16(data []byte, order binary.ByteOrder, enc textEncoding) (string, textEncoding, error) {
+	data = data[len(enc.bom):]
+	if len(data)%2 != 0 {
+		return "", textEncoding{}, fmt.Errorf("truncated %s content", enc.name)
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), enc, nil
+}
+
+// decodeLatin1 maps each ISO-8859-1 byte to the rune of the same value.
+func decodeLatin1(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+// encode turns text back into the file's encoding, byte-order mark first.
+// A BOM the text itself starts with (e.g. echoed by the AI) isn't doubled.
+// Fails if text has characters the encoding can't represent.
+func (enc textEncoding) encode(text string) ([]byte, error) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	out := append([]byte(nil), enc.bom...)
+
+	switch enc.name {
+	case "utf-16le", "utf-16be":
+		var order binary.AppendByteOrder = binary.LittleEndian
+		if enc.name == "utf-16be" {
+			order = binary.BigEndian
+		}
+		for _, u := range utf16.Encode([]rune(text)) {
+			out = order.AppendUint16(out, u)
+		}
+	case "latin1":
+		for _, r := range text {
+			if r > 0xFF {
+				return nil, fmt.Errorf("%q can't be written as Latin-1", r)
+			}
+			out = append(out, byte(r))
+		}
+	default:
+		out = append(out, text...)
+	}
+	return out, nil
+}
+
+// diffText makes a file's diff safe to send to the AI and store: a diff
+// that isn't valid UTF-8 is decoded with fallback_encoding, or replaced by a
+// placeholder (with a warning) when the file isn't text in a supported
+// encoding.
+func (e *Engine) diffText(path, diff string) string {
+	if utf8.ValidString(diff) {
+		return diff
+	}
+	text, _, err := decodeText([]byte(diff), e.cfg.FallbackEncoding)
+	if err != nil {
+		e.logger.Warn("Skipping AI processing for a file that isn't text in a supported encoding", "file", path, "err", err)
+		return fmt.Sprintf("--- a/%s\n+++ b/%s\n(content not shown: not text in a supported encoding)", path, path)
+	}
+	return text
+}
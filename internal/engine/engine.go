@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,13 +14,16 @@ import (
 	"github.com/firasastwani/gitpulse/internal/config"
 	"github.com/firasastwani/gitpulse/internal/git"
 	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/notify"
 	"github.com/firasastwani/gitpulse/internal/store"
 	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/version"
 	"github.com/firasastwani/gitpulse/internal/watcher"
 )
 
-// how many time
-const maxReviewIterations = 3
+// default number of fix rounds before the review loop asks how to proceed
+// (ai.max_review_iterations)
+const defaultMaxReviewIterations = 3
 
 // Engine orchestrates the full GitPulse pipeline:
 // watcher buffers changes -> user triggers `gitpulse push` OR safety timer fires
@@ -26,11 +31,23 @@ type Engine struct {
 	cfg     *config.Config
 	logger  *ui.Logger
 	watcher *watcher.Watcher
-	git     *git.Manager
-	ai      *ai.Client
+	git     GitRepo
+	ai      AIClient // groups changes and writes commit messages (ai.commit_model)
 	store   *store.Store
 	done    chan struct{}
 
+	// event subscribers (see Subscribe), closed on Stop
+	subMu       sync.Mutex
+	subscribers []chan Event
+	stopped     bool
+
+	reviewAI AIClient // reviews code and writes fixes (ai.review_model); the same as ai unless that differs
+
+	dismissed *store.Dismissals // review findings the user marked as false positives
+
+	notifier     *notify.Notifier // alerts for blockers found by async reviews
+	asyncReviews sync.WaitGroup   // background reviews (ai.review_mode: async) still running
+
 	// Interactive controls whether the engine can prompt the user.
 	// Set to true in daemon mode (user at terminal), false for safety timer auto-flush.
 	Interactive bool
@@ -39,48 +56,192 @@ type Engine struct {
 	mu      sync.Mutex
 	pending []watcher.FileChange
 
+	planMu sync.Mutex // serializes writes of .gitpulse/plan.json (see updatePlan)
+
+	// flush queue (see claimFlush): at most one pipeline runs at a time, and
+	// triggers that arrive meanwhile share one queued flush (protected by flushMu)
+	flushMu      sync.Mutex
+	flushRunning bool
+	flushNext    *queuedFlush
+
 	// safety timer — auto-flushes if user forgets
 	timerMu     sync.Mutex
 	safetyTimer *time.Timer
+
+	// snooze (`gitpulse snooze`) — buffer but don't flush until snoozeUntil (protected by timerMu)
+	snoozeUntil time.Time
+	snoozeTimer *time.Timer
+
+	// max_commits_per_hour — when recent commits were made, and the timer
+	// that flushes once the cap allows it again (protected by timerMu)
+	commitTimes []time.Time
+	capTimer    *time.Timer
+
+	// polls while a flush is held for an in-progress rebase/merge (protected by timerMu)
+	busyTimer *time.Timer
+
+	// the checked-out branch, for flush_on_branch_switch (only used by Run's goroutine)
+	headBranch string
+
+	// no API key with ai.on_missing_api_key: warn, so every flush says so
+	missingAPIKey bool
+
+	// a pushed commit was amended (allow_force_push), so pushes must force
+	// until one succeeds everywhere (protected by mu)
+	forcePush bool
 }
 
 // New creates a new Engine with all components wired together.
 func New(cfg *config.Config, logger *ui.Logger) (*Engine, error) {
-	w, err := watcher.New(cfg.WatchPath, cfg.DebounceSeconds, cfg.IgnorePatterns)
+	g, err := git.New(cfg.WatchPath, cfg.PushRemotes()[0], cfg.Branch)
 	if err != nil {
 		return nil, err
 	}
 
-	g, err := git.New(cfg.WatchPath, cfg.Remote, cfg.Branch)
+	missingKey, err := checkAPIKey(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	aiClient, err := newAIClient(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
+	e, err := NewWithDeps(cfg, logger, g, aiClient)
+	if err != nil {
+		return nil, err
+	}
+	e.missingAPIKey = missingKey
+	if client, ok := aiClient.(*ai.Client); ok && cfg.ReviewModel() != cfg.CommitModel() {
+		e.SetReviewAI(client.WithModel(cfg.ReviewModel()))
+		logger.Info("Using separate models", "commit", cfg.CommitModel(), "review", cfg.ReviewModel())
+	}
+	return e, nil
+}
+
+// checkAPIKey applies ai.on_missing_api_key when the Claude provider has no
+// key: fail returns an error, and warn and disable_ai switch cfg to
+// ai.provider: none so no call is made that can only fail. It reports
+// whether flushes should keep warning about it (warn).
+func checkAPIKey(cfg *config.Config, logger *ui.Logger) (bool, error) {
+	if cfg.AI.Provider == ai.ProviderNone || strings.TrimSpace(cfg.AI.APIKey) != "" {
+		return false, nil
+	}
+	switch cfg.AI.OnMissingAPIKey {
+	case config.MissingAPIKeyFail:
+		return false, errors.New("no API key: set ANTHROPIC_API_KEY or ai.api_key, or ai.provider: none to run without AI (ai.on_missing_api_key is fail)")
+	case config.MissingAPIKeyDisableAI:
+		logger.Info("No API key, AI disabled (ai.on_missing_api_key: disable_ai)")
+		cfg.AI.Provider = ai.ProviderNone
+		return false, nil
+	}
+	logger.Warn("No API key set — AI grouping, commit messages and code review are OFF. " +
+		"Set ANTHROPIC_API_KEY or ai.api_key, or ai.on_missing_api_key: disable_ai to silence this")
+	cfg.AI.Provider = ai.ProviderNone
+	return true, nil
+}
+
+// newAIClient picks the AI implementation for cfg.AI.Provider: "none" gives
+// deterministic offline messages, anything else talks to Claude.
+func newAIClient(cfg *config.Config, logger *ui.Logger) (AIClient, error) {
+	if cfg.AI.Provider == ai.ProviderNone {
+		logger.Info("AI disabled — using deterministic commit messages, skipping code review")
+		offline := ai.NewOfflineClient()
+		offline.SetCommitTypes(cfg.CommitTypes)
+		return offline, nil
+	}
 
-	aiClient := ai.NewClient(cfg.AI.APIKey, cfg.AI.Model)
+	httpClient, err := ai.NewHTTPClient(cfg.AI.ProxyURL, cfg.AI.CACertPath)
+	if err != nil {
+		return nil, err
+	}
+	client := ai.NewClient(cfg.AI.APIKey, cfg.CommitModel(), httpClient)
+	client.SetCommitTypes(cfg.CommitTypes)
+	client.SetCommitLanguage(cfg.AI.CommitLanguage)
+	client.SetReviewFocus(cfg.AI.ReviewFocus)
+	client.SetFallbackModels(cfg.AI.FallbackModels)
+	client.SetRequestsPerMinute(cfg.AI.RequestsPerMinute)
+	client.SetMaxGroupsPerRequest(cfg.AI.MaxGroupsPerRequest)
+	client.SetSampling(cfg.AI.Temperature, cfg.AI.TopP)
+	return client, nil
+}
 
-	historyPath := filepath.Join(cfg.WatchPath, ".gitpulse", "history.json")
-	s, err := store.New(historyPath)
+// NewWithDeps creates an Engine that uses the given git and AI
+// implementations instead of constructing real ones — e.g. fakes in tests.
+func NewWithDeps(cfg *config.Config, logger *ui.Logger, repo GitRepo, aiClient AIClient) (*Engine, error) {
+	w, err := watcher.New(cfg.WatchPath, cfg.DebounceSeconds, cfg.IgnorePatterns)
 	if err != nil {
 		return nil, err
 	}
+	w.CoalesceAtomicSaves(cfg.CoalesceAtomicSaves)
 
-	return &Engine{
-		cfg:     cfg,
-		logger:  logger,
-		watcher: w,
-		git:     g,
-		ai:      aiClient,
-		store:   s,
-		done:    make(chan struct{}),
-	}, nil
+	s, err := openStore(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	dismissed, err := store.NewDismissals(filepath.Join(cfg.WatchPath, ".gitpulse", "dismissed.json"))
+	if err != nil && s.InMemory() {
+		// .gitpulse is unusable; keep dismissals in memory like the history
+		dismissed, err = store.NewDismissals("")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		cfg:       cfg,
+		logger:    logger,
+		watcher:   w,
+		git:       repo,
+		ai:        aiClient,
+		reviewAI:  aiClient,
+		store:     s,
+		dismissed: dismissed,
+		notifier:  notify.New(cfg.Notify.Desktop, cfg.Notify.SlackWebhook),
+		done:      make(chan struct{}),
+	}
+	w.FilterModeChanges(e.modeChanged)
+	return e, nil
+}
+
+// SetReviewAI makes reviews and review fixes use c instead of the client
+// NewWithDeps was given, as with a separate ai.review_model.
+func (e *Engine) SetReviewAI(c AIClient) {
+	e.reviewAI = c
+}
+
+// openStore opens .gitpulse/history.json. If .gitpulse can't be written
+// (read-only mount, permissions) it warns and falls back to an in-memory
+// store, so commits still happen but aren't recorded past this run — unless
+// require_persistent_history is set, in which case that's an error.
+func openStore(cfg *config.Config, logger *ui.Logger) (*store.Store, error) {
+	dir := filepath.Join(cfg.WatchPath, ".gitpulse")
+	if err := store.CheckWritable(dir); err != nil {
+		if cfg.RequirePersistentHistory {
+			return nil, fmt.Errorf("commit history can't be written (require_persistent_history is set): %w", err)
+		}
+		logger.Warn("Commit history can't be written, keeping it in memory for this run", "dir", dir, "err", err)
+		return store.NewInMemory(), nil
+	}
+	return store.New(filepath.Join(dir, "history.json"))
 }
 
 // Run starts the main engine loop. Buffers changes from the watcher.
 func (e *Engine) Run() {
+	e.ReconcileOutsideCommits()
+	e.ReconcilePushState()
+	e.SyncSnooze()
+	if e.cfg.FlushOnBranchSwitch {
+		e.watchBranchSwitch()
+	}
+
 	if err := e.watcher.Start(); err != nil {
 		e.logger.Error("Failed to start watcher", err)
 		return
 	}
+	if e.cfg.SyncExistingChangesOnStart {
+		e.scheduleStartupSync()
+	}
 
 	e.logger.Info("Watching for changes...", "safety_timer", fmt.Sprintf("%ds", e.cfg.DebounceSeconds))
 	e.logger.Info("Run `gitpulse push` in another terminal to commit & push")
@@ -88,7 +249,9 @@ func (e *Engine) Run() {
 	for {
 		select {
 		case changeset := <-e.watcher.Events():
-			e.bufferChanges(changeset)
+			e.bufferChanges(e.toRepoPaths(changeset))
+		case <-e.watcher.HeadChanges():
+			e.handleHeadChange()
 		case <-e.done:
 			return
 		}
@@ -96,11 +259,28 @@ func (e *Engine) Run() {
 }
 
 // bufferChanges adds new file changes to pending and resets the safety timer.
+// Changes matching commit_immediately_patterns are committed right away
+// instead.
 func (e *Engine) bufferChanges(changeset watcher.ChangeSet) {
+	if immediate, rest := e.splitImmediate(changeset.Files); len(immediate) > 0 && e.commitImmediately(immediate) {
+		if len(rest) == 0 {
+			return
+		}
+		changeset.Files = rest
+	}
+	e.addPending(changeset)
+}
+
+// addPending adds changes to pending and resets the safety timer, without
+// the commit_immediately_patterns check of bufferChanges.
+func (e *Engine) addPending(changeset watcher.ChangeSet) {
 	e.mu.Lock()
 	e.pending = append(e.pending, changeset.Files...)
 	count := len(e.pending)
+	snapshot := append([]watcher.FileChange(nil), e.pending...)
 	e.mu.Unlock()
+	e.emit(Event{Type: EventPending, Pending: snapshot})
+	e.updatePlan()
 
 	e.logger.Info("Changes buffered", "new", len(changeset.Files), "total_pending", count)
 
@@ -108,6 +288,45 @@ func (e *Engine) bufferChanges(changeset watcher.ChangeSet) {
 	e.resetSafetyTimer()
 }
 
+// Submit buffers a changeset exactly as if the watcher had emitted it (paths
+// relative to the watch dir), so tests and harnesses can feed synthetic
+// changes and then call Flush without a live filesystem watcher. Changes
+// without a Time are stamped with now.
+func (e *Engine) Submit(changeset watcher.ChangeSet) {
+	now := time.Now()
+	files := make([]watcher.FileChange, len(changeset.Files))
+	for i, fc := range changeset.Files {
+		if fc.Time.IsZero() {
+			fc.Time = now
+		}
+		files[i] = fc
+	}
+	changeset.Files = files
+	e.bufferChanges(e.toRepoPaths(changeset))
+}
+
+// toRepoPaths rewrites watcher paths (relative to the watch dir) into the
+// engine's canonical path space, relative to the git repo root, so they can
+// be handed to git as-is when watch_path is a subdirectory of the repo.
+func (e *Engine) toRepoPaths(changeset watcher.ChangeSet) watcher.ChangeSet {
+	watchAbs, err := filepath.Abs(e.cfg.WatchPath)
+	if err != nil {
+		return changeset
+	}
+	prefix, err := filepath.Rel(e.git.Root(), watchAbs)
+	if err != nil || prefix == "." {
+		return changeset
+	}
+
+	files := make([]watcher.FileChange, len(changeset.Files))
+	for i, fc := range changeset.Files {
+		fc.Path = filepath.ToSlash(filepath.Join(prefix, fc.Path))
+		files[i] = fc
+	}
+	changeset.Files = files
+	return changeset
+}
+
 // resetSafetyTimer resets (or starts) the safety timer that auto-flushes.
 func (e *Engine) resetSafetyTimer() {
 	e.timerMu.Lock()
@@ -117,7 +336,13 @@ func (e *Engine) resetSafetyTimer() {
 		e.safetyTimer.Stop()
 	}
 
+	if time.Now().Before(e.snoozeUntil) {
+		e.emit(Event{Type: EventTimer})
+		return // re-armed by Resume
+	}
+
 	delay := time.Duration(e.cfg.DebounceSeconds) * time.Second
+	e.emit(Event{Type: EventTimer, Deadline: time.Now().Add(delay)})
 	e.safetyTimer = time.AfterFunc(delay, func() {
 		e.mu.Lock()
 		hasPending := len(e.pending) > 0
@@ -133,6 +358,37 @@ func (e *Engine) resetSafetyTimer() {
 // Flush processes all buffered changes through the full pipeline.
 // Called by `gitpulse push` (via SIGUSR1) or by the safety timer.
 func (e *Engine) Flush() {
+	e.flush(false)
+}
+
+// flush is Flush; bypassCap ignores max_commits_per_hour (manual pushes).
+// If another flush is running, it waits for the next one, which starts as
+// soon as the running one is done and also takes the changes buffered since.
+func (e *Engine) flush(bypassCap bool) {
+	if queued := e.claimFlush(bypassCap); queued != nil {
+		<-queued.done
+		return
+	}
+	defer e.releaseFlush()
+	e.runFlush(bypassCap)
+}
+
+// runFlush is one run of the pipeline over everything pending. Only called
+// while holding the flush queue (see claimFlush).
+func (e *Engine) runFlush(bypassCap bool) {
+	if until, ok := e.SnoozedUntil(); ok {
+		e.logger.Info("Snoozed — keeping changes buffered (run `gitpulse resume` to flush now)",
+			"until", until.Format("15:04"), "pending", e.PendingCount())
+		return
+	}
+	// Committing mid-rebase/merge would interfere with it
+	if e.PendingCount() > 0 && e.holdForRepoOperation() {
+		return
+	}
+	if !bypassCap && e.PendingCount() > 0 && e.holdForCommitCap() {
+		return
+	}
+
 	// Grab and clear pending changes
 	e.mu.Lock()
 	if len(e.pending) == 0 {
@@ -145,180 +401,1485 @@ func (e *Engine) Flush() {
 	e.pending = nil
 	e.mu.Unlock()
 
-	// Stop safety timer since we're flushing now
-	e.timerMu.Lock()
-	if e.safetyTimer != nil {
-		e.safetyTimer.Stop()
+	if e.missingAPIKey {
+		e.logger.Warn("No API key — committing with heuristic messages and no review")
+	}
+
+	e.emit(Event{Type: EventFlushStart})
+	e.emit(Event{Type: EventPending})
+	e.updatePlan()
+	defer e.emit(Event{Type: EventFlushDone})
+
+	// Stop safety timer since we're flushing now
+	e.timerMu.Lock()
+	if e.safetyTimer != nil {
+		e.safetyTimer.Stop()
+	}
+	e.timerMu.Unlock()
+	e.emit(Event{Type: EventTimer})
+
+	batches := [][]watcher.FileChange{files}
+	if e.cfg.SplitByTimeGapSeconds > 0 {
+		batches = splitByTimeGap(files, time.Duration(e.cfg.SplitByTimeGapSeconds)*time.Second)
+		if len(batches) > 1 {
+			e.logger.Info("Split pending changes by time gaps", "batches", len(batches))
+		}
+	}
+
+	for i, batch := range batches {
+		// The cap can fill up partway through a split flush
+		if i > 0 && !bypassCap && e.holdForCommitCap() {
+			e.requeue(batches[i:])
+			break
+		}
+		changeset := watcher.ChangeSet{
+			Files:     batch,
+			Timestamp: time.Now(),
+		}
+		e.processChanges(changeset)
+	}
+	// Move the last seen HEAD past these commits, importing any made by hand
+	// in the meantime
+	e.ReconcileOutsideCommits()
+}
+
+// splitByTimeGap divides chronologically buffered changes into batches
+// wherever consecutive events are more than gap apart. A path that changed in
+// several batches is kept only in its latest one, since staging captures the
+// file's final content anyway.
+func splitByTimeGap(files []watcher.FileChange, gap time.Duration) [][]watcher.FileChange {
+	var batches [][]watcher.FileChange
+	var current []watcher.FileChange
+	for i, fc := range files {
+		if i > 0 && !fc.Time.IsZero() && !files[i-1].Time.IsZero() && fc.Time.Sub(files[i-1].Time) > gap {
+			batches = append(batches, current)
+			current = nil
+		}
+		current = append(current, fc)
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	lastBatch := make(map[string]int)
+	for b, batch := range batches {
+		for _, fc := range batch {
+			lastBatch[fc.Path] = b
+		}
+	}
+
+	var out [][]watcher.FileChange
+	for b, batch := range batches {
+		var kept []watcher.FileChange
+		for _, fc := range batch {
+			if lastBatch[fc.Path] == b {
+				kept = append(kept, fc)
+			}
+		}
+		if len(kept) > 0 {
+			out = append(out, kept)
+		}
+	}
+	return out
+}
+
+// Snooze stops flushing (including the safety timer) until the given time;
+// changes keep buffering. Flushing resumes automatically when it expires.
+func (e *Engine) Snooze(until time.Time) {
+	e.timerMu.Lock()
+	e.snoozeUntil = until
+	if e.safetyTimer != nil {
+		e.safetyTimer.Stop()
+	}
+	if e.snoozeTimer != nil {
+		e.snoozeTimer.Stop()
+	}
+	e.snoozeTimer = time.AfterFunc(time.Until(until), e.Resume)
+	e.timerMu.Unlock()
+	e.emit(Event{Type: EventSnooze, Deadline: until})
+	e.emit(Event{Type: EventTimer})
+
+	e.logger.Info("Snoozed — changes will buffer but not commit", "until", until.Format("15:04"))
+}
+
+// Resume ends a snooze early (or on expiry) and re-arms the safety timer if
+// changes piled up meanwhile.
+func (e *Engine) Resume() {
+	e.timerMu.Lock()
+	wasSnoozed := !e.snoozeUntil.IsZero()
+	e.snoozeUntil = time.Time{}
+	if e.snoozeTimer != nil {
+		e.snoozeTimer.Stop()
+		e.snoozeTimer = nil
+	}
+	e.timerMu.Unlock()
+
+	if !wasSnoozed {
+		return
+	}
+	e.emit(Event{Type: EventSnooze})
+	e.logger.Info("Snooze over — resuming auto-commits")
+	if e.PendingCount() > 0 {
+		e.resetSafetyTimer()
+	}
+}
+
+// SnoozedUntil returns when the current snooze ends, if one is active.
+func (e *Engine) SnoozedUntil() (time.Time, bool) {
+	e.timerMu.Lock()
+	defer e.timerMu.Unlock()
+	if time.Now().Before(e.snoozeUntil) {
+		return e.snoozeUntil, true
+	}
+	return time.Time{}, false
+}
+
+// SyncSnooze applies the snooze state that `gitpulse snooze`/`gitpulse
+// resume` wrote to .gitpulse/state.json. Called at startup and when the
+// daemon is signalled.
+func (e *Engine) SyncSnooze() {
+	state, err := store.LoadState(filepath.Join(e.cfg.WatchPath, ".gitpulse", "state.json"))
+	if err != nil {
+		e.logger.Warn("Could not read GitPulse state", "err", err)
+		return
+	}
+	if until, ok := state.Snoozed(); ok {
+		e.Snooze(until)
+	} else {
+		e.Resume()
+	}
+}
+
+// PendingCount returns the number of buffered file changes.
+func (e *Engine) PendingCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.pending)
+}
+
+// Stop gracefully shuts down the engine.
+func (e *Engine) Stop() {
+	e.timerMu.Lock()
+	if e.safetyTimer != nil {
+		e.safetyTimer.Stop()
+	}
+	if e.snoozeTimer != nil {
+		e.snoozeTimer.Stop()
+	}
+	if e.capTimer != nil {
+		e.capTimer.Stop()
+	}
+	if e.busyTimer != nil {
+		e.busyTimer.Stop()
+	}
+	e.timerMu.Unlock()
+
+	// Let background reviews record their findings
+	e.asyncReviews.Wait()
+
+	e.watcher.Stop()
+	e.clearPlan()
+	close(e.done)
+	e.closeSubscribers()
+}
+
+// processChanges runs the full pipeline: group -> AI -> stage -> commit -> push.
+func (e *Engine) processChanges(changeset watcher.ChangeSet) {
+	e.logger.Info("Processing changes", "files", len(changeset.Files))
+
+	for _, fc := range changeset.Files {
+		e.logger.Info("  file", "path", fc.Path, "type", fc.Type)
+	}
+
+	// Git tracks files, not directories
+	changeset.Files = e.handleDirectories(changeset.Files)
+	if len(changeset.Files) == 0 {
+		e.logger.Info("Only directories changed, nothing to commit")
+		return
+	}
+
+	// Gradual adoption: leave files without the opt_in_marker alone
+	if e.cfg.OptInMarker != "" {
+		changeset.Files = e.filterOptedIn(changeset.Files)
+		if len(changeset.Files) == 0 {
+			e.logger.Info("No changed files contain the opt-in marker, nothing to commit", "marker", e.cfg.OptInMarker)
+			return
+		}
+	}
+
+	// Never commit a half-resolved merge
+	changeset.Files = e.dropConflicted(changeset.Files)
+	if len(changeset.Files) == 0 {
+		e.logger.Info("Only conflicted files changed, nothing to commit")
+		return
+	}
+
+	// Build artifacts added by mistake bloat the repo for good
+	changeset.Files = e.dropLargeBinaries(changeset.Files)
+	if len(changeset.Files) == 0 {
+		e.logger.Info("Only skipped large binaries changed, nothing to commit")
+		return
+	}
+
+	// Catch a moved remote now rather than as a rejected push later
+	e.checkBehind()
+
+	var commitHashes []string
+
+	// 0. Submodule pointer updates are committed on their own; files inside
+	// a submodule are left to its own repo
+	changeset.Files, commitHashes = e.commitSubmodules(changeset.Files)
+	if len(changeset.Files) == 0 {
+		e.pushCommits(commitHashes)
+		return
+	}
+
+	// 0.1 Don't silently throw away files the user staged by hand
+	staged, err := e.git.StagedFiles()
+	if err != nil {
+		e.logger.Warn("Could not check for manually staged files", "err", err)
+	} else if len(staged) > 0 {
+		if e.cfg.PreserveManualStaging {
+			if hash := e.commitManualStaging(staged, changeset.Files); hash != "" {
+				commitHashes = append(commitHashes, hash)
+				changeset.Files = e.dropCommittedFiles(changeset.Files)
+				if len(changeset.Files) == 0 {
+					e.pushCommits(commitHashes)
+					return
+				}
+			}
+		} else {
+			e.logger.Info("Leaving manually staged changes staged — GitPulse commits only its own files (set preserve_manual_staging to commit them too)",
+				"files", strings.Join(staged, ", "))
+		}
+	}
+
+	// 1. Heuristic grouping
+	groups := grouper.PreGroupWithOptions(changeset, e.groupOptions())
+	groups = grouper.ApplyGenerated(groups, e.generatedRules())
+	groups, conflicts := grouper.ApplyOverrides(groups, e.groupOverrides())
+	for _, c := range conflicts {
+		e.logger.Warn("Conflicting grouping_overrides", "detail", c)
+	}
+	e.logger.Info("Pre-grouped files", "groups", len(groups))
+
+	// 2. Get diffs (against one HEAD snapshot for the whole flush) and
+	// suggest each group a commit scope
+	e.git.BeginDiffSession()
+	diffs := e.fetchDiffs(groups)
+	groups = separateModeOnly(groups, diffs)
+	for i := range groups {
+		groups[i].Scope = grouper.ResolveScope(e.git.Root(), groups[i].Files)
+	}
+
+	// 3. AI refine + commit messages (and, for small flushes with
+	// ai.combine_refine_and_review, the review in the same call)
+	refined, grouping, prefetched := e.refineAndReview(groups)
+	e.logGroups(refined)
+
+	// 3.1 Let the user reject an AI grouping that merged unrelated files
+	if e.Interactive && e.cfg.ConfirmGrouping && grouping == store.GroupingAI {
+		refined, grouping = e.confirmGrouping(groups, refined)
+	}
+
+	// 3.2 Flag oversized groups (large_commit_lines) and offer to split them
+	refined = e.checkLargeGroups(refined)
+
+	// 3.5 AI Code Review — hold push if blockers found
+	// Track review data for store records
+	var reviewRecord *store.ReviewRecord
+	coverage := skippedReview("ai.code_review is off")
+
+	// Offline mode has no reviewer, so skip rather than report a vacuous pass
+	if e.cfg.AI.CodeReview && e.cfg.AI.Provider == ai.ProviderNone {
+		coverage = skippedReview("offline (ai.provider: none)")
+	} else if e.cfg.AI.CodeReview {
+		if reason := e.reviewSkipReason(refined); reason != "" {
+			e.logger.Info("Skipping AI review", "reason", reason)
+			coverage = skippedReview(reason)
+		} else if e.cfg.AI.ReviewMode == config.ReviewModeAsync {
+			coverage = reviewCoverage{mode: store.ReviewModeAsync}
+			e.logger.Info("Committing now — the AI review runs in the background (ai.review_mode: async)")
+		} else if e.Interactive {
+			var held []grouper.FileGroup
+			refined, reviewRecord, held = e.reviewLoopWithRecord(refined, prefetched, diffs)
+			coverage = reviewCoverage{mode: store.ReviewModeBlocking}
+			if reviewRecord == nil {
+				coverage = skippedReview("AI review failed")
+			}
+			if len(held) > 0 {
+				e.holdBack(held, changeset.Files)
+			}
+			if reviewRecord != nil && reviewRecord.Action == "abort" {
+				return // everything went back to pending; nothing to commit or push
+			}
+		} else {
+			// Non-interactive (safety timer): review but only log, don't block
+			reviewResult, err := prefetched, error(nil)
+			if reviewResult == nil {
+				reviewResult, err = e.reviewAI.ReviewCode(refined)
+			}
+			if err != nil {
+				e.logger.Warn("AI review failed, proceeding without review", "err", err)
+				coverage = skippedReview("AI review failed")
+			} else {
+				coverage = reviewCoverage{mode: store.ReviewModeNonBlocking}
+				reviewResult = e.filterDismissed(e.filterSuppressed(reviewResult))
+				reviewRecord = &store.ReviewRecord{
+					Findings:    convertFindingsForStore(reviewResult.Findings),
+					HasBlockers: reviewResult.HasBlockers,
+				}
+				if reviewResult.HasBlockers {
+					e.emit(Event{Type: EventReviewBlocked, Findings: reviewResult.Findings})
+					e.logger.Warn("AI review found blockers but running non-interactively, proceeding anyway",
+						"issues", len(reviewResult.Findings))
+					e.logger.ReviewFindings(reviewResult.Findings)
+				} else if len(reviewResult.Findings) > 0 {
+					e.logger.Info("AI review passed with info-only findings", "issues", len(reviewResult.Findings))
+					e.logger.ReviewFindings(reviewResult.Findings)
+				} else {
+					e.logger.Info("AI review passed — no issues found")
+				}
+			}
+		}
+	}
+
+	// The groups that became commits, with their hashes, for an async review
+	var committed []grouper.FileGroup
+	var committedHashes []string
+
+	// Fold the group that touches the same files into the previous commit when
+	// it's recent and unpushed (amend_window_seconds). Must happen before any
+	// new commit in this flush moves HEAD.
+	if last, pushed := e.amendTarget(); last != nil {
+		if i := overlappingGroup(refined, last); i >= 0 && e.mayAmend(last, pushed) {
+			if hash := e.amendGroup(refined[i], last, reviewRecord, coverage, changeset.Files); hash != "" {
+				if pushed {
+					e.needForcePush()
+				}
+				commitHashes = append(commitHashes, hash)
+				committed = append(committed, refined[i])
+				committedHashes = append(committedHashes, hash)
+				refined = append(refined[:i:i], refined[i+1:]...)
+			}
+		}
+	}
+
+	// 4. Commit each group's files only; anything else staged (by hand, or
+	// outside the watch root) stays staged and out of GitPulse's commits
+	for _, g := range refined {
+		// Build enriched file changes from diffs
+		fileChanges := parseDiffStats(g.Diffs, g.Files)
+		stampChangeTimes(fileChanges, changeset.Files)
+
+		message := e.withDiffStat(e.formatMessage(g.CommitMessage), fileChanges)
+		message += e.commitTrailers(reviewRecord, g.Model)
+
+		hash, err := e.git.CommitFilesAt(g.Files, message, e.authorTime(g.Files, changeset.Files))
+		if hash != "" && git.PartiallyStaged(err) {
+			e.logger.Warn("Some files could not be staged, committed the rest", "err", err)
+			err = nil
+		}
+		var stageErr *git.StageError
+		if errors.As(err, &stageErr) {
+			e.logger.Error("Failed to stage files", err, "files", g.Files)
+			continue
+		}
+		if errors.Is(err, git.ErrNothingToCommit) {
+			e.logger.Info("Nothing to commit for group — files already match HEAD", "files", strings.Join(g.Files, ", "))
+			continue
+		}
+		if err != nil {
+			e.logger.Error("Failed to commit", err)
+			continue
+		}
+
+		e.logger.CommitSuccess(hash, g.CommitMessage)
+		e.emit(Event{Type: EventCommit, Hash: hash, Message: message, Files: g.Files})
+		commitHashes = append(commitHashes, hash)
+		committed = append(committed, g)
+		committedHashes = append(committedHashes, hash)
+
+		record := store.CommitRecord{
+			Hash:            hash,
+			Message:         message,
+			Files:           e.commitStats(hash, fileChanges),
+			GroupReason:     g.Reason,
+			HeuristicReason: heuristicReason(groups, g.Files),
+			AIGenerated:     true,
+			Model:           g.Model,
+			Review:          reviewRecord,
+			LargeCommit:     e.isLarge(g),
+			LocalOnly:       e.cfg.PushMode == config.PushModeNever,
+			Grouping:        grouping,
+		}
+		if grouping != store.GroupingHeuristic {
+			record.AIReason = g.Reason
+		}
+		coverage.apply(&record)
+
+		if err := e.store.Save(record); err != nil {
+			e.logger.Warn("Failed to save commit record", "err", err)
+		}
+	}
+
+	// 5. Push and mark records as pushed
+	e.pushCommits(commitHashes)
+
+	// 6. ai.review_mode: async — review what was just committed
+	if coverage.mode == store.ReviewModeAsync && len(committed) > 0 {
+		e.reviewInBackground(committed, committedHashes, prefetched)
+	}
+}
+
+// heuristicReason returns the reasons of the heuristic groups files came
+// from, so a commit's record keeps them even after the AI regrouped.
+func heuristicReason(heuristic []grouper.FileGroup, files []string) string {
+	var reasons []string
+	for _, g := range heuristic {
+		for _, f := range g.Files {
+			if containsString(files, f) {
+				if !containsString(reasons, g.Reason) {
+					reasons = append(reasons, g.Reason)
+				}
+				break
+			}
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// groupingSplitHint is sent with a re-run of the AI refinement when the user
+// rejects a grouping as too coarse.
+const groupingSplitHint = "The previous grouping merged unrelated changes. Split more aggressively: " +
+	"only keep files in the same group when they are part of one logical change, " +
+	"and when in doubt give a file its own group."
+
+// refineGroups asks the AI to regroup the heuristic groups and write commit
+// messages (with an optional extra instruction), then makes sure every file
+// is still covered exactly once, re-applies grouping_overrides and fills in
+// any missing messages. Returns the groups and which grouping they came from.
+func (e *Engine) refineGroups(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, string) {
+	// RefineAndCommit may fill in messages on its input; keep the caller's copy clean
+	input := append([]grouper.FileGroup(nil), groups...)
+
+	grouping := store.GroupingAI
+	if hint != "" {
+		grouping = store.GroupingAISplit
+	}
+	if e.cfg.AI.Provider == ai.ProviderNone {
+		grouping = store.GroupingHeuristic
+	}
+
+	refined, err := e.ai.RefineWithHint(input, hint)
+	if err != nil {
+		e.logger.Warn("AI refinement failed, using heuristic groups", "err", err)
+		grouping = store.GroupingHeuristic
+		refined = input
+		for i := range refined {
+			if refined[i].CommitMessage == "" {
+				refined[i].CommitMessage = "chore: auto-commit changes"
+			}
+		}
+	} else if e.cfg.RejectDegenerateGrouping && degenerateGrouping(groups, refined) {
+		refined, grouping = e.regroupDegenerate(groups, hint)
+	}
+
+	return e.finishRefine(groups, refined), grouping
+}
+
+// regroupDegenerate handles an AI grouping that lumped everything into one
+// generic commit: unless that already was a re-run asking to split, the AI
+// is asked once more to keep groups focused; if it still won't, the
+// heuristic groups are used, with their messages generated one by one.
+func (e *Engine) regroupDegenerate(groups []grouper.FileGroup, hint string) ([]grouper.FileGroup, string) {
+	e.logger.Warn("AI put every file in one generic commit (reject_degenerate_grouping)", "heuristic_groups", len(groups))
+	if hint == "" {
+		input := append([]grouper.FileGroup(nil), groups...)
+		refined, err := e.ai.RefineWithHint(input, groupingSplitHint)
+		if err == nil && !degenerateGrouping(groups, refined) {
+			e.logger.Info("AI regrouped after being asked to keep groups focused", "groups", len(refined))
+			return refined, store.GroupingAISplit
+		}
+	}
+	e.logger.Warn("Using the heuristic groups instead", "groups", len(groups))
+	heuristic := append([]grouper.FileGroup(nil), groups...)
+	for i := range heuristic {
+		heuristic[i].CommitMessage = ""
+	}
+	return heuristic, store.GroupingHeuristic
+}
+
+// combineMaxLines is the largest flush (changed lines) that
+// ai.combine_refine_and_review sends as one refine + review call; bigger
+// flushes keep the separate calls so neither answer gets squeezed.
+const combineMaxLines = 400
+
+// refineAndReview is refineGroups that, when ai.combine_refine_and_review is
+// on and the flush is small and would be reviewed, also fetches the review in
+// the same API call. The review comes back non-nil only then; on any failure
+// of the combined call it falls back to refineGroups and a nil review, so the
+// caller reviews separately as usual. With grouping_strategy type or hybrid
+// it groups by change type instead (see groupByType). Whitespace-only and
+// mode-only groups skip the AI and are committed last (see
+// splitWhitespaceOnly and splitModeOnly).
+func (e *Engine) refineAndReview(groups []grouper.FileGroup) ([]grouper.FileGroup, string, *ai.ReviewResult) {
+	modes, rest := e.splitModeOnly(groups)
+	formatting, rest := e.splitWhitespaceOnly(rest)
+	if canned := append(formatting, modes...); len(canned) > 0 {
+		if len(rest) == 0 {
+			return canned, store.GroupingHeuristic, nil
+		}
+		refined, grouping, review := e.refineAndReview(rest)
+		return append(refined, canned...), grouping, review
+	}
+	if e.cfg.GroupingStrategy != config.GroupingStrategyDirectory {
+		if typed, grouping, ok := e.groupByType(groups); ok {
+			return typed, grouping, nil
+		}
+	}
+	if !e.combineEligible(groups) {
+		refined, grouping := e.refineGroups(groups, "")
+		return refined, grouping, nil
+	}
+
+	input := append([]grouper.FileGroup(nil), groups...)
+	refined, review, err := e.ai.RefineAndReview(input)
+	if err != nil {
+		e.logger.Warn("Combined refine + review failed, falling back to separate calls", "err", err)
+		refined, grouping := e.refineGroups(groups, "")
+		return refined, grouping, nil
+	}
+	if e.cfg.RejectDegenerateGrouping && degenerateGrouping(groups, refined) {
+		// The review is redone separately to match the new groups
+		refined, grouping := e.regroupDegenerate(groups, "")
+		return e.finishRefine(groups, refined), grouping, nil
+	}
+	e.logger.Info("Refined and reviewed in one call", "groups", len(refined), "findings", len(review.Findings))
+	return e.finishRefine(groups, refined), store.GroupingAI, review
+}
+
+// combineEligible reports whether this flush's refine and review can share
+// one API call.
+func (e *Engine) combineEligible(groups []grouper.FileGroup) bool {
+	if !e.cfg.AI.CombineRefineAndReview || !e.cfg.AI.CodeReview || e.cfg.AI.Provider == ai.ProviderNone {
+		return false
+	}
+	// One call can only ask one model
+	if e.cfg.ReviewModel() != e.cfg.CommitModel() {
+		return false
+	}
+	if e.reviewSkipReason(groups) != "" {
+		return false
+	}
+	lines := 0
+	for _, g := range groups {
+		lines += diffLineCount(g.Diffs)
+	}
+	return lines <= combineMaxLines
+}
+
+// finishRefine makes sure the refined groups cover every file of groups
+// exactly once, re-applies the grouping rules and fills in missing messages.
+func (e *Engine) finishRefine(groups, refined []grouper.FileGroup) []grouper.FileGroup {
+	// Make sure the AI didn't drop or invent files
+	refined, missing, unknown := grouper.Reconcile(groups, refined)
+	if len(unknown) > 0 {
+		e.logger.Warn("AI grouping returned unknown files, dropping them", "files", strings.Join(unknown, ", "))
+	}
+	if len(missing) > 0 {
+		e.logger.Warn("AI grouping left out files, committing them separately", "files", strings.Join(missing, ", "))
+	}
+	// Re-apply generated_file_rules and grouping_overrides in case the AI
+	// regrouped those files; any group they change loses its message and is
+	// regenerated below
+	refined = grouper.ApplyGenerated(refined, e.generatedRules())
+	refined, _ = grouper.ApplyOverrides(refined, e.groupOverrides())
+	e.fillMessages(refined)
+	e.logModels(refined)
+	return refined
+}
+
+// fillMessages generates a commit message for every group that lacks one.
+func (e *Engine) fillMessages(groups []grouper.FileGroup) {
+	for i := range groups {
+		if groups[i].CommitMessage == "" {
+			msg, model, err := e.ai.GenerateCommitMessage(groups[i].Diffs, groups[i].Files)
+			if err != nil {
+				msg = "chore: auto-commit changes"
+			} else {
+				groups[i].Model = model
+			}
+			groups[i].CommitMessage = msg
+		}
+	}
+}
+
+// confirmGrouping shows the AI grouping and lets the user accept it, fall
+// back to the heuristic pre-groups, or re-run the AI with a hint to split
+// more. Returns the groups to commit and which grouping they are.
+func (e *Engine) confirmGrouping(heuristic, refined []grouper.FileGroup) ([]grouper.FileGroup, string) {
+	grouping := store.GroupingAI
+	for {
+		choice, err := e.logger.PromptGrouping()
+		if err != nil {
+			e.logger.Warn("Grouping prompt failed, keeping the AI grouping", "err", err)
+			return refined, grouping
+		}
+
+		switch choice {
+		case "heuristic":
+			groups := make([]grouper.FileGroup, len(heuristic))
+			for i, g := range heuristic {
+				g.CommitMessage, g.Model = "", ""
+				groups[i] = g
+			}
+			e.fillMessages(groups)
+			e.logger.Info("Using the heuristic grouping for this flush")
+			e.logGroups(groups)
+			return groups, store.GroupingHeuristic
+		case "split":
+			e.logger.Info("Re-running AI grouping, splitting more aggressively...")
+			refined, grouping = e.refineGroups(heuristic, groupingSplitHint)
+			e.logGroups(refined)
+			if grouping == store.GroupingHeuristic {
+				return refined, grouping // the AI failed; nothing left to confirm
+			}
+		default:
+			return refined, grouping
+		}
+	}
+}
+
+// logGroups prints the groups about to be committed.
+func (e *Engine) logGroups(groups []grouper.FileGroup) {
+	displays := make([]ui.GroupDisplay, len(groups))
+	for i, g := range groups {
+		displays[i] = ui.GroupDisplay{
+			Files:  strings.Join(g.Files, ", "),
+			Reason: g.Reason,
+		}
+	}
+	e.logger.GroupInfo(len(groups), displays)
+	e.emit(Event{Type: EventGroups, Groups: groups})
+}
+
+// amendTarget returns the previous GitPulse commit if it is still HEAD and
+// within amend_window_seconds, and whether a remote already has it;
+// otherwise nil.
+func (e *Engine) amendTarget() (*store.CommitRecord, bool) {
+	if e.cfg.AmendWindowSeconds <= 0 {
+		return nil, false
+	}
+
+	recent := e.store.Recent(1)
+	if len(recent) == 0 {
+		return nil, false
+	}
+	last := recent[0]
+	// Never rewrite a commit the user made by hand (import_outside_commits
+	// records them too)
+	if !last.AIGenerated {
+		return nil, false
+	}
+
+	window := time.Duration(e.cfg.AmendWindowSeconds) * time.Second
+	if time.Since(last.CreatedAt) > window {
+		return nil, false
+	}
+
+	head, err := e.git.HeadHash()
+	if err != nil || head != last.Hash {
+		return nil, false
+	}
+
+	// Ask the remote too, in case the store missed a push
+	pushed := last.Pushed
+	if !pushed {
+		if pushed, err = e.git.IsPushed(last.Hash); err != nil {
+			return nil, false
+		}
+	}
+	return &last, pushed
+}
+
+// mayAmend reports whether last may be amended. Rewriting a pushed commit
+// takes a force push, so it needs allow_force_push and is only done
+// interactively, after a warning, once the user confirms.
+func (e *Engine) mayAmend(last *store.CommitRecord, pushed bool) bool {
+	if !pushed {
+		return true
+	}
+	if !e.cfg.AllowForcePush {
+		e.logger.Info("Not amending the previous commit — it's already pushed and rewriting it would need a force push (allow_force_push is off)", "commit", last.Hash[:7])
+		return false
+	}
+	if !e.Interactive {
+		e.logger.Warn("Not amending a pushed commit without confirmation — committing the changes separately", "commit", last.Hash[:7])
+		return false
+	}
+
+	e.logger.Warn("The previous commit is already pushed; amending it rewrites shared history", "commit", last.Hash[:7])
+	ok, err := e.logger.ConfirmForcePush(last.Hash[:7], strings.Join(e.cfg.PushRemotes(), ", "))
+	if err != nil || !ok {
+		e.logger.Info("Keeping the pushed commit, committing the changes separately")
+		return false
+	}
+	return true
+}
+
+// needForcePush makes the next push a force push, to replace the pushed
+// commit an amend just rewrote.
+func (e *Engine) needForcePush() {
+	e.mu.Lock()
+	e.forcePush = true
+	e.mu.Unlock()
+	e.logger.Warn("Amended a pushed commit — the next push will force-push (with lease) to replace it")
+}
+
+// overlappingGroup returns the index of the first group sharing a file with
+// the record, or -1.
+func overlappingGroup(groups []grouper.FileGroup, record *store.CommitRecord) int {
+	prev := make(map[string]bool, len(record.Files))
+	for _, f := range record.Files {
+		prev[f.Path] = true
+	}
+	for i, g := range groups {
+		for _, f := range g.Files {
+			if prev[f] {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// amendGroup folds a group into the previous commit, regenerating the message
+// from the combined changes. Returns the new hash, or "" on failure.
+func (e *Engine) amendGroup(g grouper.FileGroup, last *store.CommitRecord, reviewRecord *store.ReviewRecord, coverage reviewCoverage, changes []watcher.FileChange) string {
+	files := append([]string(nil), g.Files...)
+	var combined strings.Builder
+	for _, f := range last.Files {
+		combined.WriteString(f.Diff)
+		if !containsString(files, f.Path) {
+			files = append(files, f.Path)
+		}
+	}
+	combined.WriteString(g.Diffs)
+
+	message, model, err := e.ai.GenerateCommitMessage(combined.String(), files)
+	if err != nil {
+		e.logger.Warn("AI commit message failed for amend, keeping group message", "err", err)
+		message = g.CommitMessage
+		model = g.Model
+	}
+	message = e.formatMessage(message)
+	footer := e.commitTrailers(reviewRecord, model)
+
+	hash, err := e.git.AmendLastCommit(g.Files, message+footer)
+	if err != nil {
+		e.logger.Error("Failed to amend previous commit", err)
+		return ""
+	}
+
+	diff, err := e.git.GetCommitDiff(hash)
+	if err != nil {
+		diff = combined.String()
+	}
+
+	fileChanges := e.commitStats(hash, parseDiffStats(diff, files))
+	// The diffstat covers the whole amended commit, which only git can tell
+	// us once it exists: reword it with no files to add the stat
+	if withStat := e.withDiffStat(message, fileChanges); withStat != message {
+		if reworded, err := e.git.AmendLastCommit(nil, withStat+footer); err != nil {
+			e.logger.Warn("Could not add diffstat to amended commit", "err", err)
+		} else {
+			hash, message = reworded, withStat
+		}
+	}
+	message += footer
+	e.logger.Info("Amended previous commit", "old", last.Hash[:7], "new", hash[:7], "msg", message)
+	e.emit(Event{Type: EventCommit, Hash: hash, Message: message, Files: files})
+
+	stampChangeTimes(fileChanges, changes)
+	// Keep when the amended commit's files were first touched
+	for i := range fileChanges {
+		for _, prev := range last.Files {
+			if prev.Path == fileChanges[i].Path && prev.FirstChangedAt != nil {
+				fileChanges[i].FirstChangedAt = prev.FirstChangedAt
+				if fileChanges[i].LastChangedAt == nil {
+					fileChanges[i].LastChangedAt = prev.LastChangedAt
+				}
+			}
+		}
+	}
+
+	record := store.CommitRecord{
+		Hash:        hash,
+		Message:     message,
+		Files:       fileChanges,
+		GroupReason: g.Reason,
+		AIGenerated: true,
+		Model:       model,
+		Review:      reviewRecord,
+		LocalOnly:   e.cfg.PushMode == config.PushModeNever,
+	}
+	coverage.apply(&record)
+	if err := e.store.Amend(last.Hash, record); err != nil {
+		e.logger.Warn("Failed to update amended commit record", "err", err)
+	}
+
+	return hash
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// pushCommits pushes after a flush (push_mode: auto only) and marks the given
+// commits as pushed.
+func (e *Engine) pushCommits(commitHashes []string) {
+	if len(commitHashes) == 0 {
+		return
+	}
+	e.recordCommits(len(commitHashes))
+	switch e.cfg.PushMode {
+	case config.PushModeManual:
+		e.logger.Info("Committed locally — run `gitpulse push` to push", "commits", len(commitHashes))
+		return
+	case config.PushModeNever:
+		return
+	}
+	e.push(commitHashes)
+}
+
+// PushNow flushes pending changes and pushes every commit GitPulse hasn't
+// pushed yet. This is what `gitpulse push` triggers; with push_mode: never it
+// only flushes. Being a manual push, it isn't held by max_commits_per_hour.
+func (e *Engine) PushNow() {
+	e.flush(true)
+	if e.cfg.PushMode == config.PushModeNever {
+		e.logger.Info("push_mode is never — commits stay local")
+		return
+	}
+
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, remote := range e.cfg.PushRemotes() {
+		for _, r := range e.store.GetUnpushedTo(remote) {
+			if !seen[r.Hash] {
+				seen[r.Hash] = true
+				hashes = append(hashes, r.Hash)
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return
+	}
+	e.push(hashes)
+}
+
+// push pushes the branch to each remote (remotes, or just remote) that
+// doesn't have the given commits yet and marks them pushed there. A failed
+// remote doesn't stop the others; its commits are retried on the next push.
+func (e *Engine) push(commitHashes []string) {
+
+	// Don't push again if the remote already has everything (e.g. a previous
+	// push succeeded but recording it in the store didn't)
+	e.ReconcilePushState()
+	var targets []string
+	for _, remote := range e.cfg.PushRemotes() {
+		for _, h := range commitHashes {
+			if r := e.store.GetByHash(h); r == nil || !r.PushedTo(remote) {
+				targets = append(targets, remote)
+				break
+			}
+		}
+	}
+	if len(targets) == 0 {
+		e.logger.Info("Commits are already on the remote, nothing to push")
+		return
+	}
+
+	if !e.pushAllowed() {
+		return
+	}
+
+	branch, err := e.git.TargetBranch()
+	if err != nil {
+		branch = e.cfg.Branch
+	}
+	e.mu.Lock()
+	force := e.forcePush
+	e.mu.Unlock()
+	failed := false
+	for _, remote := range targets {
+		if err := e.pushWithRetry(remote, force); err != nil {
+			failed = true
+			e.emit(Event{Type: EventPush, Remote: remote, Err: err})
+			switch {
+			case errors.Is(err, git.ErrNoRemote):
+				e.logger.Warn("No such remote — commits stay local (add it, or set push_mode: never)", "remote", remote)
+			case errors.Is(err, git.ErrAuthFailed):
+				e.logger.Error("Push rejected: authentication failed — check your credentials, then run `gitpulse push`", err, "remote", remote)
+			case errors.Is(err, git.ErrNonFastForward):
+				e.logger.Error("Push rejected: the remote has new commits — pull, then run `gitpulse push`", err, "remote", remote)
+			default:
+				e.logger.Error("Failed to push", err, "remote", remote)
+			}
+			continue
+		}
+		if force {
+			e.logger.Warn("Force-pushed over the amended commit", "remote", remote)
+		}
+		e.logger.PushSuccess(len(commitHashes), remote)
+		e.emit(Event{Type: EventPush, Remote: remote, Hashes: commitHashes})
+
+		if err := e.store.MarkPushed(commitHashes, remote, branch); err != nil {
+			e.logger.Warn("Failed to mark commits as pushed (will reconcile from the remote next run)", "remote", remote, "err", err)
+		}
+	}
+	if force && !failed {
+		e.mu.Lock()
+		e.forcePush = false
+		e.mu.Unlock()
+	}
+	// The push also carried any earlier commits whose push failed
+	e.ReconcilePushState()
+}
+
+// checkBehind fetches and warns when the target branch trails the remote.
+// Interactive runs offer to pull (rebase) first; otherwise it's only logged.
+// Skipped with push_mode: never, which doesn't touch the remote.
+func (e *Engine) checkBehind() {
+	if e.cfg.PushMode == config.PushModeNever {
+		return
+	}
+	if err := e.git.Fetch(); err != nil {
+		e.logger.Warn("Could not fetch from the remote, skipping staleness check", "err", err)
+		return
+	}
+	behind, n, err := e.git.IsBehind()
+	if err != nil {
+		e.logger.Warn("Could not compare with the remote branch", "err", err)
+		return
+	}
+	if !behind {
+		return
+	}
+
+	branch, err := e.git.TargetBranch()
+	if err != nil {
+		branch = e.cfg.Branch
+	}
+	target := e.cfg.PushRemotes()[0] + "/" + branch
+	if !e.Interactive {
+		e.logger.Warn("Local branch is behind the remote — the push may be rejected until you pull", "target", target, "commits", n)
+		return
+	}
+
+	pull, err := e.logger.PromptPullFirst(target, n)
+	if err != nil || !pull {
+		e.logger.Warn("Continuing without pulling — the push may be rejected", "target", target)
+		return
+	}
+	rewritten, err := e.git.Pull()
+	if errors.Is(err, git.ErrBranchMismatch) {
+		e.logger.Warn("Not pulling — the checked-out branch isn't the one GitPulse pushes to", "err", err)
+		return
+	}
+	if err != nil {
+		e.logger.Error("Failed to pull, continuing without it", err)
+		return
+	}
+	e.logger.Info("Pulled remote changes", "target", target, "commits", n)
+	// The rebase gave unpushed commits new hashes; follow them in the history
+	// so they can still be marked pushed (and aren't imported as outside commits)
+	if _, err := e.store.RemapHashes(rewritten); err != nil {
+		e.logger.Warn("Failed to record the rebased commits' new hashes", "err", err)
+	}
+}
+
+// pushAttempts is how many times a push is tried when it fails for a reason
+// that may be transient (e.g. a network error); pushRetryDelay is the base
+// backoff, doubled each attempt.
+const pushAttempts = 3
+
+var pushRetryDelay = 2 * time.Second
+
+// pushWithRetry pushes to remote, retrying only failures that aren't a
+// missing remote, bad credentials or a non-fast-forward — retrying those
+// can't help. force pushes with ForcePushTo.
+func (e *Engine) pushWithRetry(remote string, force bool) error {
+	pushTo := e.git.PushTo
+	if force {
+		pushTo = e.git.ForcePushTo
+	}
+	var err error
+	for attempt := 1; attempt <= pushAttempts; attempt++ {
+		err = pushTo(remote)
+		if err == nil || errors.Is(err, git.ErrNoRemote) || errors.Is(err, git.ErrAuthFailed) || errors.Is(err, git.ErrNonFastForward) {
+			return err
+		}
+		if attempt < pushAttempts {
+			delay := pushRetryDelay << (attempt - 1)
+			e.logger.Warn("Push failed, retrying", "remote", remote, "err", err, "attempt", attempt, "retry_in", delay)
+			time.Sleep(delay)
+		}
+	}
+	return err
+}
+
+// ReconcilePushState marks stored commits as pushed when the remote already
+// contains them, so a MarkPushed that failed (or a crash right after a push)
+// heals itself instead of the store claiming those commits are unpushed.
+// Returns how many records were corrected.
+func (e *Engine) ReconcilePushState() int {
+	if e.cfg.PushMode == config.PushModeNever {
+		return 0 // local-only: don't look at remote state at all
+	}
+
+	branch, err := e.git.TargetBranch()
+	if err != nil {
+		branch = e.cfg.Branch
+	}
+
+	corrected := 0
+	for _, remote := range e.cfg.PushRemotes() {
+		var onRemote []string
+		for _, r := range e.store.GetUnpushedTo(remote) {
+			pushed, err := e.git.IsPushedTo(r.Hash, remote)
+			if err != nil {
+				continue // e.g. the commit was amended away
+			}
+			if pushed {
+				onRemote = append(onRemote, r.Hash)
+			}
+		}
+		if len(onRemote) == 0 {
+			continue
+		}
+
+		if err := e.store.MarkPushed(onRemote, remote, branch); err != nil {
+			e.logger.Warn("Failed to record commits found on the remote", "remote", remote, "err", err)
+			continue
+		}
+		e.logger.Info("Reconciled push state from the remote", "remote", remote, "commits", len(onRemote))
+		corrected += len(onRemote)
+	}
+	return corrected
+}
+
+// pushAllowed asks for a one-time confirmation before GitPulse first pushes to
+// this repo, remembering a yes in .gitpulse/state.json. Non-interactive runs
+// never prompt; consent is implied by running GitPulse unattended.
+func (e *Engine) pushAllowed() bool {
+	if !e.Interactive {
+		return true
 	}
-	e.timerMu.Unlock()
 
-	changeset := watcher.ChangeSet{
-		Files:     files,
-		Timestamp: time.Now(),
+	statePath := filepath.Join(e.cfg.WatchPath, ".gitpulse", "state.json")
+	state, err := store.LoadState(statePath)
+	if err != nil {
+		e.logger.Warn("Could not read GitPulse state, asking before push", "err", err)
+	}
+	if state.PushConfirmed() {
+		return true
 	}
 
-	e.processChanges(changeset)
-}
+	branch, err := e.git.TargetBranch()
+	if err != nil {
+		branch = e.cfg.Branch
+	}
+	var targets []string
+	for _, remote := range e.cfg.PushRemotes() {
+		targets = append(targets, remote+"/"+branch)
+	}
+	target := strings.Join(targets, ", ")
 
-// PendingCount returns the number of buffered file changes.
-func (e *Engine) PendingCount() int {
-	e.mu.Lock()
-	defer e.mu.Unlock()
-	return len(e.pending)
+	ok, err := e.logger.ConfirmFirstPush(target)
+	if err != nil || !ok {
+		e.logger.Warn("Push skipped — commits stay local (set push_mode: never to stop pushing entirely)", "target", target)
+		return false
+	}
+	if err := state.ConfirmPush(target); err != nil {
+		e.logger.Warn("Could not save push confirmation, will ask again next time", "err", err)
+	}
+	return true
 }
 
-// Stop gracefully shuts down the engine.
-func (e *Engine) Stop() {
-	e.timerMu.Lock()
-	if e.safetyTimer != nil {
-		e.safetyTimer.Stop()
+// fetchDiffs (re)builds each group's combined diff and returns the diff text
+// of every file, keyed by path, so later rounds can re-fetch just the files
+// that changed (see reviewLoopWithRecord).
+func (e *Engine) fetchDiffs(groups []grouper.FileGroup) map[string]string {
+	var files []string
+	for _, g := range groups {
+		files = append(files, g.Files...)
 	}
-	e.timerMu.Unlock()
-
-	e.watcher.Stop()
-	close(e.done)
+	diffs := e.fetchFileDiffs(files)
+	assembleDiffs(groups, diffs)
+	return diffs
 }
 
-// processChanges runs the full pipeline: group -> AI -> stage -> commit -> push.
-func (e *Engine) processChanges(changeset watcher.ChangeSet) {
-	e.logger.Info("Processing changes", "files", len(changeset.Files))
+// fetchFileDiffs returns the diff text of each file, keyed by path.
+// GetFileDiff calls run on a bounded worker pool (diff_concurrency).
+func (e *Engine) fetchFileDiffs(files []string) map[string]string {
+	results := make([]string, len(files))
 
-	for _, fc := range changeset.Files {
-		e.logger.Info("  file", "path", fc.Path, "type", fc.Type)
+	workers := e.cfg.DiffConcurrency
+	if workers < 1 {
+		workers = 1
 	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
 
-	// 1. Heuristic grouping
-	groups := grouper.PreGroup(changeset)
-	e.logger.Info("Pre-grouped files", "groups", len(groups))
+	for i, f := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, f string) {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-	// 2. Get diffs
-	for i := range groups {
-		for _, f := range groups[i].Files {
 			d, err := e.git.GetFileDiff(f)
 			if err != nil {
 				d = fmt.Sprintf("--- /dev/null\n+++ b/%s\n(new or deleted file)", f)
 			}
-			groups[i].Diffs += d + "\n"
-		}
+			results[i] = e.diffText(f, d)
+		}(i, f)
 	}
+	wg.Wait()
 
-	// 3. AI refine + commit messages
-	refined, err := e.ai.RefineAndCommit(groups)
-	if err != nil {
-		e.logger.Warn("AI refinement failed, using heuristic groups", "err", err)
-		refined = groups
-		for i := range refined {
-			if refined[i].CommitMessage == "" {
-				refined[i].CommitMessage = "chore: auto-commit changes"
-			}
+	diffs := make(map[string]string, len(files))
+	for i, f := range files {
+		diffs[f] = results[i]
+	}
+	return diffs
+}
+
+// assembleDiffs sets each group's combined diff from the per-file diffs, in
+// file order.
+func assembleDiffs(groups []grouper.FileGroup, diffs map[string]string) {
+	for i := range groups {
+		var sb strings.Builder
+		for _, f := range groups[i].Files {
+			sb.WriteString(diffs[f] + "\n")
 		}
+		groups[i].Diffs = sb.String()
 	}
+}
 
-	// Log grouping results
-	displays := make([]ui.GroupDisplay, len(refined))
-	for i, g := range refined {
-		displays[i] = ui.GroupDisplay{
-			Files:  strings.Join(g.Files, ", "),
-			Reason: g.Reason,
+// logModels logs which AI model produced each group's commit message,
+// warning when a fallback model had to step in.
+func (e *Engine) logModels(groups []grouper.FileGroup) {
+	for i, g := range groups {
+		if g.Model == "" {
+			continue
+		}
+		if g.Model != e.cfg.CommitModel() {
+			e.logger.Warn("Commit message generated by fallback model", "group", i+1, "model", g.Model)
+		} else {
+			e.logger.Info("Commit message generated", "group", i+1, "model", g.Model)
 		}
 	}
-	e.logger.GroupInfo(len(refined), displays)
+}
 
-	// 3.5 AI Code Review — hold push if blockers found
-	// Track review data for store records
-	var reviewRecord *store.ReviewRecord
+// isLarge reports whether a group's diff exceeds large_commit_lines.
+func (e *Engine) isLarge(g grouper.FileGroup) bool {
+	return e.cfg.LargeCommitLines > 0 && diffLineCount(g.Diffs) > e.cfg.LargeCommitLines
+}
 
-	if e.cfg.AI.CodeReview {
-		if e.Interactive {
-			refined, reviewRecord = e.reviewLoopWithRecord(refined)
-		} else {
-			// Non-interactive (safety timer): review but only log, don't block
-			reviewResult, err := e.ai.ReviewCode(refined)
+// checkLargeGroups warns about groups over large_commit_lines and, in
+// interactive mode, offers to split each one by file or by directory.
+func (e *Engine) checkLargeGroups(groups []grouper.FileGroup) []grouper.FileGroup {
+	var out []grouper.FileGroup
+	for _, g := range groups {
+		if !e.isLarge(g) {
+			out = append(out, g)
+			continue
+		}
+
+		lines := diffLineCount(g.Diffs)
+		e.logger.Warn("Large commit — hard to review and the AI review may be unreliable",
+			"lines", lines, "limit", e.cfg.LargeCommitLines, "files", len(g.Files))
+
+		if !e.Interactive || len(g.Files) < 2 {
+			out = append(out, g)
+			continue
+		}
+
+		choice, err := e.logger.PromptLargeCommit(g.CommitMessage, lines)
+		if err != nil || choice == "keep" {
+			out = append(out, g)
+			continue
+		}
+
+		split := splitGroup(g, choice)
+		for i := range split {
+			msg, model, err := e.ai.GenerateCommitMessage(split[i].Diffs, split[i].Files)
 			if err != nil {
-				e.logger.Warn("AI review failed, proceeding without review", "err", err)
-			} else {
-				reviewRecord = &store.ReviewRecord{
-					Findings:    convertFindingsForStore(reviewResult.Findings),
-					HasBlockers: reviewResult.HasBlockers,
-				}
-				if reviewResult.HasBlockers {
-					e.logger.Warn("AI review found blockers but running non-interactively, proceeding anyway",
-						"issues", len(reviewResult.Findings))
-					e.logger.ReviewFindings(reviewResult.Findings)
-				} else if len(reviewResult.Findings) > 0 {
-					e.logger.Info("AI review passed with info-only findings", "issues", len(reviewResult.Findings))
-					e.logger.ReviewFindings(reviewResult.Findings)
-				} else {
-					e.logger.Info("AI review passed — no issues found")
-				}
+				msg = g.CommitMessage
+				model = g.Model
 			}
+			split[i].Model = model
+			split[i].CommitMessage = msg
 		}
+		e.logger.Info("Split large group", "into", len(split), "by", choice)
+		out = append(out, split...)
 	}
+	return out
+}
 
-	// 4. Reset staging, then stage + commit per group
-	if err := e.git.ResetStaging(); err != nil {
-		e.logger.Error("Failed to reset staging", err)
-		return
+// splitGroup breaks a group into one group per file ("file") or per
+// directory ("dir"), carrying each file's diff along.
+func splitGroup(g grouper.FileGroup, by string) []grouper.FileGroup {
+	fileDiffs := grouper.FileDiffs([]grouper.FileGroup{g})
+
+	var keys []string
+	byKey := make(map[string][]string)
+	for _, f := range g.Files {
+		key := f
+		if by == "dir" {
+			key = filepath.Dir(f)
+		}
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], f)
 	}
 
-	var commitHashes []string
-	for _, g := range refined {
-		if err := e.git.StageFiles(g.Files); err != nil {
-			e.logger.Error("Failed to stage files", err, "files", g.Files)
-			continue
+	groups := make([]grouper.FileGroup, 0, len(keys))
+	for _, key := range keys {
+		var diffs strings.Builder
+		for _, f := range byKey[key] {
+			diffs.WriteString(fileDiffs[f])
 		}
+		groups = append(groups, grouper.FileGroup{
+			Files:  byKey[key],
+			Reason: "split from large group: " + key,
+			Diffs:  diffs.String(),
+		})
+	}
+	return groups
+}
 
-		hash, err := e.git.Commit(g.CommitMessage)
-		if err != nil {
-			e.logger.Error("Failed to commit", err)
+// diffLineCount counts added and removed lines in a unified diff, skipping headers.
+func diffLineCount(diff string) int {
+	n := 0
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
 			continue
 		}
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			n++
+		}
+	}
+	return n
+}
 
-		e.logger.CommitSuccess(hash, g.CommitMessage)
-		commitHashes = append(commitHashes, hash)
+// reviewExempt lists extensions of docs/config files that don't warrant an
+// AI code review on their own.
+var reviewExempt = map[string]bool{
+	".md": true, ".rst": true, ".txt": true,
+	".yaml": true, ".yml": true, ".json": true, ".toml": true,
+}
 
-		// Build enriched file changes from diffs
-		fileChanges := parseDiffStats(g.Diffs, g.Files)
+// reviewCoverage is how a flush's AI review covered its commits, recorded
+// on each commit so unreviewed ones can be audited later.
+type reviewCoverage struct {
+	mode       string // one of the store.ReviewMode values
+	skipReason string // why the review was skipped, with store.ReviewModeSkipped
+}
 
-		record := store.CommitRecord{
-			Hash:        hash,
-			Message:     g.CommitMessage,
-			Files:       fileChanges,
-			GroupReason: g.Reason,
-			AIGenerated: true,
-			Review:      reviewRecord,
-		}
+// skippedReview is the coverage of commits that weren't reviewed for reason.
+func skippedReview(reason string) reviewCoverage {
+	return reviewCoverage{mode: store.ReviewModeSkipped, skipReason: reason}
+}
 
-		if err := e.store.Save(record); err != nil {
-			e.logger.Warn("Failed to save commit record", "err", err)
+// apply records the coverage on r.
+func (c reviewCoverage) apply(r *store.CommitRecord) {
+	r.ReviewMode = c.mode
+	r.ReviewSkipReason = c.skipReason
+}
+
+// reviewSkipReason returns why the AI review isn't worth running for groups
+// (fewer changed lines than review_min_lines, or docs/config only), or "" to
+// review as usual.
+func (e *Engine) reviewSkipReason(groups []grouper.FileGroup) string {
+	lines := 0
+	codeFiles := 0
+	for _, g := range groups {
+		lines += diffLineCount(g.Diffs)
+		for _, f := range g.Files {
+			if !reviewExempt[strings.ToLower(filepath.Ext(f))] {
+				codeFiles++
+			}
 		}
 	}
 
-	// 5. Push and mark records as pushed
-	if len(commitHashes) > 0 && e.cfg.AutoPush {
-		if err := e.git.Push(); err != nil {
-			e.logger.Error("Failed to push", err)
-			return
+	if codeFiles == 0 {
+		return "docs/config changes only"
+	}
+	if e.cfg.ReviewMinLines > 0 && lines < e.cfg.ReviewMinLines {
+		return fmt.Sprintf("only %d changed lines (review_min_lines: %d)", lines, e.cfg.ReviewMinLines)
+	}
+	return ""
+}
+
+// groupOptions translates grouping config into grouper options.
+func (e *Engine) groupOptions() grouper.Options {
+	opts := grouper.Options{RootDepth: e.cfg.GroupRootDepth}
+	for _, r := range e.cfg.GroupingRules {
+		opts.TypeRules = append(opts.TypeRules, grouper.TypeRule{
+			Name:     r.Name,
+			Patterns: r.Patterns,
+		})
+	}
+	return opts
+}
+
+// generatedRules converts generated_file_rules config into grouper rules.
+func (e *Engine) generatedRules() []grouper.GeneratedRule {
+	var rules []grouper.GeneratedRule
+	for _, r := range e.cfg.GeneratedFileRules {
+		rules = append(rules, grouper.GeneratedRule{Source: r.Source, Generated: r.Generated})
+	}
+	return rules
+}
+
+// groupOverrides converts grouping_overrides config into grouper overrides.
+func (e *Engine) groupOverrides() grouper.Overrides {
+	var o grouper.Overrides
+	for _, r := range e.cfg.GroupingOverrides.ForceSeparate {
+		o.Separate = append(o.Separate, grouper.TypeRule{Name: r.Name, Patterns: r.Patterns})
+	}
+	for _, r := range e.cfg.GroupingOverrides.ForceTogether {
+		o.Together = append(o.Together, grouper.TypeRule{Name: r.Name, Patterns: r.Patterns})
+	}
+	return o
+}
+
+// commitManualStaging commits whatever the user staged by hand as its own
+// commit, exactly as staged, before the pipeline resets the index.
+// Returns the commit hash, or "" if the commit failed.
+func (e *Engine) commitManualStaging(staged []string, changes []watcher.FileChange) string {
+	e.logger.Info("Preserving manually staged changes as their own commit", "files", strings.Join(staged, ", "))
+
+	diff, err := e.git.GetStagedDiff()
+	if err != nil {
+		e.logger.Warn("Could not diff manually staged changes", "err", err)
+	}
+
+	message, model, err := e.ai.GenerateCommitMessage(diff, staged)
+	if err != nil {
+		e.logger.Warn("AI commit message failed for staged changes, using fallback", "err", err)
+	}
+	fileChanges := parseDiffStats(diff, staged)
+	stampChangeTimes(fileChanges, changes)
+	message = e.withDiffStat(e.formatMessage(message), fileChanges) + e.commitTrailers(nil, model)
+
+	hash, err := e.git.Commit(message)
+	if errors.Is(err, git.ErrNothingToCommit) {
+		return ""
+	}
+	if err != nil {
+		e.logger.Error("Failed to commit manually staged changes", err)
+		return ""
+	}
+	e.logger.CommitSuccess(hash, message)
+	e.emit(Event{Type: EventCommit, Hash: hash, Message: message, Files: staged})
+
+	record := store.CommitRecord{
+		Hash:        hash,
+		Message:     message,
+		Files:       e.commitStats(hash, fileChanges),
+		GroupReason: "manually staged",
+		AIGenerated: true,
+		Model:       model,
+		LocalOnly:   e.cfg.PushMode == config.PushModeNever,
+	}
+	skippedReview("manually staged").apply(&record)
+	if err := e.store.Save(record); err != nil {
+		e.logger.Warn("Failed to save commit record", "err", err)
+	}
+
+	return hash
+}
+
+// filterOptedIn keeps only changes to files containing opt_in_marker. A file
+// that no longer exists counts as opted in if GitPulse committed it before,
+// so deleting a tracked file is still committed. The rest stay uncommitted
+// in the working tree.
+func (e *Engine) filterOptedIn(files []watcher.FileChange) []watcher.FileChange {
+	marker := []byte(e.cfg.OptInMarker)
+	var kept []watcher.FileChange
+	var skipped []string
+	for _, fc := range files {
+		data, err := os.ReadFile(filepath.Join(e.git.Root(), fc.Path))
+		switch {
+		case err == nil && bytes.Contains(data, marker):
+			kept = append(kept, fc)
+		case os.IsNotExist(err) && len(e.store.GetByFile(filepath.ToSlash(fc.Path))) > 0:
+			kept = append(kept, fc)
+		default:
+			skipped = append(skipped, fc.Path)
 		}
-		e.logger.PushSuccess(len(commitHashes), e.cfg.Remote)
+	}
+	if len(skipped) > 0 {
+		e.logger.Info("Skipping files without the opt-in marker", "marker", e.cfg.OptInMarker, "files", strings.Join(skipped, ", "))
+	}
+	return kept
+}
+
+// dropCommittedFiles removes changes that no longer differ from HEAD (e.g.
+// fully covered by the manual-staging commit) so they don't produce empty commits.
+func (e *Engine) dropCommittedFiles(files []watcher.FileChange) []watcher.FileChange {
+	changed, err := e.git.ChangedFiles()
+	if err != nil {
+		return files
+	}
 
-		if err := e.store.MarkPushed(commitHashes, e.cfg.Remote, e.cfg.Branch); err != nil {
-			e.logger.Warn("Failed to mark commits as pushed", "err", err)
+	var remaining []watcher.FileChange
+	for _, fc := range files {
+		if changed[filepath.ToSlash(fc.Path)] {
+			remaining = append(remaining, fc)
 		}
 	}
+	return remaining
 }
 
 // reviewLoopWithRecord runs the interactive review cycle and returns the final
-// review record for storage alongside the (possibly updated) groups.
-func (e *Engine) reviewLoopWithRecord(groups []grouper.FileGroup) ([]grouper.FileGroup, *store.ReviewRecord) {
+// review record for storage alongside the (possibly updated) groups. If the
+// fix rounds (ai.max_review_iterations) run out with blockers left, the user
+// picks: keep trying, continue (only the groups that passed are returned for
+// committing; the still-blocked ones come back as held), or abort (every
+// group comes back as held and the record's Action is "abort").
+// diffs holds each file's diff text from fetchDiffs; after a fix only the
+// files whose content changed are re-diffed and re-reviewed (see
+// reviewScoped).
+func (e *Engine) reviewLoopWithRecord(groups []grouper.FileGroup, first *ai.ReviewResult, diffs map[string]string) ([]grouper.FileGroup, *store.ReviewRecord, []grouper.FileGroup) {
 	var record *store.ReviewRecord
+	limit := e.maxReviewIterations()
+
+	hashes := e.fileHashes(groups)
+	var scope map[string]bool // nil until the first fix: review everything
+	var carried []ai.ReviewFinding
 
-	for iteration := 0; iteration < maxReviewIterations; iteration++ {
-		reviewResult, err := e.ai.ReviewCode(groups)
+	for iteration := 0; ; iteration++ {
+		reviewResult, err := first, error(nil)
+		if iteration > 0 || reviewResult == nil {
+			reviewResult, err = e.reviewScoped(groups, diffs, scope, carried)
+		}
 		if err != nil {
 			e.logger.Warn("AI review failed, proceeding without review", "err", err)
-			return groups, nil
+			return groups, nil, nil
 		}
+		reviewResult = e.filterDismissed(e.filterSuppressed(reviewResult))
 
 		record = &store.ReviewRecord{
 			Findings:    convertFindingsForStore(reviewResult.Findings),
@@ -327,7 +1888,7 @@ func (e *Engine) reviewLoopWithRecord(groups []grouper.FileGroup) ([]grouper.Fil
 
 		if len(reviewResult.Findings) == 0 {
 			e.logger.Info("AI review passed — no issues found")
-			return groups, record
+			return groups, record, nil
 		}
 
 		// Display findings
@@ -335,21 +1896,53 @@ func (e *Engine) reviewLoopWithRecord(groups []grouper.FileGroup) ([]grouper.Fil
 
 		if !reviewResult.HasBlockers {
 			e.logger.Info("All findings are info-only, proceeding with push")
-			return groups, record
+			return groups, record, nil
+		}
+		e.emit(Event{Type: EventReviewBlocked, Findings: reviewResult.Findings})
+
+		// Every fix round is used up and blockers remain: ask rather than
+		// silently pushing
+		if iteration == limit {
+			choice, err := e.logger.PromptReviewLimit(limit)
+			if err != nil {
+				e.logger.Warn("Review limit prompt failed, holding back blocked groups", "err", err)
+				choice = "continue"
+			}
+			switch choice {
+			case "retry":
+				limit += e.maxReviewIterations()
+				e.logger.Info("Allowing more fix rounds", "max_review_iterations", limit)
+			case "abort":
+				record.Action = "abort"
+				e.logger.Warn("Flush aborted — nothing committed")
+				return nil, record, groups
+			default:
+				return e.commitPassed(groups, record, reviewResult)
+			}
 		}
 
 		// Prompt user for action
 		action, err := e.handleReviewFindings(groups, reviewResult)
 		if err != nil {
 			e.logger.Warn("Review prompt failed, proceeding with push", "err", err)
-			return groups, record
+			return groups, record, nil
 		}
 
 		record.Action = action
 
 		if action == "continue" {
 			e.logger.Info("User chose to continue — proceeding with push")
-			return groups, record
+			return groups, record, nil
+		}
+
+		if action == "dismiss" {
+			e.logger.Info("Findings dismissed — proceeding with push")
+			return groups, record, nil
+		}
+
+		if action == "abort" {
+			e.logger.Warn("Flush aborted — nothing committed")
+			return nil, record, groups
 		}
 
 		// Track fixes applied
@@ -371,27 +1964,113 @@ func (e *Engine) reviewLoopWithRecord(groups []grouper.FileGroup) ([]grouper.Fil
 			})
 		}
 
-		// Re-fetch diffs after fix (manual or AI) for the next iteration
-		for i := range groups {
-			groups[i].Diffs = ""
-			for _, f := range groups[i].Files {
-				d, err := e.git.GetFileDiff(f)
-				if err != nil {
-					d = fmt.Sprintf("--- /dev/null\n+++ b/%s\n(new or deleted file)", f)
-				}
-				groups[i].Diffs += d + "\n"
+		// Re-fetch diffs after fix (manual or AI) for the next iteration, but
+		// only for files the fix actually changed
+		var changed map[string]bool
+		hashes, changed = e.changedFiles(groups, hashes, diffs)
+		for f, d := range e.fetchFileDiffs(sortedKeys(changed)) {
+			diffs[f] = d
+		}
+		assembleDiffs(groups, diffs)
+		scope = reviewScope(changed, reviewResult.Findings)
+		carried = outsideScope(reviewResult.Findings, scope)
+
+		e.logger.Info("Re-reviewing after fix...", "iteration", iteration+2, "files", len(scope))
+	}
+}
+
+// maxReviewIterations returns ai.max_review_iterations, or the default when unset.
+func (e *Engine) maxReviewIterations() int {
+	if e.cfg.AI.MaxReviewIterations > 0 {
+		return e.cfg.AI.MaxReviewIterations
+	}
+	return defaultMaxReviewIterations
+}
+
+// commitPassed returns the groups that passed review for committing and
+// holds back the ones still blocked, rather than pushing unfixed code along
+// with everything else.
+func (e *Engine) commitPassed(groups []grouper.FileGroup, record *store.ReviewRecord, last *ai.ReviewResult) ([]grouper.FileGroup, *store.ReviewRecord, []grouper.FileGroup) {
+	passed, held := splitBlocked(groups, last.Findings)
+	if len(held) == 0 {
+		e.logger.Warn("Max review iterations reached, proceeding with push")
+		return groups, record, nil
+	}
+	e.logger.Warn("Max review iterations reached, holding back groups that are still blocked",
+		"committing", len(passed), "held", len(held))
+
+	var passedFindings []ai.ReviewFinding
+	for _, f := range last.Findings {
+		if touchesGroups(f, passed) {
+			passedFindings = append(passedFindings, f)
+		}
+	}
+	record.Findings = convertFindingsForStore(passedFindings)
+	record.HasBlockers = false
+	return passed, record, held
+}
+
+// splitBlocked partitions groups into those with no error/warning findings
+// and those that still have one. A finding blocks every group containing its
+// file or one of its related locations.
+func splitBlocked(groups []grouper.FileGroup, findings []ai.ReviewFinding) (passed, blocked []grouper.FileGroup) {
+	for _, g := range groups {
+		isBlocked := false
+		for _, f := range findings {
+			if (f.Severity == ai.SeverityError || f.Severity == ai.SeverityWarning) &&
+				touchesGroups(f, []grouper.FileGroup{g}) {
+				isBlocked = true
+				break
+			}
+		}
+		if isBlocked {
+			blocked = append(blocked, g)
+		} else {
+			passed = append(passed, g)
+		}
+	}
+	return passed, blocked
+}
+
+// touchesGroups reports whether f touches a file in any of groups.
+func touchesGroups(f ai.ReviewFinding, groups []grouper.FileGroup) bool {
+	for _, g := range groups {
+		if containsString(g.Files, f.File) {
+			return true
+		}
+		for _, loc := range f.RelatedLocations {
+			if containsString(g.Files, loc.File) {
+				return true
 			}
 		}
+	}
+	return false
+}
 
-		e.logger.Info("Re-reviewing after fix...", "iteration", iteration+2)
+// holdBack puts the files of groups that weren't committed (still blocked, or
+// the flush was aborted) back into the pending buffer for the next flush.
+func (e *Engine) holdBack(groups []grouper.FileGroup, changes []watcher.FileChange) {
+	var files []string
+	for _, g := range groups {
+		files = append(files, g.Files...)
 	}
+	e.logger.Warn("Not committed — returned to pending changes", "files", strings.Join(files, ", "))
 
-	e.logger.Warn("Max review iterations reached, proceeding with push")
-	return groups, record
+	var held []watcher.FileChange
+	for _, fc := range changes {
+		if containsString(files, fc.Path) {
+			held = append(held, fc)
+		}
+	}
+	// Not through bufferChanges: held-back files matching
+	// commit_immediately_patterns would go straight back into the pipeline
+	// and prompt again
+	e.addPending(watcher.ChangeSet{Files: held, Timestamp: time.Now()})
 }
 
 // handleReviewFindings prompts the user and executes the chosen action.
-// Returns the action string ("manual", "aifix", "continue") and any error.
+// Returns the action string ("manual", "aifix", "continue", "dismiss",
+// "abort") and any error.
 func (e *Engine) handleReviewFindings(groups []grouper.FileGroup, result *ai.ReviewResult) (string, error) {
 	action, err := e.logger.PromptReviewAction()
 	if err != nil {
@@ -406,11 +2085,40 @@ func (e *Engine) handleReviewFindings(groups []grouper.FileGroup, result *ai.Rev
 
 	case "aifix":
 		e.applyAIFixes(result.Findings)
+
+	case "dismiss":
+		e.dismissFindings(result.Findings)
 	}
 
 	return action, nil
 }
 
+// filterDismissed drops findings the user previously dismissed as false
+// positives, so they no longer block the push.
+func (e *Engine) filterDismissed(result *ai.ReviewResult) *ai.ReviewResult {
+	filtered := result.Without(func(f ai.ReviewFinding) bool {
+		return e.dismissed.IsDismissed(f.File, f.StartLine, f.EndLine, f.Description)
+	})
+	if n := len(result.Findings) - len(filtered.Findings); n > 0 {
+		e.logger.Info("Skipping previously dismissed review findings", "count", n)
+	}
+	return filtered
+}
+
+// dismissFindings records every blocking finding as dismissed for
+// dismiss_days (0 = forever).
+func (e *Engine) dismissFindings(findings []ai.ReviewFinding) {
+	ttl := time.Duration(e.cfg.DismissDays) * 24 * time.Hour
+	for _, f := range findings {
+		if f.Severity != ai.SeverityError && f.Severity != ai.SeverityWarning {
+			continue
+		}
+		if err := e.dismissed.Add(f.File, f.StartLine, f.EndLine, f.Description, ttl); err != nil {
+			e.logger.Warn("Failed to record dismissed finding", "file", f.File, "err", err)
+		}
+	}
+}
+
 // parseDiffStats splits a combined unified diff into per-file FileChange records
 // with line-added/removed counts and file status (added, deleted, modified).
 func parseDiffStats(combinedDiff string, files []string) []store.FileChange {
@@ -435,11 +2143,16 @@ func parseDiffStats(combinedDiff string, files []string) []store.FileChange {
 		diff := fileDiffs[f]
 
 		// Count added/removed lines (skip diff headers starting with @@, ---, +++)
-		var added, removed int
+		// and hunks
+		var added, removed, hunks int
 		for _, line := range strings.Split(diff, "\n") {
 			if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
 				continue
 			}
+			if strings.HasPrefix(line, "@@") {
+				hunks++
+				continue
+			}
 			if strings.HasPrefix(line, "+") {
 				added++
 			} else if strings.HasPrefix(line, "-") {
@@ -461,6 +2174,8 @@ func parseDiffStats(combinedDiff string, files []string) []store.FileChange {
 			LinesAdded:   added,
 			LinesRemoved: removed,
 			Status:       status,
+			DiffBytes:    len(diff),
+			HunkCount:    hunks,
 		})
 	}
 
@@ -481,6 +2196,108 @@ func parseDiffStats(combinedDiff string, files []string) []store.FileChange {
 	return changes
 }
 
+// reviewFooter builds a git trailer summarizing the review, e.g.
+// "GitPulse-Review: 2 findings (1 warning ignored)". Any error/warning still
+// present at commit time was ignored, since the commit proceeds regardless.
+func reviewFooter(record *store.ReviewRecord) string {
+	var errors, warnings int
+	for _, f := range record.Findings {
+		switch f.Severity {
+		case ai.SeverityError:
+			errors++
+		case ai.SeverityWarning:
+			warnings++
+		}
+	}
+
+	footer := "GitPulse-Review: " + plural(len(record.Findings), "finding")
+	var ignored []string
+	if errors > 0 {
+		ignored = append(ignored, plural(errors, "error")+" ignored")
+	}
+	if warnings > 0 {
+		ignored = append(ignored, plural(warnings, "warning")+" ignored")
+	}
+	if len(ignored) > 0 {
+		footer += " (" + strings.Join(ignored, ", ") + ")"
+	}
+	return footer
+}
+
+// commitTrailers returns the trailer block for a commit message: a blank
+// line, then the review summary (commit_review_footer) and the attribution
+// (attribution_trailer), one per line. "" if neither applies.
+func (e *Engine) commitTrailers(review *store.ReviewRecord, model string) string {
+	var trailers []string
+	if e.cfg.CommitReviewFooter && review != nil {
+		trailers = append(trailers, reviewFooter(review))
+	}
+	if e.cfg.AttributionTrailer {
+		trailers = append(trailers, attributionTrailer(model))
+	}
+	if len(trailers) == 0 {
+		return ""
+	}
+	return "\n\n" + strings.Join(trailers, "\n")
+}
+
+// attributionTrailer marks a commit as made by GitPulse, e.g.
+// "Generated-by: GitPulse v1.2.3 (model: claude-sonnet-4-5)". The model is
+// left out when no AI wrote the message (ai.provider: none, or a fallback).
+func attributionTrailer(model string) string {
+	trailer := "Generated-by: GitPulse " + version.String()
+	if model != "" {
+		trailer += " (model: " + model + ")"
+	}
+	return trailer
+}
+
+// plural formats a count with a noun, adding "s" unless the count is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// authorTime returns the commit author date for a group: the earliest watcher
+// timestamp among its files with author_date: first_change (the default), or
+// zero (meaning now) with author_date: now or when no timestamps are known.
+func (e *Engine) authorTime(files []string, events []watcher.FileChange) time.Time {
+	if e.cfg.AuthorDate == config.AuthorDateNow {
+		return time.Time{}
+	}
+	var earliest time.Time
+	for _, ev := range events {
+		if ev.Time.IsZero() || !containsString(files, ev.Path) {
+			continue
+		}
+		if earliest.IsZero() || ev.Time.Before(earliest) {
+			earliest = ev.Time
+		}
+	}
+	return earliest
+}
+
+// stampChangeTimes sets each file's first/last change time from the buffered
+// watcher events. Files with no timestamped events are left as-is.
+func stampChangeTimes(changes []store.FileChange, events []watcher.FileChange) {
+	for i := range changes {
+		for _, ev := range events {
+			if ev.Path != changes[i].Path || ev.Time.IsZero() {
+				continue
+			}
+			t := ev.Time
+			if changes[i].FirstChangedAt == nil || t.Before(*changes[i].FirstChangedAt) {
+				changes[i].FirstChangedAt = &t
+			}
+			if changes[i].LastChangedAt == nil || t.After(*changes[i].LastChangedAt) {
+				changes[i].LastChangedAt = &t
+			}
+		}
+	}
+}
+
 // convertFindingsForStore converts ai.ReviewFinding to store.ReviewFinding
 // to avoid import cycles between the store and ai packages.
 func convertFindingsForStore(findings []ai.ReviewFinding) []store.ReviewFinding {
@@ -506,37 +2323,60 @@ func (e *Engine) applyAIFixes(findings []ai.ReviewFinding) {
 			continue
 		}
 
-		// Read the primary file content
-		absPath := filepath.Join(e.cfg.WatchPath, finding.File)
+		// Read the primary file content, remembering its mode (e.g. executable scripts)
+		absPath := filepath.Join(e.git.Root(), finding.File)
+		info, err := os.Stat(absPath)
+		if err != nil {
+			e.logger.Warn("Could not read file for AI fix", "file", finding.File, "err", err)
+			continue
+		}
 		primaryBytes, err := os.ReadFile(absPath)
 		if err != nil {
 			e.logger.Warn("Could not read file for AI fix", "file", finding.File, "err", err)
 			continue
 		}
+		// Decode it (and remember the encoding/BOM to write the fix back with)
+		primary, enc, err := decodeText(primaryBytes, e.cfg.FallbackEncoding)
+		if err != nil {
+			e.logger.Warn("Skipping AI fix for a file that isn't text in a supported encoding", "file", finding.File, "err", err)
+			continue
+		}
 
 		// Read related file contents for cross-file context
 		relatedContents := make(map[string]string)
 		for _, loc := range finding.RelatedLocations {
-			relPath := filepath.Join(e.cfg.WatchPath, loc.File)
+			relPath := filepath.Join(e.git.Root(), loc.File)
 			relBytes, err := os.ReadFile(relPath)
 			if err != nil {
 				continue // skip related files we can't read
 			}
-			relatedContents[loc.File] = string(relBytes)
+			related, _, err := decodeText(relBytes, e.cfg.FallbackEncoding)
+			if err != nil {
+				continue // or that aren't text
+			}
+			relatedContents[loc.File] = related
 		}
 
 		// Ask AI to generate the fix
-		fixed, err := e.ai.GenerateFix(finding.File, finding, string(primaryBytes), relatedContents)
+		fixed, err := e.reviewAI.GenerateFix(finding.File, finding, primary, relatedContents)
 		if err != nil {
 			e.logger.Warn("AI fix generation failed", "file", finding.File, "err", err)
 			continue
 		}
+		fixedBytes, err := enc.encode(fixed)
+		if err != nil {
+			e.logger.Warn("AI fix can't be written in the file's encoding", "file", finding.File, "encoding", enc.name, "err", err)
+			continue
+		}
 
-		// Write the fix back to disk
-		if err := os.WriteFile(absPath, []byte(fixed), 0644); err != nil {
+		// Write the fix back to disk with the original permissions
+		if err := os.WriteFile(absPath, fixedBytes, info.Mode().Perm()); err != nil {
 			e.logger.Warn("Failed to write AI fix", "file", finding.File, "err", err)
 			continue
 		}
+		if err := os.Chmod(absPath, info.Mode().Perm()); err != nil {
+			e.logger.Warn("Failed to restore file mode after AI fix", "file", finding.File, "err", err)
+		}
 
 		e.logger.AIFixApplied(finding.File, finding.Description)
 	}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// EventType identifies what changed in an Event.
+type EventType int
+
+const (
+	EventPending       EventType = iota // changes were buffered or the buffer was drained; Pending is a snapshot
+	EventTimer                          // the safety timer was armed (Deadline) or stopped (zero Deadline)
+	EventFlushStart                     // a flush began
+	EventFlushDone                      // a flush finished
+	EventSnooze                         // a snooze began (Deadline = its end) or ended (zero Deadline)
+	EventGroups                         // the groups about to be committed were decided; Groups
+	EventCommit                         // a commit was made (or amended); Hash, Message, Files
+	EventPush                           // a push to Remote finished; Hashes on success, Err on failure
+	EventReviewBlocked                  // the AI review found blockers; Findings
+)
+
+// Event is an engine state change, for UIs such as --tui. Only the fields
+// named for its Type are set.
+type Event struct {
+	Type     EventType
+	Pending  []watcher.FileChange
+	Deadline time.Time
+	Groups   []grouper.FileGroup
+	Hash     string
+	Message  string
+	Files    []string
+	Hashes   []string
+	Remote   string
+	Findings []ai.ReviewFinding
+	Err      error
+}
+
+// eventBuffer is how many events can queue per subscriber before new ones
+// are dropped.
+const eventBuffer = 64
+
+// Subscribe returns a new channel that receives every event from now on,
+// closed when the engine stops. Events are dropped rather than blocking the
+// engine when a subscriber falls behind, so treat each Pending snapshot as
+// the whole truth rather than a delta.
+func (e *Engine) Subscribe() <-chan Event {
+	ch := make(chan Event, eventBuffer)
+	e.subMu.Lock()
+	defer e.subMu.Unlock()
+	if e.stopped {
+		close(ch)
+		return ch
+	}
+	e.subscribers = append(e.subscribers, ch)
+	return ch
+}
+
+func (e *Engine) emit(ev Event) {
+	e.subMu.Lock()
+	defer e.subMu.Unlock()
+	for _, ch := range e.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// closeSubscribers ends every subscription; later emits go nowhere.
+func (e *Engine) closeSubscribers() {
+	e.subMu.Lock()
+	defer e.subMu.Unlock()
+	for _, ch := range e.subscribers {
+		close(ch)
+	}
+	e.subscribers = nil
+	e.stopped = true
+}
@@ -0,0 +1,60 @@
+package engine
+
+// queuedFlush is a flush requested while another one was running. Every
+// trigger that arrives in the meantime joins the same one, so a burst of
+// triggers (safety timer, `gitpulse push`, ENTER) costs one extra run.
+type queuedFlush struct {
+	bypassCap bool          // any of its triggers was a manual push
+	done      chan struct{} // closed once it has run
+}
+
+// claimFlush reserves the pipeline for the caller and returns nil, or, if a
+// flush is already running, joins the flush queued behind it and returns
+// that. Two pipelines staging and committing in the same worktree at once
+// would corrupt the index.
+func (e *Engine) claimFlush(bypassCap bool) *queuedFlush {
+	e.flushMu.Lock()
+	defer e.flushMu.Unlock()
+	if !e.flushRunning {
+		e.flushRunning = true
+		return nil
+	}
+	if e.flushNext == nil {
+		e.flushNext = &queuedFlush{done: make(chan struct{})}
+		e.logger.Info("A flush is already running — flushing again once it's done")
+	}
+	e.flushNext.bypassCap = e.flushNext.bypassCap || bypassCap
+	return e.flushNext
+}
+
+// tryClaimFlush reserves the pipeline for the caller if no flush is
+// running and reports whether it did. Unlike claimFlush it never queues a
+// flush, for callers that would rather buffer their changes than wait.
+func (e *Engine) tryClaimFlush() bool {
+	e.flushMu.Lock()
+	defer e.flushMu.Unlock()
+	if e.flushRunning {
+		return false
+	}
+	e.flushRunning = true
+	return true
+}
+
+// releaseFlush runs the flushes queued while the caller held the pipeline,
+// then frees it.
+func (e *Engine) releaseFlush() {
+	for {
+		e.flushMu.Lock()
+		next := e.flushNext
+		e.flushNext = nil
+		if next == nil {
+			e.flushRunning = false
+			e.flushMu.Unlock()
+			return
+		}
+		e.flushMu.Unlock()
+
+		e.runFlush(next.bypassCap)
+		close(next.done)
+	}
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// splitImmediate separates changes to files matching
+// commit_immediately_patterns (globs matched against the file name or
+// repo-relative path) from the rest.
+func (e *Engine) splitImmediate(files []watcher.FileChange) (immediate, rest []watcher.FileChange) {
+	if len(e.cfg.CommitImmediatelyPatterns) == 0 {
+		return nil, files
+	}
+	for _, fc := range files {
+		if matchesAny(e.cfg.CommitImmediatelyPatterns, fc.Path) {
+			immediate = append(immediate, fc)
+		} else {
+			rest = append(rest, fc)
+		}
+	}
+	return immediate, rest
+}
+
+// matchesAny reports whether path's base name or the whole path matches one
+// of patterns.
+func matchesAny(patterns []string, path string) bool {
+	base := filepath.Base(path)
+	for _, p := range patterns {
+		if matched, _ := filepath.Match(p, base); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(p, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// commitImmediately starts changes matching commit_immediately_patterns
+// through the pipeline on their own, without waiting for a flush. The
+// pipeline runs on its own goroutine, holding the flush queue like any
+// flush, so the caller (the watcher loop) keeps reading events through AI
+// calls and prompts. Returns false if flushing is on hold (snooze,
+// rebase/merge, commit cap) or a flush is running, in which case the caller
+// buffers them with everything else.
+func (e *Engine) commitImmediately(files []watcher.FileChange) bool {
+	if _, ok := e.SnoozedUntil(); ok {
+		return false
+	}
+	if e.holdForRepoOperation() || e.holdForCommitCap() {
+		return false
+	}
+	// Not while a flush is running: they're buffered and go out with the
+	// next one
+	if !e.tryClaimFlush() {
+		return false
+	}
+
+	paths := make([]string, len(files))
+	for i, fc := range files {
+		paths[i] = fc.Path
+	}
+	e.logger.Info("Committing right away (commit_immediately_patterns)", "files", strings.Join(paths, ", "))
+
+	e.emit(Event{Type: EventFlushStart})
+	go func() {
+		// Done only once the pipeline is free again
+		defer e.emit(Event{Type: EventFlushDone})
+		defer e.releaseFlush()
+		e.processChanges(watcher.ChangeSet{Files: files, Timestamp: time.Now()})
+	}()
+	return true
+}
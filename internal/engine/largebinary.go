@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// binarySniffBytes is how much of a file is checked for a NUL byte to tell
+// binary from text, the same heuristic git uses.
+const binarySniffBytes = 8000
+
+// isBinaryFile reports whether the file at path looks binary.
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	buf := make([]byte, binarySniffBytes)
+	n, _ := io.ReadFull(f, buf)
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
+
+// largeBinaries returns the changed files that would add a new binary file
+// of at least large_binary_prompt_mb to the repo, with their sizes.
+func (e *Engine) largeBinaries(files []watcher.FileChange) map[string]int64 {
+	limit := int64(e.cfg.LargeBinaryPromptMB) << 20
+	found := make(map[string]int64)
+	for _, fc := range files {
+		if fc.Type == watcher.Deleted {
+			continue
+		}
+		path := filepath.Join(e.git.Root(), fc.Path)
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Size() < limit {
+			continue
+		}
+		if tracked, err := e.git.InHead(fc.Path); err != nil || tracked {
+			continue
+		}
+		if isBinaryFile(path) {
+			found[fc.Path] = info.Size()
+		}
+	}
+	return found
+}
+
+// dropLargeBinaries keeps new large binaries (often build artifacts) out of
+// the flush unless the user confirms them. Non-interactive runs skip them
+// with a warning. A skipped file is asked about again the next time it
+// changes.
+func (e *Engine) dropLargeBinaries(files []watcher.FileChange) []watcher.FileChange {
+	if e.cfg.LargeBinaryPromptMB <= 0 {
+		return files
+	}
+	large := e.largeBinaries(files)
+	if len(large) == 0 {
+		return files
+	}
+
+	skip := make(map[string]bool)
+	for _, fc := range files {
+		size, ok := large[fc.Path]
+		if !ok || skip[fc.Path] {
+			continue
+		}
+		if e.Interactive {
+			add, err := e.logger.ConfirmLargeBinary(fc.Path, size)
+			if err == nil && add {
+				delete(large, fc.Path)
+				continue
+			}
+		} else {
+			e.logger.Warn("Skipping large new binary file — commit it yourself if it's meant to be in the repo",
+				"file", fc.Path, "mb", size>>20)
+		}
+		skip[fc.Path] = true
+	}
+
+	var kept []watcher.FileChange
+	for _, fc := range files {
+		if !skip[fc.Path] {
+			kept = append(kept, fc)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// formatMessage applies commit_subject_max_length and commit_body_wrap to a
+// generated commit message.
+func (e *Engine) formatMessage(msg string) string {
+	return formatCommitMessage(msg, e.cfg.CommitSubjectMaxLength, e.cfg.CommitBodyWrap)
+}
+
+// withDiffStat appends a diffstat of changes to the body of msg when
+// include_diffstat_in_body is on. The subject is left alone.
+func (e *Engine) withDiffStat(msg string, changes []store.FileChange) string {
+	if !e.cfg.IncludeDiffStatInBody || len(changes) == 0 {
+		return msg
+	}
+	return msg + "\n\n" + formatDiffStat(changes)
+}
+
+// formatCommitMessage keeps the subject line within subjectMax characters
+// by moving the words that don't fit into the body, as a paragraph wrapped
+// at bodyWrap columns ahead of any existing body. A single word longer than
+// subjectMax is cut. subjectMax <= 0 leaves the message alone; bodyWrap <= 0
+// doesn't wrap.
+func formatCommitMessage(msg string, subjectMax, bodyWrap int) string {
+	subject, body, _ := strings.Cut(msg, "\n")
+	subject = strings.TrimSpace(subject)
+	body = strings.TrimLeft(body, "\n")
+	if subjectMax <= 0 || utf8.RuneCountInString(subject) <= subjectMax {
+		return msg
+	}
+
+	words := strings.Fields(subject)
+	var kept []string
+	n := 0
+	for len(words) > 0 {
+		w := utf8.RuneCountInString(words[0])
+		if len(kept) > 0 {
+			w++ // the space before it
+		}
+		if n+w > subjectMax {
+			break
+		}
+		kept = append(kept, words[0])
+		n += w
+		words = words[1:]
+	}
+	if len(kept) == 0 {
+		// One word is longer than the whole subject: cut it
+		r := []rune(words[0])
+		kept = []string{string(r[:subjectMax])}
+		words[0] = string(r[subjectMax:])
+	}
+
+	out := strings.Join(kept, " ") + "\n\n" + wrapWords(words, bodyWrap)
+	if body != "" {
+		out += "\n\n" + body
+	}
+	// Every word may have fit once the spacing was tidied, leaving no body
+	return strings.TrimRight(out, "\n")
+}
+
+// wrapWords joins words into lines of at most width characters (longer
+// words get a line of their own). width <= 0 puts everything on one line.
+func wrapWords(words []string, width int) string {
+	var lines []string
+	line := ""
+	for _, w := range words {
+		switch {
+		case line == "":
+			line = w
+		case width > 0 && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(w) > width:
+			lines = append(lines, line)
+			line = w
+		default:
+			line += " " + w
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffStatWidth is the most +/- marks a diffstat line gets; bigger changes
+// are scaled down to fit, like `git diff --stat`.
+const diffStatWidth = 40
+
+// formatDiffStat renders changes like `git diff --stat`: one
+// "path | N +++--" line per file, then a summary line. Returns "" for no
+// changes.
+func formatDiffStat(changes []store.FileChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	pathWidth, countWidth, maxChange := 0, 1, 0
+	var added, removed int
+	for _, c := range changes {
+		n := c.LinesAdded + c.LinesRemoved
+		pathWidth = max(pathWidth, utf8.RuneCountInString(c.Path))
+		countWidth = max(countWidth, len(strconv.Itoa(n)))
+		maxChange = max(maxChange, n)
+		added += c.LinesAdded
+		removed += c.LinesRemoved
+	}
+
+	var b strings.Builder
+	for _, c := range changes {
+		plus, minus := c.LinesAdded, c.LinesRemoved
+		if maxChange > diffStatWidth {
+			plus = scaleStat(plus, maxChange)
+			minus = scaleStat(minus, maxChange)
+		}
+		fmt.Fprintf(&b, " %-*s | %*d", pathWidth, c.Path, countWidth, c.LinesAdded+c.LinesRemoved)
+		if plus+minus > 0 {
+			b.WriteString(" " + strings.Repeat("+", plus) + strings.Repeat("-", minus))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(" " + plural(len(changes), "file") + " changed")
+	if added > 0 {
+		b.WriteString(", " + plural(added, "insertion") + "(+)")
+	}
+	if removed > 0 {
+		b.WriteString(", " + plural(removed, "deletion") + "(-)")
+	}
+	return b.String()
+}
+
+// scaleStat scales n marks out of maxChange down to diffStatWidth, keeping
+// at least one mark for any nonzero count.
+func scaleStat(n, maxChange int) int {
+	if n == 0 {
+		return 0
+	}
+	return max(1, n*diffStatWidth/maxChange)
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// modeOnlyReason is the Reason of the group separateModeOnly builds.
+const modeOnlyReason = "file mode changes only"
+
+// separateModeOnly moves the files whose only change is their mode (chmod
+// +x, which has no content diff) out of their heuristic groups into one
+// group of their own, so the mode change gets its own commit instead of
+// riding along with unrelated edits. Groups left empty are dropped.
+func separateModeOnly(groups []grouper.FileGroup, diffs map[string]string) []grouper.FileGroup {
+	var modeFiles []string
+	var out []grouper.FileGroup
+	for _, g := range groups {
+		var files []string
+		for _, f := range g.Files {
+			if modeOnly(diffs[f]) {
+				modeFiles = append(modeFiles, f)
+			} else {
+				files = append(files, f)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		g.Files = files
+		out = append(out, g)
+	}
+	if len(modeFiles) == 0 {
+		return groups
+	}
+	out = append(out, grouper.FileGroup{Files: modeFiles, Reason: modeOnlyReason})
+	assembleDiffs(out, diffs)
+	return out
+}
+
+// modeChanged is the watcher's Chmod filter: it reports whether git sees a
+// new mode for path (relative to the watch dir), so touch, xattr updates and
+// indexers don't trigger flushes.
+func (e *Engine) modeChanged(path string) bool {
+	changes := e.toRepoPaths(watcher.ChangeSet{Files: []watcher.FileChange{{Path: path}}})
+	changed, err := e.git.ModeChanged(changes.Files[0].Path)
+	return err == nil && changed
+}
+
+// splitModeOnly separates the groups whose diff only changes file modes
+// from the rest and gives them a canned message ("chore: make deploy.sh
+// executable"), so they need no AI call.
+func (e *Engine) splitModeOnly(groups []grouper.FileGroup) (modes, rest []grouper.FileGroup) {
+	for _, g := range groups {
+		if !modeOnly(g.Diffs) {
+			rest = append(rest, g)
+			continue
+		}
+		g.CommitMessage = "chore: " + modeMessage(g.Files, g.Diffs)
+		g.Model = ""
+		modes = append(modes, g)
+		e.logger.Info("Only file modes changed, committing without an AI call", "files", strings.Join(g.Files, ", "))
+	}
+	return modes, rest
+}
+
+// modeOnly reports whether every file in a combined git diff has an old
+// mode / new mode header and no content change (no hunk, binary change or
+// rename).
+func modeOnly(diff string) bool {
+	sections := strings.Split(diff, "diff --git")[1:]
+	if len(sections) == 0 {
+		return false
+	}
+	for _, section := range sections {
+		if !strings.Contains(section, "\nold mode ") || !strings.Contains(section, "\nnew mode ") {
+			return false
+		}
+		for _, marker := range []string{"\n@@", "\nBinary files", "\nGIT binary patch", "\nrename from", "\ncopy from"} {
+			if strings.Contains(section, marker) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// modeMessage describes a mode-only change to files: "make deploy.sh
+// executable" when every new mode is 100755, "make deploy.sh
+// non-executable" when every one is 100644, "change the file mode of
+// deploy.sh" otherwise.
+func modeMessage(files []string, diff string) string {
+	executable, plain, other := 0, 0, 0
+	for _, line := range strings.Split(diff, "\n") {
+		mode, ok := strings.CutPrefix(line, "new mode ")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(mode) {
+		case "100755":
+			executable++
+		case "100644":
+			plain++
+		default:
+			other++
+		}
+	}
+
+	names := fmt.Sprintf("%d files", len(files))
+	switch len(files) {
+	case 1:
+		names = path.Base(files[0])
+	case 2:
+		names = path.Base(files[0]) + " and " + path.Base(files[1])
+	}
+	switch {
+	case executable > 0 && plain == 0 && other == 0:
+		return "make " + names + " executable"
+	case plain > 0 && executable == 0 && other == 0:
+		return "make " + names + " non-executable"
+	case len(files) == 1:
+		return "change the file mode of " + names
+	}
+	return "change the file modes of " + names
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"path/filepath"
+
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/store"
+)
+
+// outsideCommitsMax caps how many commits one reconciliation looks at, e.g.
+// after checking out a branch with a long history of its own.
+const outsideCommitsMax = 200
+
+// ReconcileOutsideCommits looks for commits made without GitPulse since the
+// HEAD it last saw (kept in .gitpulse/state.json), e.g. while it wasn't
+// running. With import_outside_commits they're added to the history as
+// ai_generated: false, so the dashboard shows them too; otherwise they're
+// only counted in the log. Then the current HEAD is remembered. Returns how
+// many such commits were found.
+func (e *Engine) ReconcileOutsideCommits() int {
+	state, err := store.LoadState(filepath.Join(e.cfg.WatchPath, ".gitpulse", "state.json"))
+	if err != nil {
+		e.logger.Warn("Could not read GitPulse state", "err", err)
+	}
+	head, err := e.git.HeadHash()
+	if err != nil || head == state.LastSeenHead {
+		return 0 // no commits yet, or nothing new
+	}
+	defer func() {
+		if err := state.SeeHead(head); err != nil {
+			e.logger.Warn("Could not save the last seen HEAD", "err", err)
+		}
+	}()
+	if state.LastSeenHead == "" {
+		return 0 // first run: only start tracking from here
+	}
+
+	commits, err := e.git.CommitsSince(state.LastSeenHead, outsideCommitsMax)
+	if err != nil {
+		e.logger.Warn("Could not check for commits made outside GitPulse", "err", err)
+		return 0
+	}
+	var outside []git.CommitInfo
+	for _, c := range commits {
+		if e.store.GetByHash(c.Hash) == nil {
+			outside = append(outside, c)
+		}
+	}
+	if len(outside) == 0 {
+		return 0
+	}
+	if !e.cfg.ImportOutsideCommits {
+		e.logger.Info("Found commits made outside GitPulse (set import_outside_commits to add them to the history)",
+			"count", len(outside))
+		return len(outside)
+	}
+
+	branch, _ := e.git.CurrentBranch()
+	records := make([]store.CommitRecord, 0, len(outside))
+	for _, c := range outside {
+		record := store.CommitRecord{
+			Hash:        c.Hash,
+			Message:     c.Message,
+			Files:       e.commitStats(c.Hash, nil),
+			GroupReason: "committed outside GitPulse",
+			AIGenerated: false,
+			Branch:      branch,
+			CreatedAt:   c.When,
+		}
+		skippedReview("committed outside GitPulse").apply(&record)
+		records = append(records, record)
+	}
+	added, err := e.store.Import(records)
+	if err != nil {
+		e.logger.Warn("Failed to import commits made outside GitPulse", "err", err)
+	}
+	e.logger.Info("Imported commits made outside GitPulse into the history", "count", added)
+	return len(outside)
+}
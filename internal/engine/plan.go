@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// planPath is where the plan for the dashboard's preview is written.
+func (e *Engine) planPath() string {
+	return filepath.Join(e.cfg.WatchPath, ".gitpulse", "plan.json")
+}
+
+// updatePlan writes what a flush would commit right now to
+// .gitpulse/plan.json: the pending changes, grouped the way the heuristic
+// pre-grouping would, each with a deterministic commit message (no AI call).
+// Called whenever the pending changes change.
+func (e *Engine) updatePlan() {
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+
+	e.mu.Lock()
+	pending := append([]watcher.FileChange(nil), e.pending...)
+	e.mu.Unlock()
+
+	plan := store.Plan{UpdatedAt: time.Now(), Pending: []store.PlanChange{}, Groups: []store.PlanGroup{}}
+	if len(pending) > 0 {
+		// Latest event per path, in first-seen order
+		index := make(map[string]int)
+		var files []watcher.FileChange
+		for _, fc := range pending {
+			if i, seen := index[fc.Path]; seen {
+				files[i] = fc
+				continue
+			}
+			index[fc.Path] = len(files)
+			files = append(files, fc)
+		}
+		for _, fc := range files {
+			plan.Pending = append(plan.Pending, store.PlanChange{Path: fc.Path, Status: planStatus(fc.Type)})
+		}
+
+		groups := grouper.PreGroupWithOptions(watcher.ChangeSet{Files: files}, e.groupOptions())
+		groups = grouper.ApplyGenerated(groups, e.generatedRules())
+		groups, _ = grouper.ApplyOverrides(groups, e.groupOverrides())
+		for i := range groups {
+			groups[i].Scope = grouper.ResolveScope(e.git.Root(), groups[i].Files)
+		}
+		offline := ai.NewOfflineClient()
+		offline.SetCommitTypes(e.cfg.CommitTypes)
+		groups, _ = offline.RefineAndCommit(groups)
+		for _, g := range groups {
+			plan.Groups = append(plan.Groups, store.PlanGroup{
+				Files:   g.Files,
+				Reason:  g.Reason,
+				Scope:   g.Scope,
+				Message: e.formatMessage(g.CommitMessage),
+			})
+		}
+	}
+
+	if err := store.SavePlan(e.planPath(), plan); err != nil {
+		e.logger.Warn("Could not write the commit plan for the dashboard", "err", err)
+	}
+}
+
+// clearPlan removes the plan file, so a stopped daemon doesn't leave a
+// stale preview behind.
+func (e *Engine) clearPlan() {
+	e.planMu.Lock()
+	defer e.planMu.Unlock()
+	if err := os.Remove(e.planPath()); err != nil && !os.IsNotExist(err) {
+		e.logger.Warn("Could not remove the commit plan", "err", err)
+	}
+}
+
+// planStatus names a watcher change type like store.FileChange.Status does.
+func planStatus(t watcher.ChangeType) string {
+	switch t {
+	case watcher.Created:
+		return "added"
+	case watcher.Deleted:
+		return "deleted"
+	case watcher.Renamed:
+		return "renamed"
+	}
+	return "modified"
+}
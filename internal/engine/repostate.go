@@ -0,0 +1,57 @@
+package engine
+
+import "time"
+
+// repoBusyPoll is how often a flush held for an in-progress rebase or merge
+// checks whether the user has finished it.
+const repoBusyPoll = 2 * time.Second
+
+// holdForRepoOperation reports whether the repository is mid-rebase,
+// -merge, -cherry-pick or -revert, in which case the flush is held: changes
+// stay buffered and busyTimer polls until the operation is over, then
+// flushes them.
+func (e *Engine) holdForRepoOperation() bool {
+	op, err := e.git.InProgressOperation()
+	if err != nil {
+		e.logger.Warn("Could not check for an in-progress rebase/merge", "err", err)
+		return false
+	}
+	if op == "" {
+		return false
+	}
+
+	e.timerMu.Lock()
+	if e.busyTimer == nil {
+		e.busyTimer = time.AfterFunc(repoBusyPoll, e.pollRepoOperation)
+	}
+	e.timerMu.Unlock()
+
+	e.logger.Warn("A git "+op+" is in progress — keeping changes buffered until it's finished",
+		"pending", e.PendingCount())
+	return true
+}
+
+// pollRepoOperation re-checks a held repository and flushes once the
+// operation is over.
+func (e *Engine) pollRepoOperation() {
+	select {
+	case <-e.done:
+		return
+	default:
+	}
+
+	op, err := e.git.InProgressOperation()
+	e.timerMu.Lock()
+	if err == nil && op != "" {
+		e.busyTimer = time.AfterFunc(repoBusyPoll, e.pollRepoOperation)
+		e.timerMu.Unlock()
+		return
+	}
+	e.busyTimer = nil
+	e.timerMu.Unlock()
+
+	e.logger.Info("Git operation finished — resuming auto-commits")
+	if e.PendingCount() > 0 {
+		e.Flush()
+	}
+}
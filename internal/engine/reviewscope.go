@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// fileHashes returns a hash of each group file's working-tree content, keyed
+// by path. A file that can't be read (e.g. deleted) hashes to "".
+func (e *Engine) fileHashes(groups []grouper.FileGroup) map[string]string {
+	hashes := make(map[string]string)
+	for _, g := range groups {
+		for _, f := range g.Files {
+			data, err := os.ReadFile(filepath.Join(e.git.Root(), f))
+			if err != nil {
+				hashes[f] = ""
+				continue
+			}
+			sum := sha256.Sum256(data)
+			hashes[f] = hex.EncodeToString(sum[:])
+		}
+	}
+	return hashes
+}
+
+// changedFiles re-hashes the group files and returns the new hashes along
+// with the files whose content differs from before. Files missing from diffs
+// count as changed so they get a diff.
+func (e *Engine) changedFiles(groups []grouper.FileGroup, before map[string]string, diffs map[string]string) (map[string]string, map[string]bool) {
+	after := e.fileHashes(groups)
+	changed := make(map[string]bool)
+	for f, h := range after {
+		old, seen := before[f]
+		if _, fetched := diffs[f]; !seen || old != h || !fetched {
+			changed[f] = true
+		}
+	}
+	return after, changed
+}
+
+// reviewScope is the set of files to re-review after a fix: the changed
+// files, plus every file of an earlier finding (its own and its related
+// locations) that touches one of them, since a fix on one side of a
+// cross-file finding can break the other.
+func reviewScope(changed map[string]bool, findings []ai.ReviewFinding) map[string]bool {
+	scope := make(map[string]bool, len(changed))
+	for f := range changed {
+		scope[f] = true
+	}
+	for _, f := range findings {
+		files := findingFiles(f)
+		touched := false
+		for _, file := range files {
+			if changed[file] {
+				touched = true
+				break
+			}
+		}
+		if !touched {
+			continue
+		}
+		for _, file := range files {
+			scope[file] = true
+		}
+	}
+	return scope
+}
+
+// outsideScope returns the findings that touch no file in scope. Their code
+// didn't change, so they still stand without asking the model again.
+func outsideScope(findings []ai.ReviewFinding, scope map[string]bool) []ai.ReviewFinding {
+	var kept []ai.ReviewFinding
+	for _, f := range findings {
+		inScope := false
+		for _, file := range findingFiles(f) {
+			if scope[file] {
+				inScope = true
+				break
+			}
+		}
+		if !inScope {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// findingFiles lists the file of f and of each of its related locations.
+func findingFiles(f ai.ReviewFinding) []string {
+	files := []string{f.File}
+	for _, loc := range f.RelatedLocations {
+		files = append(files, loc.File)
+	}
+	return files
+}
+
+// reviewScoped reviews groups narrowed to the files in scope and adds back
+// carried, the earlier findings outside it. A nil scope reviews every file;
+// an empty one (the fix changed nothing) reuses carried without a model call.
+func (e *Engine) reviewScoped(groups []grouper.FileGroup, diffs map[string]string, scope map[string]bool, carried []ai.ReviewFinding) (*ai.ReviewResult, error) {
+	if scope == nil {
+		return e.reviewAI.ReviewCode(groups)
+	}
+
+	var narrowed []grouper.FileGroup
+	for _, g := range groups {
+		var files []string
+		for _, f := range g.Files {
+			if scope[f] {
+				files = append(files, f)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		sub := g
+		sub.Files = files
+		narrowed = append(narrowed, sub)
+	}
+	assembleDiffs(narrowed, diffs)
+
+	result := &ai.ReviewResult{}
+	if len(narrowed) == 0 {
+		e.logger.Info("No files changed since the last review, keeping its findings")
+	} else {
+		reviewed, err := e.reviewAI.ReviewCode(narrowed)
+		if err != nil {
+			return nil, err
+		}
+		result = reviewed
+	}
+	if len(carried) > 0 {
+		e.logger.Info("Keeping findings on files the fix didn't touch", "count", len(carried))
+	}
+	return result.With(carried), nil
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// scheduleStartupSync runs SyncExistingChanges once startup_grace_seconds
+// have passed, so the watcher's directory walk has settled first.
+func (e *Engine) scheduleStartupSync() {
+	grace := time.Duration(e.cfg.StartupGraceSeconds) * time.Second
+	e.logger.Info("Will pick up changes made before startup", "in", grace)
+	time.AfterFunc(grace, func() {
+		select {
+		case <-e.done:
+			return
+		default:
+		}
+		e.SyncExistingChanges()
+	})
+}
+
+// SyncExistingChanges buffers the files git status reports as changed,
+// untracked or deleted, as if they had just been saved, so work in progress
+// from before GitPulse started is committed with the next flush. Files that
+// are ignored, outside watch_path or already pending are skipped. Returns how
+// many files were buffered.
+func (e *Engine) SyncExistingChanges() int {
+	changed, err := e.git.ChangedFiles()
+	if err != nil {
+		e.logger.Warn("Could not read git status, not picking up changes made before startup", "err", err)
+		return 0
+	}
+
+	// Paths in the watch dir's terms, for the ignore patterns
+	prefix := ""
+	if watchAbs, err := filepath.Abs(e.cfg.WatchPath); err == nil {
+		if rel, err := filepath.Rel(e.git.Root(), watchAbs); err == nil && rel != "." {
+			prefix = filepath.ToSlash(rel) + "/"
+		}
+	}
+
+	e.mu.Lock()
+	pending := make(map[string]bool, len(e.pending))
+	for _, fc := range e.pending {
+		pending[fc.Path] = true
+	}
+	e.mu.Unlock()
+
+	now := time.Now()
+	var files []watcher.FileChange
+	for _, path := range sortedKeys(changed) {
+		if !strings.HasPrefix(path, prefix) || pending[path] || e.watcher.Ignored(strings.TrimPrefix(path, prefix)) {
+			continue
+		}
+		fc := watcher.FileChange{Path: path, Type: watcher.Modified, Time: now}
+		if _, err := os.Lstat(filepath.Join(e.git.Root(), path)); err != nil {
+			fc.Type = watcher.Deleted
+		} else if inHead, err := e.git.InHead(path); err == nil && !inHead {
+			fc.Type = watcher.Created
+		}
+		files = append(files, fc)
+	}
+
+	if len(files) == 0 {
+		e.logger.Info("No changes from before startup to pick up")
+		return 0
+	}
+	e.logger.Info("Picked up changes made before startup", "files", len(files))
+	e.bufferChanges(watcher.ChangeSet{Files: files, Timestamp: now})
+	return len(files)
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firasastwani/gitpulse/internal/config"
+	"github.com/firasastwani/gitpulse/internal/git"
+	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// commitSubmodules commits each moved submodule pointer as its own
+// "chore: bump submodule X to <sha>" commit, and drops changes to files
+// inside submodules: those belong to the submodule's repo, and a gitlink
+// has no text diff to group or review. Returns the remaining changes and
+// the new commit hashes.
+func (e *Engine) commitSubmodules(changes []watcher.FileChange) ([]watcher.FileChange, []string) {
+	subs, err := e.git.Submodules()
+	if err != nil {
+		e.logger.Warn("Could not check submodules", "err", err)
+		return changes, nil
+	}
+	if len(subs) == 0 {
+		return changes, nil
+	}
+
+	var remaining []watcher.FileChange
+	var inside []string
+	for _, fc := range changes {
+		if submoduleOf(subs, fc.Path) != "" {
+			inside = append(inside, fc.Path)
+			continue
+		}
+		remaining = append(remaining, fc)
+	}
+	if len(inside) > 0 {
+		e.logger.Info("Leaving changes inside submodules to the submodule's own repo", "files", strings.Join(inside, ", "))
+	}
+
+	var hashes []string
+	for _, s := range subs {
+		if !s.Moved() {
+			continue
+		}
+		if hash := e.commitSubmodule(s); hash != "" {
+			hashes = append(hashes, hash)
+		}
+	}
+	return remaining, hashes
+}
+
+// commitSubmodule commits one submodule pointer update. Returns the commit
+// hash, or "" if the commit failed.
+func (e *Engine) commitSubmodule(s git.Submodule) string {
+	message := e.formatMessage(fmt.Sprintf("chore: bump submodule %s to %s", s.Path, s.Current[:7])) + e.commitTrailers(nil, "")
+
+	hash, err := e.git.CommitFilesAt([]string{s.Path}, message, time.Time{})
+	if errors.Is(err, git.ErrNothingToCommit) {
+		return ""
+	}
+	if err != nil {
+		e.logger.Error("Failed to commit submodule update", err, "submodule", s.Path)
+		return ""
+	}
+	e.logger.CommitSuccess(hash, message)
+	e.emit(Event{Type: EventCommit, Hash: hash, Message: message, Files: []string{s.Path}})
+
+	change := store.FileChange{Path: s.Path, Status: "modified"}
+	if s.Recorded == "" {
+		change.Status = "added"
+		change.Diff = fmt.Sprintf("Submodule %s added at %s", s.Path, s.Current[:7])
+	} else {
+		change.Diff = fmt.Sprintf("Submodule %s %s..%s", s.Path, s.Recorded[:7], s.Current[:7])
+	}
+
+	record := store.CommitRecord{
+		Hash:        hash,
+		Message:     message,
+		Files:       []store.FileChange{change},
+		GroupReason: "submodule pointer update",
+		LocalOnly:   e.cfg.PushMode == config.PushModeNever,
+	}
+	skippedReview("submodule pointer update").apply(&record)
+	if err := e.store.Save(record); err != nil {
+		e.logger.Warn("Failed to save commit record", "err", err)
+	}
+
+	return hash
+}
+
+// submoduleOf returns the path of the submodule that is or contains path,
+// or "".
+func submoduleOf(subs []git.Submodule, path string) string {
+	path = filepath.ToSlash(path)
+	for _, s := range subs {
+		if path == s.Path || strings.HasPrefix(path, s.Path+"/") {
+			return s.Path
+		}
+	}
+	return ""
+}
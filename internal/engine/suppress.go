@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/ai"
+)
+
+// Inline review suppression markers, matched in any comment syntax:
+//
+//	x := hack() // gitpulse:ignore-review         (this line)
+//	// gitpulse:ignore-review                     (the next line)
+//	// gitpulse:ignore-review-start ... // gitpulse:ignore-review-end
+//
+// An unterminated -start runs to the end of the file.
+const (
+	suppressMarker      = "gitpulse:ignore-review"
+	suppressStartMarker = suppressMarker + "-start"
+	suppressEndMarker   = suppressMarker + "-end"
+)
+
+// lineRange is an inclusive range of 1-based line numbers.
+type lineRange struct{ start, end int }
+
+// suppressedRanges returns the regions of content marked with
+// gitpulse:ignore-review.
+func suppressedRanges(content string) []lineRange {
+	var ranges []lineRange
+	regionStart := 0 // line of the open -start marker, 0 if none
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		n := i + 1
+		idx := strings.Index(line, suppressMarker)
+		if idx < 0 {
+			continue
+		}
+		switch rest := line[idx:]; {
+		case strings.HasPrefix(rest, suppressStartMarker):
+			if regionStart == 0 {
+				regionStart = n
+			}
+		case strings.HasPrefix(rest, suppressEndMarker):
+			if regionStart != 0 {
+				ranges = append(ranges, lineRange{regionStart, n})
+				regionStart = 0
+			}
+		case ownLineComment(line[:idx]):
+			ranges = append(ranges, lineRange{n, n + 1})
+		default:
+			ranges = append(ranges, lineRange{n, n})
+		}
+	}
+	if regionStart != 0 {
+		ranges = append(ranges, lineRange{regionStart, len(lines)})
+	}
+	return ranges
+}
+
+// ownLineComment reports whether the text before a marker is only a comment
+// leader (//, #, --, /*, <!--, ;), i.e. the marker is on a line of its own.
+func ownLineComment(prefix string) bool {
+	return strings.Trim(strings.TrimSpace(prefix), "/#*-;<! \t") == ""
+}
+
+// filterSuppressed drops findings whose lines overlap a region marked with
+// gitpulse:ignore-review in the file's current content, so they never block.
+func (e *Engine) filterSuppressed(result *ai.ReviewResult) *ai.ReviewResult {
+	regions := make(map[string][]lineRange)
+	filtered := result.Without(func(f ai.ReviewFinding) bool {
+		ranges, ok := regions[f.File]
+		if !ok {
+			data, err := os.ReadFile(filepath.Join(e.git.Root(), f.File))
+			if err == nil {
+				if text, _, err := decodeText(data, e.cfg.FallbackEncoding); err == nil {
+					ranges = suppressedRanges(text)
+				}
+			}
+			regions[f.File] = ranges
+		}
+		end := f.EndLine
+		if end < f.StartLine {
+			end = f.StartLine
+		}
+		for _, r := range ranges {
+			if f.StartLine <= r.end && end >= r.start {
+				return true
+			}
+		}
+		return false
+	})
+	if n := len(result.Findings) - len(filtered.Findings); n > 0 {
+		e.logger.Info("Skipping review findings in gitpulse:ignore-review regions", "count", n)
+	}
+	return filtered
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/firasastwani/gitpulse/internal/grouper"
+)
+
+// formatMessage is the canned commit message for whitespace-only groups.
+const formatMessage = "format code"
+
+// splitWhitespaceOnly separates the groups whose diff only changes
+// whitespace (e.g. a gofmt pass) from the rest and gives them a canned
+// "style: format code" message, so they need no AI call. With
+// skip_ai_for_whitespace_only off every group is returned as rest.
+func (e *Engine) splitWhitespaceOnly(groups []grouper.FileGroup) (formatting, rest []grouper.FileGroup) {
+	if !e.cfg.SkipAIForWhitespaceOnly {
+		return nil, groups
+	}
+	typ := "style"
+	if !containsString(e.cfg.CommitTypes, typ) {
+		typ = "chore"
+	}
+	for _, g := range groups {
+		if !whitespaceOnly(g.Diffs) {
+			rest = append(rest, g)
+			continue
+		}
+		g.CommitMessage = typ + ": " + formatMessage
+		g.Model = ""
+		formatting = append(formatting, g)
+		e.logger.Info("Only whitespace changed, committing without an AI call", "files", strings.Join(g.Files, ", "))
+	}
+	return formatting, rest
+}
+
+// whitespaceOnly reports whether every file in a combined unified diff only
+// changes whitespace: with all whitespace removed, the old and new text of
+// each hunk (context lines included, in order) read the same, so moving or
+// reordering lines doesn't count. New and deleted files, mode changes, and
+// diffs with no changed lines (e.g. binary files), don't either.
+func whitespaceOnly(diff string) bool {
+	sections := strings.Split(diff, "diff --git")
+	changed := false
+	for _, section := range sections {
+		if strings.Contains(section, "--- /dev/null") || strings.Contains(section, "+++ /dev/null") ||
+			strings.Contains(section, "\nold mode ") {
+			return false
+		}
+		var before, after strings.Builder
+		inHunk := false // +/- lines before the first @@ are file headers
+		for _, line := range strings.Split(section, "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				if before.String() != after.String() {
+					return false
+				}
+				before.Reset()
+				after.Reset()
+				inHunk = true
+			case !inHunk:
+			case strings.HasPrefix(line, "-"):
+				before.WriteString(stripSpace(line[1:]))
+				changed = true
+			case strings.HasPrefix(line, "+"):
+				after.WriteString(stripSpace(line[1:]))
+				changed = true
+			case strings.HasPrefix(line, " "):
+				before.WriteString(stripSpace(line[1:]))
+				after.WriteString(stripSpace(line[1:]))
+			}
+		}
+		if before.String() != after.String() {
+			return false
+		}
+	}
+	return changed
+}
+
+// stripSpace removes every whitespace character from s.
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
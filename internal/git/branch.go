@@ -0,0 +1,96 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitDir returns the absolute path of the repository's git dir (for a
+// linked worktree, its own one, where its HEAD lives).
+func (m *Manager) GitDir() (string, error) {
+	out, err := m.gitOutput(nil, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to find git dir: %w", err)
+	}
+	return out, nil
+}
+
+// CommitToBranch commits files, as they are in the working tree, on top of
+// branch without checking it out or touching the index — e.g. changes made
+// on a branch that has since been switched away from. message is called with
+// the diff being committed and returns the commit message. Returns the new
+// commit hash, or an error wrapping ErrNothingToCommit if files already
+// match the branch.
+func (m *Manager) CommitToBranch(branch string, files []string, message func(diff string) string) (string, error) {
+	ref := "refs/heads/" + branch
+	parent, err := m.gitOutput(nil, "rev-parse", "--verify", ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+	}
+
+	// A throwaway index holding the branch's tree plus the files
+	tmp, err := os.MkdirTemp("", "gitpulse-index")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+
+	if _, err := m.gitOutput(env, "read-tree", parent); err != nil {
+		return "", fmt.Errorf("failed to read %s into a temporary index: %w", branch, err)
+	}
+	if _, err := m.gitOutput(env, append([]string{"add", "-A", "--"}, files...)...); err != nil {
+		return "", fmt.Errorf("failed to stage files for %s: %w", branch, err)
+	}
+	tree, err := m.gitOutput(env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write tree: %w", err)
+	}
+	parentTree, err := m.gitOutput(nil, "rev-parse", parent+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s's tree: %w", branch, err)
+	}
+	if tree == parentTree {
+		return "", fmt.Errorf("failed to commit to %s: %w", branch, ErrNothingToCommit)
+	}
+
+	diff, err := m.gitOutput(nil, "diff-tree", "-p", parentTree, tree)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff against %s: %w", branch, err)
+	}
+	signature := []string{
+		"GIT_AUTHOR_NAME=GitPulse", "GIT_AUTHOR_EMAIL=gitpulse@auto",
+		"GIT_COMMITTER_NAME=GitPulse", "GIT_COMMITTER_EMAIL=gitpulse@auto",
+	}
+	hash, err := m.gitOutput(signature, "commit-tree", tree, "-p", parent, "-m", message(diff+"\n"))
+	if err != nil {
+		return "", fmt.Errorf("failed to commit to %s: %w", branch, err)
+	}
+	// Only move the branch if nothing else did in the meantime
+	if _, err := m.gitOutput(nil, "update-ref", ref, hash, parent); err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", branch, err)
+	}
+	return hash, nil
+}
+
+// gitOutput runs git in the working tree root with extra environment
+// variables and returns its trimmed stdout. Errors include git's stderr.
+func (m *Manager) gitOutput(env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = m.repoPath
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
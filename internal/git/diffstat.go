@@ -0,0 +1,88 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// FileStat is one file's change in a commit.
+type FileStat struct {
+	Path    string
+	OldPath string // the path before a rename, "" otherwise
+	Added   int
+	Removed int
+	Status  string // "added", "deleted", "modified" or "renamed"
+	Binary  bool   // no line counts
+}
+
+// DiffStat returns per-file stats for the commit hash against its first
+// parent (everything counts as added for a root commit), from go-git's tree
+// comparison with rename detection rather than from a text diff.
+func (m *Manager) DiffStat(hash string) ([]FileStat, error) {
+	commit, err := m.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", hash, err)
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parent of %s: %w", hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s's parent: %w", hash, err)
+		}
+	}
+
+	changes, err := object.DiffTreeWithOptions(context.Background(), parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare trees of %s: %w", hash, err)
+	}
+
+	stats := make([]FileStat, 0, len(changes))
+	for _, c := range changes {
+		action, err := c.Action()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read change in %s: %w", hash, err)
+		}
+		var s FileStat
+		switch {
+		case action == merkletrie.Insert:
+			s = FileStat{Path: c.To.Name, Status: "added"}
+		case action == merkletrie.Delete:
+			s = FileStat{Path: c.From.Name, Status: "deleted"}
+		case c.From.Name != c.To.Name:
+			s = FileStat{Path: c.To.Name, OldPath: c.From.Name, Status: "renamed"}
+		default:
+			s = FileStat{Path: c.To.Name, Status: "modified"}
+		}
+
+		// A gitlink's "content" is a commit in another repo: no lines
+		if c.From.TreeEntry.Mode == filemode.Submodule || c.To.TreeEntry.Mode == filemode.Submodule {
+			stats = append(stats, s)
+			continue
+		}
+		patch, err := c.Patch()
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s in %s: %w", s.Path, hash, err)
+		}
+		for _, fp := range patch.FilePatches() {
+			s.Binary = s.Binary || fp.IsBinary()
+		}
+		for _, fs := range patch.Stats() {
+			s.Added += fs.Addition
+			s.Removed += fs.Deletion
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Sentinel errors returned (wrapped, with details) by Manager so callers can
+// branch with errors.Is instead of matching message text.
+var (
+	// ErrNoRemote means the configured remote doesn't exist (or isn't a repo).
+	ErrNoRemote = errors.New("remote not found")
+	// ErrAuthFailed means the remote rejected or couldn't get credentials.
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrNonFastForward means the remote branch has commits the local one lacks.
+	ErrNonFastForward = errors.New("non-fast-forward: remote has commits you don't have")
+	// ErrNothingToCommit means the index matches HEAD.
+	ErrNothingToCommit = errors.New("nothing to commit")
+	// ErrBranchMismatch means the checked-out branch isn't the target branch
+	// (branch is pinned to another one), so pulling would rebase the wrong one.
+	ErrBranchMismatch = errors.New("checked-out branch is not the target branch")
+)
+
+// remoteError maps a failed remote operation onto one of the sentinels,
+// looking at the go-git error and the output of the shell git fallback (if
+// any). Unrecognized failures (e.g. network errors) keep their message and
+// match none of the sentinels, so they're the ones worth retrying.
+func remoteError(op string, err error, output string) error {
+	detail := summarize(output)
+	if detail == "" && err != nil {
+		detail = err.Error()
+	}
+	wrap := func(sentinel error) error {
+		if detail == "" || detail == sentinel.Error() {
+			return fmt.Errorf("%s: %w", op, sentinel)
+		}
+		return fmt.Errorf("%s: %w: %s", op, sentinel, detail)
+	}
+
+	out := strings.ToLower(output)
+	switch {
+	case errors.Is(err, gogit.ErrRemoteNotFound),
+		strings.Contains(out, "does not appear to be a git repository"),
+		strings.Contains(out, "no such remote"),
+		strings.Contains(out, "repository not found") && !strings.Contains(out, "auth"):
+		return wrap(ErrNoRemote)
+	case strings.Contains(out, "authentication failed"),
+		strings.Contains(out, "could not read username"),
+		strings.Contains(out, "could not read password"),
+		strings.Contains(out, "permission denied"),
+		strings.Contains(out, "403"),
+		output == "" && (errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed)):
+		return wrap(ErrAuthFailed)
+	case strings.Contains(out, "non-fast-forward"),
+		strings.Contains(out, "fetch first"),
+		strings.Contains(out, "[rejected]"),
+		output == "" && (errors.Is(err, gogit.ErrNonFastForwardUpdate) || errors.Is(err, gogit.ErrForceNeeded)):
+		return wrap(ErrNonFastForward)
+	}
+	return fmt.Errorf("%s failed: %s", op, detail)
+}
+
+// summarize picks the line of git's output that says what went wrong
+// (a rejected ref or the first error), skipping progress lines and advice.
+func summarize(output string) string {
+	first := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if first == "" {
+			first = line
+		}
+		if strings.Contains(line, "[rejected]") || strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+			return line
+		}
+	}
+	return first
+}
@@ -1,13 +1,21 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -17,29 +25,94 @@ type Manager struct {
 	remote   string
 	branch   string
 	repo     *gogit.Repository
+
+	// diff session — HEAD snapshot shared by GetFileDiff calls within one flush
+	sessionMu     sync.RWMutex
+	sessionActive bool
+	sessionHead   string // "" when the repo has no commits yet
 }
 
-// New creates a new git Manager for the given repository path.
+// New creates a new git Manager for the repository containing repoPath.
+// repoPath may be a subdirectory or a linked worktree (`git worktree add`);
+// the Manager always works from the working tree root, and every path it
+// accepts or returns is relative to that root. Bare repos are rejected since
+// there is no working tree to watch or commit from.
 func New(repoPath, remote, branch string) (*Manager, error) {
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repo path %s: %w", repoPath, err)
+	}
 
-	repo, err := gogit.PlainOpen(repoPath)
+	// EnableDotGitCommonDir makes a linked worktree's .git file resolve to
+	// its own gitdir (HEAD, index) plus the main repo's shared objects/refs
+	repo, err := gogit.PlainOpenWithOptions(abs, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		// DetectDotGit looks for a .git entry, so a bare repo dir only opens directly
+		if bare, bareErr := gogit.PlainOpen(abs); bareErr == nil {
+			repo, err = bare, nil
+		}
+	}
 
 	// catch error, very likely needs to be fixed
 	if err != nil {
 		return nil, fmt.Errorf("failed to open repo at %s: %w", repoPath, err)
 	}
 
+	wt, err := repo.Worktree()
+	if err != nil {
+		if errors.Is(err, gogit.ErrIsBareRepository) {
+			return nil, fmt.Errorf("%s is a bare repository; run GitPulse in a working tree (e.g. one made with `git worktree add`)", repoPath)
+		}
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	root := wt.Filesystem.Root()
+
 	return &Manager{
-		repoPath: repoPath,
+		repoPath: root,
 		remote:   remote,
 		branch:   branch,
 		repo:     repo,
 	}, nil
 }
 
-// StageFiles adds the specified files to the git staging area.
+// Root returns the absolute path of the repository's working tree root.
+func (m *Manager) Root() string {
+	return m.repoPath
+}
 
-// common error location.. can add more logging etc
+// StageFailure records why a single file could not be staged.
+type StageFailure struct {
+	Path string
+	Err  error
+}
+
+// StageError lists the files StageFiles could not stage. Staged is how many
+// files did succeed; callers can commit a partial group when it's > 0.
+type StageError struct {
+	Failures []StageFailure
+	Staged   int
+}
+
+func (e *StageError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("%s: %v", f.Path, f.Err)
+	}
+	return fmt.Sprintf("failed to stage %d file(s) (%d staged): %s", len(e.Failures), e.Staged, strings.Join(parts, "; "))
+}
+
+// stageRetryDelay is how long to wait before re-checking a missing file, to
+// ride out editors that save by delete + rename.
+const stageRetryDelay = 200 * time.Millisecond
+
+// StageFiles adds the specified files to the git staging area.
+// Files that no longer exist on disk are staged as deletions (after one short
+// retry). Per-file failures are collected rather than aborting the group; a
+// *StageError is returned if any file failed, with Staged > 0 if some succeeded.
+// A submodule path is staged as a pointer to the submodule's checked-out commit.
 func (m *Manager) StageFiles(files []string) error {
 
 	wt, err := m.repo.Worktree()
@@ -48,35 +121,72 @@ func (m *Manager) StageFiles(files []string) error {
 		return fmt.Errorf("Failed to get worktree %w", err)
 	}
 
+	gitlinks := m.submoduleHeads()
+	stageErr := &StageError{}
 	for _, f := range files {
-		_, err := wt.Add(f)
-
+		if commit, ok := gitlinks[f]; ok {
+			err = m.stageGitlink(f, commit)
+		} else {
+			err = m.stageFile(wt, f)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to stage %s: %w", f, err)
+			stageErr.Failures = append(stageErr.Failures, StageFailure{Path: f, Err: err})
+			continue
 		}
+		stageErr.Staged++
 	}
 
+	if len(stageErr.Failures) > 0 {
+		return stageErr
+	}
 	return nil
 }
 
-// GetStagedDiff returns the unified diff of all currently staged changes.
-func (m *Manager) GetStagedDiff() (string, error) {
-	// TODO: Get diff between HEAD and staging area
+// stageFile stages one path, treating a missing file as a deletion.
+func (m *Manager) stageFile(wt *gogit.Worktree, path string) error {
+	if !m.exists(path) {
+		time.Sleep(stageRetryDelay)
+	}
+	if m.exists(path) {
+		_, err := wt.Add(path)
+		return err
+	}
+
+	// Gone from disk: stage the deletion if git tracked it
+	if _, err := wt.Remove(path); err != nil {
+		return fmt.Errorf("file no longer exists and is not tracked: %w", err)
+	}
+	return nil
+}
+
+// exists reports whether path (relative to the repo root) is present on disk.
+func (m *Manager) exists(path string) bool {
+	_, err := os.Lstat(filepath.Join(m.repoPath, path))
+	return err == nil
+}
+
+// PartiallyStaged reports whether err from StageFiles still left some files
+// staged, so the caller can go ahead and commit them.
+func PartiallyStaged(err error) bool {
+	var stageErr *StageError
+	return errors.As(err, &stageErr) && stageErr.Staged > 0
+}
 
+// StagedFiles returns the paths that currently have changes in the index
+// (e.g. files the user staged by hand with `git add`), sorted.
+func (m *Manager) StagedFiles() ([]string, error) {
 	wt, err := m.repo.Worktree()
 
 	if err != nil {
-		return "", fmt.Errorf("failed to get worktree: %w", err)
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
 	}
 
 	status, err := wt.Status()
 
 	if err != nil {
-		return "", fmt.Errorf("failed to get status: %w", err)
+		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
-	// collect all the paths that are staged
-
 	var staged []string
 
 	for path, s := range status {
@@ -85,37 +195,125 @@ func (m *Manager) GetStagedDiff() (string, error) {
 		}
 	}
 
-	var diffs []string
+	sort.Strings(staged)
+	return staged, nil
+}
 
-	for _, path := range staged {
-		d, err := m.GetFileDiff(path)
+// ChangedFiles returns the set of paths that differ from HEAD in either the
+// index or the working tree (including untracked files).
+func (m *Manager) ChangedFiles() (map[string]bool, error) {
+	wt, err := m.repo.Worktree()
 
-		if err != nil {
-			// dosent return error so it dosent break all if one stops
-			continue
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := wt.Status()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	changed := make(map[string]bool, len(status))
+	for path, s := range status {
+		if s.Staging != gogit.Unmodified || s.Worktree != gogit.Unmodified {
+			changed[path] = true
 		}
+	}
+
+	return changed, nil
+}
 
-		diffs = append(diffs, d)
+// ModeChanged reports whether path (relative to the repo root) has a
+// different file mode in the working tree than in the index, e.g. after
+// chmod +x. Like git, it sees no mode changes with core.fileMode off.
+func (m *Manager) ModeChanged(path string) (bool, error) {
+	cmd := exec.Command("git", "diff", "--raw", "--", path)
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check the mode of %s: %w", path, err)
+	}
+	// :<old mode> <new mode> <old hash> <new hash> <status>\t<path>
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, ":"))
+		if len(fields) >= 2 && fields[0] != fields[1] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetStagedDiff returns the unified diff of all currently staged changes
+// (index vs HEAD), leaving any unstaged working-tree edits out.
+func (m *Manager) GetStagedDiff() (string, error) {
+	cmd := exec.Command("git", "diff", "--cached")
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
 	}
 
-	return strings.Join(diffs, "\n"), nil
+	return string(output), nil
+}
+
+// BeginDiffSession snapshots HEAD so every GetFileDiff call in a flush diffs
+// against the same commit without re-resolving it. The snapshot is dropped
+// automatically on the next commit or amend.
+func (m *Manager) BeginDiffSession() {
+	head := ""
+	if ref, err := m.repo.Head(); err == nil {
+		head = ref.Hash().String()
+	}
+
+	m.sessionMu.Lock()
+	m.sessionActive = true
+	m.sessionHead = head
+	m.sessionMu.Unlock()
+}
+
+// endDiffSession drops the HEAD snapshot (HEAD has moved).
+func (m *Manager) endDiffSession() {
+	m.sessionMu.Lock()
+	m.sessionActive = false
+	m.sessionHead = ""
+	m.sessionMu.Unlock()
+}
+
+// diffBase returns the revision GetFileDiff should diff against, and false
+// if there is none (fresh repo).
+func (m *Manager) diffBase() (string, bool) {
+	m.sessionMu.RLock()
+	active, head := m.sessionActive, m.sessionHead
+	m.sessionMu.RUnlock()
+
+	if active {
+		return head, head != ""
+	}
+	if !m.hasHead() {
+		return "", false
+	}
+	return "HEAD", true
 }
 
 // GetFileDiff returns the real unified diff for a specific file against HEAD.
 // Shells out to `git diff` to get actual +/- line content that Claude can review.
 func (m *Manager) GetFileDiff(path string) (string, error) {
-	// Try diffing against HEAD (for tracked, modified files)
-	cmd := exec.Command("git", "diff", "HEAD", "--", path)
-	cmd.Dir = m.repoPath
-	output, err := cmd.Output()
-	if err == nil && len(output) > 0 {
-		return string(output), nil
+	// Try diffing against HEAD (for tracked, modified files).
+	// In a fresh repo with no commits every file is new, so skip straight to /dev/null.
+	if base, ok := m.diffBase(); ok {
+		cmd := exec.Command("git", "diff", base, "--", path)
+		cmd.Dir = m.repoPath
+		output, err := cmd.Output()
+		if err == nil && len(output) > 0 {
+			return string(output), nil
+		}
 	}
 
 	// File might be untracked (new) — diff against /dev/null
-	cmd = exec.Command("git", "diff", "--no-index", "/dev/null", path)
+	cmd := exec.Command("git", "diff", "--no-index", "/dev/null", path)
 	cmd.Dir = m.repoPath
-	output, _ = cmd.Output()
+	output, _ := cmd.Output()
 	if len(output) > 0 {
 		return string(output), nil
 	}
@@ -124,8 +322,15 @@ func (m *Manager) GetFileDiff(path string) (string, error) {
 }
 
 // Commit creates a new commit with the given message.
-// Returns the commit hash.
+// Returns the commit hash, or an error wrapping ErrNothingToCommit if the
+// index matches HEAD.
 func (m *Manager) Commit(message string) (string, error) {
+	return m.CommitAt(message, time.Time{})
+}
+
+// CommitAt is Commit with an explicit author date (e.g. when the change was
+// actually made); the committer date is always now. A zero authorTime means now.
+func (m *Manager) CommitAt(message string, authorTime time.Time) (string, error) {
 
 	wt, err := m.repo.Worktree()
 
@@ -133,48 +338,410 @@ func (m *Manager) Commit(message string) (string, error) {
 		return "", fmt.Errorf("failed to get worktree: %w", err)
 	}
 
+	now := time.Now()
+	if authorTime.IsZero() {
+		authorTime = now
+	}
 	hash, err := wt.Commit(message, &gogit.CommitOptions{
 		Author: &object.Signature{
 			Name:  "GitPulse",
 			Email: "gitpulse@auto",
-			When:  time.Now(),
+			When:  authorTime,
+		},
+		Committer: &object.Signature{
+			Name:  "GitPulse",
+			Email: "gitpulse@auto",
+			When:  now,
 		},
 	})
 
+	if errors.Is(err, gogit.ErrEmptyCommit) {
+		return "", fmt.Errorf("failed to commit changes: %w", ErrNothingToCommit)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to commit changes: %w", err)
 	}
+	m.endDiffSession()
+
+	return hash.String(), nil
+}
+
+// AmendLastCommit folds files into the HEAD commit with a new message,
+// returning the new commit hash. Like CommitFilesAt, nothing else that is
+// staged goes into the commit or gets unstaged. As with git commit --amend,
+// HEAD's author (and author date) is kept and only the committer date moves
+// to now. Callers must make sure HEAD has not been pushed, or that the user
+// agreed to a force push (see ForcePushTo); amending rewrites history.
+func (m *Manager) AmendLastCommit(files []string, message string) (string, error) {
+	var hash plumbing.Hash
+	_, err := m.scoped(files, func() error {
+		wt, err := m.repo.Worktree()
+
+		if err != nil {
+			return fmt.Errorf("failed to get worktree: %w", err)
+		}
+
+		head, err := m.repo.Head()
+		if err != nil {
+			return fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		headCommit, err := m.repo.CommitObject(head.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to read HEAD commit: %w", err)
+		}
+		author := headCommit.Author
+
+		hash, err = wt.Commit(message, &gogit.CommitOptions{
+			Amend:  true,
+			Author: &author,
+			Committer: &object.Signature{
+				Name:  "GitPulse",
+				Email: "gitpulse@auto",
+				When:  time.Now(),
+			},
+		})
+
+		if errors.Is(err, gogit.ErrEmptyCommit) {
+			return fmt.Errorf("failed to amend commit: %w", ErrNothingToCommit)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to amend commit: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	m.endDiffSession()
 
 	return hash.String(), nil
 }
 
+// hasHead reports whether HEAD resolves to a commit (false in a fresh repo).
+func (m *Manager) hasHead() bool {
+	_, err := m.repo.Head()
+	return err == nil
+}
+
+// HeadHash returns the full hash of the commit HEAD points to.
+func (m *Manager) HeadHash() (string, error) {
+	head, err := m.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
+// InHead reports whether path (relative to the repo root) is in HEAD's
+// tree, i.e. committing it wouldn't add a new file. False in a fresh repo.
+func (m *Manager) InHead(path string) (bool, error) {
+	if !m.hasHead() {
+		return false, nil
+	}
+	head, err := m.repo.Head()
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	commit, err := m.repo.CommitObject(head.Hash())
+	if err != nil {
+		return false, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+	_, err = tree.FindEntry(filepath.ToSlash(path))
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s in HEAD: %w", path, err)
+	}
+	return true, nil
+}
+
+// inProgressMarkers are the files git keeps in the git dir while an
+// operation that needs the user to finish it is under way.
+var inProgressMarkers = []struct{ path, op string }{
+	{"rebase-merge", "rebase"},
+	{"rebase-apply", "rebase"}, // also `git am`
+	{"MERGE_HEAD", "merge"},
+	{"CHERRY_PICK_HEAD", "cherry-pick"},
+	{"REVERT_HEAD", "revert"},
+}
+
+// InProgressOperation returns the operation the repository is in the middle
+// of ("rebase", "merge", "cherry-pick" or "revert"), or "" if none.
+// Committing during one would interfere with it.
+func (m *Manager) InProgressOperation() (string, error) {
+	gitDir, err := m.GitDir()
+	if err != nil {
+		return "", err
+	}
+	for _, mk := range inProgressMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, mk.path)); err == nil {
+			return mk.op, nil
+		}
+	}
+	return "", nil
+}
+
+// IsPushed reports whether any remote-tracking branch already contains hash.
+func (m *Manager) IsPushed(hash string) (bool, error) {
+	cmd := exec.Command("git", "branch", "-r", "--contains", hash)
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check remote branches for %s: %w", hash, err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// IsPushedTo reports whether one of remote's remote-tracking branches
+// already contains hash.
+func (m *Manager) IsPushedTo(hash, remote string) (bool, error) {
+	cmd := exec.Command("git", "branch", "-r", "--contains", hash, "--list", remote+"/*")
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s branches for %s: %w", remote, hash, err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// GetCommitDiff returns the unified diff introduced by the given commit.
+func (m *Manager) GetCommitDiff(hash string) (string, error) {
+	cmd := exec.Command("git", "show", "--format=", hash)
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff commit %s: %w", hash, err)
+	}
+	return string(output), nil
+}
+
+// CurrentBranch returns the short name of the branch HEAD points to.
+// Returns an error if HEAD is detached.
+func (m *Manager) CurrentBranch() (string, error) {
+	head, err := m.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// Fresh repo with no commits: HEAD is a symbolic ref to an unborn branch
+		sym, symErr := m.repo.Storer.Reference(plumbing.HEAD)
+		if symErr == nil && sym.Type() == plumbing.SymbolicReference {
+			return sym.Target().Short(), nil
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is detached at %s", head.Hash().String()[:7])
+	}
+	return head.Name().Short(), nil
+}
+
+// TargetBranch returns the branch to push: the configured branch, or the
+// current branch when the config is empty or "auto".
+func (m *Manager) TargetBranch() (string, error) {
+	if m.branch != "" && m.branch != "auto" {
+		return m.branch, nil
+	}
+	return m.CurrentBranch()
+}
+
 // Push pushes commits to the configured remote/branch.
 // Falls back to shell git push if go-git auth fails (uses system credential helper).
+// Failures wrap ErrNoRemote, ErrAuthFailed or ErrNonFastForward when recognized.
 func (m *Manager) Push() error {
-	err := m.repo.Push(&gogit.PushOptions{
-		RemoteName: m.remote,
+	return m.PushTo(m.remote)
+}
+
+// PushTo is Push to the named remote instead of the configured one.
+func (m *Manager) PushTo(remote string) error {
+	branch, err := m.TargetBranch()
+	if err != nil {
+		return fmt.Errorf("failed to determine branch to push: %w", err)
+	}
+
+	err = m.repo.Push(&gogit.PushOptions{
+		RemoteName: remote,
 		RefSpecs: []config.RefSpec{
-			config.RefSpec("refs/heads/" + m.branch + ":refs/heads/" + m.branch),
+			config.RefSpec("refs/heads/" + branch + ":refs/heads/" + branch),
 		},
 	})
-	if err == nil {
+	if err == nil || errors.Is(err, gogit.NoErrAlreadyUpToDate) {
 		return nil
 	}
+	if errors.Is(err, gogit.ErrRemoteNotFound) {
+		return remoteError("push", err, "")
+	}
 
 	// fallback to shell git push (uses system credential helper / SSH agent)
-	cmd := exec.Command("git", "push", m.remote, m.branch)
+	cmd := exec.Command("git", "push", remote, branch)
 	cmd.Dir = m.repoPath
 	output, execErr := cmd.CombinedOutput()
 	if execErr != nil {
-		return fmt.Errorf("go-git push failed: %w; git push fallback also failed: %s", err, string(output))
+		return remoteError("push", err, string(output))
 	}
 
 	return nil
 }
 
+// ForcePushTo pushes the target branch to remote even though that replaces
+// commits the remote has (after amending a pushed commit). It uses
+// --force-with-lease, so the push still fails if the remote moved since the
+// last fetch rather than dropping someone else's commits.
+func (m *Manager) ForcePushTo(remote string) error {
+	branch, err := m.TargetBranch()
+	if err != nil {
+		return fmt.Errorf("failed to determine branch to push: %w", err)
+	}
+
+	cmd := exec.Command("git", "push", "--force-with-lease", remote, branch)
+	cmd.Dir = m.repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return remoteError("push", err, string(output))
+	}
+	return nil
+}
+
+// Fetch updates the remote-tracking branches from the configured remote.
+// A repo without that remote has nothing to fetch and returns nil.
+func (m *Manager) Fetch() error {
+	if _, err := m.repo.Remote(m.remote); errors.Is(err, gogit.ErrRemoteNotFound) {
+		return nil
+	}
+
+	// shell git, like the push fallback, so credential helpers / SSH agent work
+	cmd := exec.Command("git", "fetch", "--quiet", m.remote)
+	cmd.Dir = m.repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return remoteError("fetch", err, string(output))
+	}
+	return nil
+}
+
+// IsBehind reports whether the target branch is missing commits that its
+// remote-tracking branch has, and how many. Call Fetch first for an
+// up-to-date answer. A branch the remote doesn't have yet is never behind.
+func (m *Manager) IsBehind() (bool, int, error) {
+	branch, err := m.TargetBranch()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to determine branch: %w", err)
+	}
+	remoteRef := plumbing.NewRemoteReferenceName(m.remote, branch)
+	if _, err := m.repo.Reference(remoteRef, true); err != nil {
+		return false, 0, nil
+	}
+	if !m.hasHead() {
+		return false, 0, nil
+	}
+
+	cmd := exec.Command("git", "rev-list", "--count", "refs/heads/"+branch+".."+remoteRef.String())
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to compare %s with %s: %w", branch, remoteRef.Short(), err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return false, 0, fmt.Errorf("unexpected rev-list output %q: %w", output, err)
+	}
+	return n > 0, n, nil
+}
+
+// Pull rebases the current branch onto the remote's target branch, stashing
+// uncommitted (still pending) changes around the rebase. It refuses with
+// ErrBranchMismatch unless the target branch is the one checked out. A
+// failed rebase is aborted so the repo is left as it was. Failures wrap the
+// same sentinels as Push.
+//
+// The rebase gives local commits new hashes: the returned map takes the old
+// hash of each rewritten commit to its new one, so callers can update what
+// they recorded about them.
+func (m *Manager) Pull() (map[string]string, error) {
+	branch, err := m.TargetBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine branch to pull: %w", err)
+	}
+	current, err := m.CurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the checked-out branch: %w", err)
+	}
+	if current != branch {
+		return nil, fmt.Errorf("pull: %w: %s is checked out, branch is set to %s", ErrBranchMismatch, current, branch)
+	}
+
+	upstream := m.remote + "/" + branch
+	before, err := m.localCommits(upstream)
+	if err != nil {
+		before = nil // upstream not fetched yet: nothing to rewrite
+	}
+
+	cmd := exec.Command("git", "pull", "--rebase", "--autostash", "--quiet", m.remote, branch)
+	cmd.Dir = m.repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		abort := exec.Command("git", "rebase", "--abort")
+		abort.Dir = m.repoPath
+		_ = abort.Run()
+		return nil, remoteError("pull", err, string(output))
+	}
+
+	after, err := m.localCommits(upstream)
+	if err != nil {
+		return nil, nil
+	}
+	rewritten := make(map[string]string)
+	for key, olds := range before {
+		// Commits that look the same (same message in the same second) keep
+		// their order through the rebase, so pair them by position; if some
+		// were dropped (already upstream) there's no telling which
+		news := after[key]
+		if len(news) != len(olds) {
+			continue
+		}
+		for i, old := range olds {
+			if news[i] != old {
+				rewritten[old] = news[i]
+			}
+		}
+	}
+	return rewritten, nil
+}
+
+// localCommits returns the commits in upstream..HEAD keyed by what a rebase
+// keeps of them (author, author date and message), mapped to their hashes
+// oldest first.
+func (m *Manager) localCommits(upstream string) (map[string][]string, error) {
+	cmd := exec.Command("git", "log", "--reverse", "--format=%H%x00%an <%ae> %at%x00%B%x1e", upstream+"..HEAD")
+	cmd.Dir = m.repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits since %s: %w", upstream, err)
+	}
+	commits := make(map[string][]string)
+	for _, entry := range strings.Split(string(output), "\x1e") {
+		hash, key, ok := strings.Cut(strings.TrimSpace(entry), "\x00")
+		if ok {
+			commits[key] = append(commits[key], hash)
+		}
+	}
+	return commits, nil
+}
+
 // ResetStaging unstages all currently staged files.
 func (m *Manager) ResetStaging() error {
 
+	if !m.hasHead() {
+		// No commits yet, so there's nothing to reset to: just empty the index
+		if err := m.repo.Storer.SetIndex(&index.Index{Version: 2}); err != nil {
+			return fmt.Errorf("failed to reset staging: %w", err)
+		}
+		return nil
+	}
+
 	wt, err := m.repo.Worktree()
 
 	if err != nil {
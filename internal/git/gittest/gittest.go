@@ -0,0 +1,166 @@
+// Package gittest sets up throwaway repositories wired to a local bare
+// remote, so push, fetch and pull can be exercised without a network.
+//
+// It is an ordinary package rather than an export_test.go helper in
+// internal/git: GitPulse's tests are the cmd/test* programs, and those can
+// only import non-test packages.
+package gittest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/firasastwani/gitpulse/internal/git"
+)
+
+// Branch is the branch every Remote starts on.
+const Branch = "main"
+
+// Remote is a working repository whose origin is a bare repository in the
+// same directory. Both start with one commit on Branch, already pushed.
+type Remote struct {
+	Dir  string // working tree
+	Bare string // bare repository the "origin" remote points at
+
+	clones int
+}
+
+// New creates dir/repo and dir/origin.git, creating dir if needed. dir is
+// usually a temp directory owned by the caller, who removes it afterwards.
+func New(dir string) (*Remote, error) {
+	r := &Remote{
+		Dir:  filepath.Join(dir, "repo"),
+		Bare: filepath.Join(dir, "origin.git"),
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if _, err := run(dir, "git", "init", "-q", "--bare", "-b", Branch, r.Bare); err != nil {
+		return nil, err
+	}
+	if _, err := run(dir, "git", "init", "-q", "-b", Branch, r.Dir); err != nil {
+		return nil, err
+	}
+	if err := configure(r.Dir); err != nil {
+		return nil, err
+	}
+	if _, err := r.Commit("README.md", "hello\n", "init"); err != nil {
+		return nil, err
+	}
+	if _, err := run(r.Dir, "git", "remote", "add", "origin", r.Bare); err != nil {
+		return nil, err
+	}
+	if _, err := run(r.Dir, "git", "push", "-q", "-u", "origin", Branch); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddRemote creates another bare repository next to the origin, adds it as
+// remote name and pushes Branch to it. Returns the bare repository's path.
+func (r *Remote) AddRemote(name string) (string, error) {
+	bare := filepath.Join(filepath.Dir(r.Bare), name+".git")
+	if _, err := run(filepath.Dir(r.Bare), "git", "init", "-q", "--bare", "-b", Branch, bare); err != nil {
+		return "", err
+	}
+	if _, err := run(r.Dir, "git", "remote", "add", name, bare); err != nil {
+		return "", err
+	}
+	if _, err := run(r.Dir, "git", "push", "-q", name, Branch); err != nil {
+		return "", err
+	}
+	return bare, nil
+}
+
+// Manager opens a git.Manager on the working tree, pushing to origin.
+func (r *Remote) Manager() (*git.Manager, error) {
+	return git.New(r.Dir, "origin", "auto")
+}
+
+// Commit writes content to path in the working tree and commits just that
+// file, returning the new commit hash. Nothing is pushed.
+func (r *Remote) Commit(path, content, message string) (string, error) {
+	return commitFile(r.Dir, path, content, message)
+}
+
+// PushElsewhere commits path from a fresh clone of the remote and pushes it,
+// as another machine would, leaving the working repository behind. Returns
+// the pushed commit hash.
+func (r *Remote) PushElsewhere(path, content, message string) (string, error) {
+	r.clones++
+	clone := filepath.Join(filepath.Dir(r.Dir), fmt.Sprintf("clone-%d", r.clones))
+	if _, err := run(filepath.Dir(r.Dir), "git", "clone", "-q", r.Bare, clone); err != nil {
+		return "", err
+	}
+	if err := configure(clone); err != nil {
+		return "", err
+	}
+	hash, err := commitFile(clone, path, content, message)
+	if err != nil {
+		return "", err
+	}
+	if _, err := run(clone, "git", "push", "-q", "origin", Branch); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// RemoteHead returns the commit Branch points at in the bare repository.
+func (r *Remote) RemoteHead() (string, error) {
+	return run(r.Bare, "git", "rev-parse", Branch)
+}
+
+// Head returns the working repository's HEAD commit.
+func (r *Remote) Head() (string, error) {
+	return run(r.Dir, "git", "rev-parse", "HEAD")
+}
+
+// Git runs git in the working tree and returns its trimmed output.
+func (r *Remote) Git(args ...string) (string, error) {
+	return run(r.Dir, "git", args...)
+}
+
+// configure sets a committer identity so commits work on machines without
+// a global git config.
+func configure(dir string) error {
+	if _, err := run(dir, "git", "config", "user.email", "test@gitpulse"); err != nil {
+		return err
+	}
+	_, err := run(dir, "git", "config", "user.name", "test")
+	return err
+}
+
+func commitFile(dir, path, content, message string) (string, error) {
+	full := filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := run(dir, "git", "add", "--", path); err != nil {
+		return "", err
+	}
+	if _, err := run(dir, "git", "commit", "-q", "-m", message); err != nil {
+		return "", err
+	}
+	return run(dir, "git", "rev-parse", "HEAD")
+}
+
+// run runs a command in dir and returns its trimmed stdout. Errors include
+// the command and its stderr.
+func run(dir, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,75 @@
+package gittest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Checks prints a PASS or FAIL line per check and remembers whether any
+// failed, so a harness can exit 1 at the end.
+type Checks struct {
+	failed bool
+}
+
+// Check prints PASS name when ok, otherwise FAIL name with got (quoted when
+// it's a string).
+func (c *Checks) Check(name string, ok bool, got interface{}) {
+	if ok {
+		fmt.Printf("  PASS  %s\n", name)
+		return
+	}
+	c.failed = true
+	if _, isString := got.(string); isString {
+		fmt.Printf("  FAIL  %s (got %q)\n", name, got)
+		return
+	}
+	fmt.Printf("  FAIL  %s (got %v)\n", name, got)
+}
+
+// Failed reports whether any check failed.
+func (c *Checks) Failed() bool {
+	return c.failed
+}
+
+// InitRepo runs git init on branch Branch in dir with a committer identity,
+// exiting on failure.
+func InitRepo(dir string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		Fail("create "+dir, err)
+	}
+	Run(dir, "git", "init", "-q", "-b", Branch)
+	if err := configure(dir); err != nil {
+		Fail("configure git identity", err)
+	}
+}
+
+// Run runs a command in dir and returns its combined output, exiting on
+// failure.
+func Run(dir string, name string, args ...string) string {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		Fail(name+" "+strings.Join(args, " ")+": "+string(out), err)
+	}
+	return string(out)
+}
+
+// Write writes content to path, creating its directory, exiting on failure.
+func Write(path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		Fail("mkdir", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		Fail("write "+path, err)
+	}
+}
+
+// Fail reports that a harness couldn't do what and exits 1.
+func Fail(what string, err error) {
+	fmt.Fprintf(os.Stderr, "Failed to %s: %v\n", what, err)
+	os.Exit(1)
+}
@@ -0,0 +1,42 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// CommitInfo describes a commit in the repo's history.
+type CommitInfo struct {
+	Hash    string
+	Message string
+	Author  string
+	When    time.Time // author date
+}
+
+// CommitsSince returns the commits reachable from HEAD but not from since,
+// oldest first: what was committed after since was HEAD. At most max of the
+// newest are returned.
+func (m *Manager) CommitsSince(since string, max int) ([]CommitInfo, error) {
+	out, err := m.gitOutput(nil, "rev-list", "--reverse", "--max-count="+strconv.Itoa(max), since+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits since %s: %w", since, err)
+	}
+	var commits []CommitInfo
+	for _, hash := range strings.Fields(out) {
+		c, err := m.repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    hash,
+			Message: strings.TrimSpace(c.Message),
+			Author:  c.Author.Name,
+			When:    c.Author.When,
+		})
+	}
+	return commits, nil
+}
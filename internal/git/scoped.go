@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// CommitFilesAt commits exactly files (as they are in the working tree) with
+// the given author date, like `git commit --only -- files`: whatever else is
+// staged, inside or outside the watch root, is neither committed nor
+// unstaged. A *StageError with Staged > 0 comes back alongside the hash when
+// only some files could be staged; ErrNothingToCommit when none differ from
+// HEAD.
+func (m *Manager) CommitFilesAt(files []string, message string, authorTime time.Time) (string, error) {
+	var hash string
+	stageErr, err := m.scoped(files, func() error {
+		var err error
+		hash, err = m.CommitAt(message, authorTime)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if stageErr != nil {
+		return hash, stageErr
+	}
+	return hash, nil
+}
+
+// scoped runs commit against an index holding HEAD plus only files, then puts
+// back the user's index with just those paths updated to what was committed.
+// Staging failures are returned as stageErr (nil if none); if no file could
+// be staged, commit isn't called and stageErr comes back as err.
+func (m *Manager) scoped(files []string, commit func() error) (stageErr error, err error) {
+	saved, err := m.repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	saved = copyIndex(saved)
+
+	if err := m.ResetStaging(); err != nil {
+		return nil, err
+	}
+	stageErr = m.StageFiles(files)
+	if stageErr != nil && !PartiallyStaged(stageErr) {
+		m.restoreIndex(saved, nil)
+		return nil, stageErr
+	}
+
+	if err := commit(); err != nil {
+		m.restoreIndex(saved, nil)
+		return nil, err
+	}
+
+	committed, err := m.repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	scopedEntries := make(map[string]*index.Entry, len(files))
+	for _, f := range files {
+		scopedEntries[f] = nil // deleted unless the committed index has it
+		if e, err := committed.Entry(f); err == nil {
+			scopedEntries[f] = e
+		}
+	}
+	if err := m.restoreIndex(saved, scopedEntries); err != nil {
+		return stageErr, err
+	}
+	return stageErr, nil
+}
+
+// restoreIndex writes saved back as the index, with each path in updates
+// replaced by its entry (or removed when the entry is nil).
+func (m *Manager) restoreIndex(saved *index.Index, updates map[string]*index.Entry) error {
+	if len(updates) > 0 {
+		kept := saved.Entries[:0]
+		for _, e := range saved.Entries {
+			if _, ok := updates[e.Name]; !ok {
+				kept = append(kept, e)
+			}
+		}
+		saved.Entries = kept
+		for _, e := range updates {
+			if e != nil {
+				saved.Entries = append(saved.Entries, e)
+			}
+		}
+		sort.Slice(saved.Entries, func(i, j int) bool { return saved.Entries[i].Name < saved.Entries[j].Name })
+	}
+	// The cached tree describes the old index, not this one
+	saved.Cache = nil
+	if err := m.repo.Storer.SetIndex(saved); err != nil {
+		return fmt.Errorf("failed to restore staged changes: %w", err)
+	}
+	return nil
+}
+
+// copyIndex deep-copies the entries of idx so later index writes can't change them.
+func copyIndex(idx *index.Index) *index.Index {
+	c := &index.Index{Version: idx.Version, Entries: make([]*index.Entry, len(idx.Entries))}
+	if c.Version == 0 {
+		c.Version = 2
+	}
+	for i, e := range idx.Entries {
+		entry := *e
+		c.Entries[i] = &entry
+	}
+	return c
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// Submodule is a submodule of the repo, with the commit the parent records
+// for it (its gitlink) and the commit actually checked out.
+type Submodule struct {
+	Path     string
+	Recorded string // "" for a newly added submodule
+	Current  string // "" when not initialized
+}
+
+// Moved reports whether the submodule's checked-out commit differs from the
+// recorded one, i.e. there is a pointer update to commit.
+func (s Submodule) Moved() bool {
+	return s.Current != "" && s.Current != s.Recorded
+}
+
+// Submodules lists the repo's submodules, sorted by path. Recorded comes
+// from the index, so staging Path with StageFiles (or CommitFilesAt) records
+// Current as the new pointer.
+func (m *Manager) Submodules() ([]Submodule, error) {
+	wt, err := m.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	subs, err := wt.Submodules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read submodules: %w", err)
+	}
+	status, err := subs.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submodule status: %w", err)
+	}
+
+	list := make([]Submodule, 0, len(status))
+	for _, s := range status {
+		sub := Submodule{Path: s.Path}
+		if !s.Expected.IsZero() {
+			sub.Recorded = s.Expected.String()
+		}
+		if !s.Current.IsZero() {
+			sub.Current = s.Current.String()
+		}
+		list = append(list, sub)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list, nil
+}
+
+// submoduleHeads maps each initialized submodule's path to its checked-out
+// commit, so StageFiles can stage it as a gitlink instead of walking into it.
+func (m *Manager) submoduleHeads() map[string]plumbing.Hash {
+	subs, err := m.Submodules()
+	if err != nil {
+		return nil
+	}
+	heads := make(map[string]plumbing.Hash, len(subs))
+	for _, s := range subs {
+		if s.Current != "" {
+			heads[s.Path] = plumbing.NewHash(s.Current)
+		}
+	}
+	return heads
+}
+
+// stageGitlink points the index entry for a submodule at commit.
+func (m *Manager) stageGitlink(path string, commit plumbing.Hash) error {
+	idx, err := m.repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	e, err := idx.Entry(path)
+	if err == index.ErrEntryNotFound {
+		e = idx.Add(path)
+		sort.Slice(idx.Entries, func(i, j int) bool { return idx.Entries[i].Name < idx.Entries[j].Name })
+	} else if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	e.Hash = commit
+	e.Mode = filemode.Submodule
+
+	if err := m.repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to stage submodule: %w", err)
+	}
+	return nil
+}
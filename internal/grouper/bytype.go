@@ -0,0 +1,55 @@
+package grouper
+
+// GroupByType regroups files by the conventional-commit type of their change
+// (grouping_strategy): one group per type across all groups, or with perGroup
+// (hybrid) one per type within each group. types maps paths to types; a file
+// without one is "chore". Groups come out in the order their types first
+// appear, without commit messages.
+func GroupByType(groups []FileGroup, types map[string]string, perGroup bool) []FileGroup {
+	if perGroup {
+		var result []FileGroup
+		for _, g := range groups {
+			for _, typed := range GroupByType([]FileGroup{g}, types, false) {
+				typed.Reason = g.Reason + " (" + typed.Type + ")"
+				result = append(result, typed)
+			}
+		}
+		return result
+	}
+
+	fileDiffs := FileDiffs(groups)
+	var order []string
+	byType := make(map[string][]string)
+	for _, g := range groups {
+		for _, f := range g.Files {
+			typ := types[f]
+			if typ == "" {
+				typ = "chore"
+			}
+			if _, ok := byType[typ]; !ok {
+				order = append(order, typ)
+			}
+			byType[typ] = append(byType[typ], f)
+		}
+	}
+
+	result := make([]FileGroup, 0, len(order))
+	for _, typ := range order {
+		g := rebuiltGroup(byType[typ], "type: "+typ, fileDiffs)
+		g.Type = typ
+		result = append(result, g)
+	}
+	return result
+}
+
+// CopyMessages gives each group in groups the commit message (and model) of
+// the group in from that has exactly the same files, if there is one. Others
+// are left as they are.
+func CopyMessages(groups, from []FileGroup) {
+	for i := range groups {
+		if g, ok := findGroup(from, groups[i].Files); ok && g.CommitMessage != "" {
+			groups[i].CommitMessage = g.CommitMessage
+			groups[i].Model = g.Model
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package grouper
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// GeneratedRule ties generated files to the source they are generated from,
+// e.g. Source "*.proto" with Generated ["*.pb.go"]: the "*" in Source
+// captures a stem (schema.proto -> "schema") that is substituted into each
+// Generated pattern (schema.pb.go), matched in any directory.
+type GeneratedRule struct {
+	Source    string
+	Generated []string
+}
+
+// ApplyGenerated moves each changed generated file into the group of the
+// changed source it was generated from, so they are committed together even
+// across directories. The receiving group's Reason notes the relationship.
+// Groups whose file set changed get their diffs rebuilt and their
+// CommitMessage/Model cleared; untouched groups are returned as-is, so
+// applying the same rules twice is a no-op. A generated file whose source
+// didn't change is left where it is.
+func ApplyGenerated(groups []FileGroup, rules []GeneratedRule) []FileGroup {
+	if len(rules) == 0 {
+		return groups
+	}
+
+	fileDiffs := FileDiffs(groups)
+	work := make([][]string, len(groups))
+	home := make(map[string]int) // file -> index into work
+	var order []string
+	for i, g := range groups {
+		work[i] = append([]string(nil), g.Files...)
+		for _, f := range g.Files {
+			home[f] = i
+			order = append(order, f)
+		}
+	}
+
+	notes := make(map[int][]string)
+	claimed := make(map[string]bool) // generated files already placed with a source
+	for _, src := range order {
+		for _, r := range rules {
+			stem, ok := matchStem(r.Source, src)
+			if !ok {
+				continue
+			}
+			for _, gen := range order {
+				if gen == src || claimed[gen] || !matchGenerated(r.Generated, stem, gen) {
+					continue
+				}
+				claimed[gen] = true
+				from, to := home[gen], home[src]
+				if from == to {
+					continue
+				}
+				work[from] = removeString(work[from], gen)
+				work[to] = append(work[to], gen)
+				home[gen] = to
+				notes[to] = append(notes[to], filepath.Base(gen)+" generated from "+filepath.Base(src))
+			}
+		}
+	}
+
+	var result []FileGroup
+	for i, g := range groups {
+		if len(work[i]) == 0 {
+			continue
+		}
+		if sameFiles(g.Files, work[i]) {
+			result = append(result, g)
+			continue
+		}
+		reason := g.Reason
+		if n := notes[i]; len(n) > 0 {
+			note := strings.Join(n, ", ")
+			if reason == "" {
+				reason = note
+			} else {
+				reason += "; " + note
+			}
+		}
+		result = append(result, rebuiltGroup(work[i], reason, fileDiffs))
+	}
+	return result
+}
+
+// matchStem matches path's file name (or the whole path) against a source
+// pattern and returns what its first "*" covered.
+func matchStem(pattern, path string) (string, bool) {
+	name := filepath.Base(path)
+	if strings.Contains(pattern, "/") {
+		name = path
+	}
+	if matched, _ := filepath.Match(pattern, name); !matched {
+		return "", false
+	}
+	star := strings.Index(pattern, "*")
+	if star < 0 {
+		return "", true
+	}
+	// Everything after the star is matched literally or by glob; find the
+	// longest suffix of name the rest of the pattern matches
+	rest := pattern[star+1:]
+	for i := len(name); i >= star; i-- {
+		if matched, _ := filepath.Match(rest, name[i:]); matched {
+			return name[star:i], true
+		}
+	}
+	return "", true
+}
+
+// matchGenerated reports whether path matches any generated pattern once the
+// source's stem is substituted for its "*".
+func matchGenerated(patterns []string, stem, path string) bool {
+	for _, p := range patterns {
+		p = strings.Replace(p, "*", stem, 1)
+		if matched, _ := filepath.Match(p, filepath.Base(path)); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(p, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// removeString returns list without s (first occurrence).
+func removeString(list []string, s string) []string {
+	for i, v := range list {
+		if v == s {
+			return append(list[:i:i], list[i+1:]...)
+		}
+	}
+	return list
+}
@@ -16,17 +16,39 @@ type FileGroup struct {
 	Reason        string   // why these files are grouped (e.g., "same package: internal/auth")
 	Diffs         string   // combined unified diff for all files in group
 	CommitMessage string   // AI-generated commit message (populated after AI refinement)
+	Model         string   // AI model that produced CommitMessage ("" if not AI-generated)
+	Scope         string   // suggested conventional-commit scope (see ResolveScope); "" for none
+	Type          string   // conventional-commit type shared by every file (see GroupByType); "" if not grouped by type
 }
 
-// PreGroup clusters changed files using heuristic rules.
+// TypeRule clusters files matching any of Patterns (globs matched against the
+// file name or the repo-relative path) under a shared name, e.g. "docs".
+type TypeRule struct {
+	Name     string
+	Patterns []string
+}
+
+// Options tunes the heuristic grouping passes.
+type Options struct {
+	TypeRules []TypeRule // file type clustering for would-be singletons
+	RootDepth int        // group by the first RootDepth directories (e.g. 2 = services/a) instead of the parent dir; 0 = parent dir
+}
+
+// PreGroup clusters changed files using heuristic rules with default options.
+func PreGroup(changeset watcher.ChangeSet) []FileGroup {
+	return PreGroupWithOptions(changeset, Options{})
+}
+
+// PreGroupWithOptions clusters changed files using heuristic rules.
 // This is Phase 1 (local, instant) before AI refinement.
 //
 // Rules applied in order:
-//  1. Same directory/package -> grouped together
+//  1. Same directory/package -> grouped together (or the same top-level
+//     directory, opts.RootDepth segments deep, for monorepos)
 //  2. Name affinity (foo.go + foo_test.go) -> merged into same group
 //  3. File type clustering (configs together, docs together)
 //  4. Singleton fallback for unmatched files
-func PreGroup(changeset watcher.ChangeSet) []FileGroup {
+func PreGroupWithOptions(changeset watcher.ChangeSet, opts Options) []FileGroup {
 	// TODO: Implement heuristic grouping
 	//
 	// Step 1: Group by directory
@@ -43,7 +65,7 @@ func PreGroup(changeset watcher.ChangeSet) []FileGroup {
 
 	// hashmap dir -> group of files
 	for _, fc := range changeset.Files {
-		dir := filepath.Dir(fc.Path)
+		dir := groupDir(fc.Path, opts.RootDepth)
 		dirGroups[dir] = append(dirGroups[dir], fc.Path)
 	}
 
@@ -55,18 +77,16 @@ func PreGroup(changeset watcher.ChangeSet) []FileGroup {
 	for dir, files := range dirGroups {
 		bases := make(map[string]bool)
 
+		// stems keep their directory, so with RootDepth a test only pairs
+		// with the source file next to it
 		for _, f := range files {
-			name := filepath.Base(f)
-			ext := filepath.Ext(name)
-			stem := strings.TrimSuffix(name, ext)
+			stem := strings.TrimSuffix(f, filepath.Ext(f))
 			stem = strings.TrimSuffix(stem, "_test")
 			bases[stem] = true
 		}
 
 		for _, f := range files {
-			name := filepath.Base(f)
-			ext := filepath.Ext(name)
-			stem := strings.TrimSuffix(name, ext)
+			stem := strings.TrimSuffix(f, filepath.Ext(f))
 			if strings.HasSuffix(stem, "_test") {
 				sourceStem := strings.TrimSuffix(stem, "_test")
 				if bases[sourceStem] {
@@ -82,6 +102,9 @@ func PreGroup(changeset watcher.ChangeSet) []FileGroup {
 	for dir, files := range dirGroups {
 		if len(files) > 1 || merged[files[0]] {
 			reason := "same package: " + dir
+			if opts.RootDepth > 0 {
+				reason = "same root: " + dir
+			}
 			if affinityDirs[dir] {
 				reason = "name affinity: " + dir
 			}
@@ -100,6 +123,37 @@ func PreGroup(changeset watcher.ChangeSet) []FileGroup {
 		}
 	}
 
+	// set 3, file type clustering for files that would otherwise be singletons
+	typeGroups := make(map[string][]string)
+	var typeOrder []string
+	typeSeen := make(map[string]bool)
+	for _, fc := range changeset.Files {
+		if grouped[fc.Path] || typeSeen[fc.Path] {
+			continue
+		}
+		typeSeen[fc.Path] = true
+		if name := matchTypeRule(fc.Path, opts.TypeRules); name != "" {
+			if _, ok := typeGroups[name]; !ok {
+				typeOrder = append(typeOrder, name)
+			}
+			typeGroups[name] = append(typeGroups[name], fc.Path)
+		}
+	}
+	for _, name := range typeOrder {
+		files := typeGroups[name]
+		if len(files) < 2 {
+			continue // a lone match is still just a singleton
+		}
+		groups = append(groups, FileGroup{
+			Files:  files,
+			Reason: "file type: " + name,
+		})
+		for _, f := range files {
+			grouped[f] = true
+		}
+	}
+
+	// set 4, singletons
 	for _, fc := range changeset.Files {
 		if !grouped[fc.Path] {
 			groups = append(groups, FileGroup{
@@ -111,3 +165,112 @@ func PreGroup(changeset watcher.ChangeSet) []FileGroup {
 
 	return groups
 }
+
+// groupDir returns the directory path is grouped under: its parent, cut to
+// the first depth segments when depth > 0 and the parent is deeper.
+func groupDir(path string, depth int) string {
+	dir := filepath.Dir(path)
+	if depth <= 0 {
+		return dir
+	}
+	parts := strings.Split(filepath.ToSlash(dir), "/")
+	if len(parts) <= depth {
+		return dir
+	}
+	return filepath.FromSlash(strings.Join(parts[:depth], "/"))
+}
+
+// matchTypeRule returns the name of the first rule whose patterns match path, or "".
+func matchTypeRule(path string, rules []TypeRule) string {
+	base := filepath.Base(path)
+	for _, r := range rules {
+		for _, pattern := range r.Patterns {
+			if matched, _ := filepath.Match(pattern, base); matched {
+				return r.Name
+			}
+			if matched, _ := filepath.Match(pattern, path); matched {
+				return r.Name
+			}
+		}
+	}
+	return ""
+}
+
+// FileDiffs splits each group's combined diff into per-file diffs, keyed by path.
+func FileDiffs(groups []FileGroup) map[string]string {
+	fileDiffs := make(map[string]string)
+	for _, g := range groups {
+		if len(g.Files) == 1 {
+			// Single file group: entire diff belongs to that file
+			fileDiffs[g.Files[0]] = g.Diffs
+			continue
+		}
+		// Multiple files: split diff by file headers
+		for _, section := range strings.Split(g.Diffs, "diff --git") {
+			if section == "" {
+				continue
+			}
+			section = "diff --git" + section
+			for _, f := range g.Files {
+				if strings.Contains(section, " a/"+f+" ") || strings.Contains(section, " b/"+f+" ") {
+					fileDiffs[f] = section
+					break
+				}
+			}
+		}
+	}
+	return fileDiffs
+}
+
+// Reconcile checks refined groups (e.g. from the AI) against the input groups
+// so no file is lost or invented: unknown and duplicate paths are dropped,
+// empty groups removed, and input files missing from the output are appended
+// as singleton groups (with their diffs, but no commit message).
+// Returns the reconciled groups plus the missing and unknown paths.
+func Reconcile(input, refined []FileGroup) (groups []FileGroup, missing, unknown []string) {
+	known := make(map[string]bool)
+	for _, g := range input {
+		for _, f := range g.Files {
+			known[f] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, g := range refined {
+		var files []string
+		for _, f := range g.Files {
+			if !known[f] {
+				unknown = append(unknown, f)
+				continue
+			}
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			files = append(files, f)
+		}
+		if len(files) == 0 {
+			continue
+		}
+		g.Files = files
+		groups = append(groups, g)
+	}
+
+	fileDiffs := FileDiffs(input)
+	for _, g := range input {
+		for _, f := range g.Files {
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			missing = append(missing, f)
+			groups = append(groups, FileGroup{
+				Files:  []string{f},
+				Reason: "singletons " + filepath.Base(f),
+				Diffs:  fileDiffs[f],
+			})
+		}
+	}
+
+	return groups, missing, unknown
+}
@@ -0,0 +1,148 @@
+package grouper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Overrides force files out of or into groups regardless of the heuristics
+// (or the AI refinement).
+type Overrides struct {
+	// Separate pulls files matching a rule out of whatever group they're in,
+	// into one group per rule holding only those files (e.g. go.mod + go.sum).
+	Separate []TypeRule
+	// Together moves files matching a rule into a single group (the first
+	// one containing a match), e.g. a .proto file and its generated code.
+	Together []TypeRule
+}
+
+// ApplyOverrides rewrites groups so the overrides hold. Groups whose file set
+// changed get their diffs rebuilt and their CommitMessage/Model cleared so the
+// caller can regenerate them; untouched groups are returned as-is, so applying
+// the same overrides twice is a no-op.
+//
+// When a file matches both a Separate and a Together rule, Separate wins and
+// the clash is reported in conflicts. Within each list the first matching rule
+// wins.
+func ApplyOverrides(groups []FileGroup, o Overrides) (result []FileGroup, conflicts []string) {
+	if len(o.Separate) == 0 && len(o.Together) == 0 {
+		return groups, nil
+	}
+
+	fileDiffs := FileDiffs(groups)
+
+	// Phase 1: pull force_separate files out into their own groups
+	var sepOrder []string
+	sepFiles := make(map[string][]string)
+	work := make([][]string, len(groups))
+	for i, g := range groups {
+		for _, f := range g.Files {
+			sep := matchTypeRule(f, o.Separate)
+			if sep == "" {
+				work[i] = append(work[i], f)
+				continue
+			}
+			if tog := matchTypeRule(f, o.Together); tog != "" {
+				conflicts = append(conflicts, fmt.Sprintf("%s: force_separate %q overrides force_together %q", f, sep, tog))
+			}
+			if _, ok := sepFiles[sep]; !ok {
+				sepOrder = append(sepOrder, sep)
+			}
+			sepFiles[sep] = append(sepFiles[sep], f)
+		}
+	}
+
+	// Phase 2: gather force_together files into the first group holding a match
+	target := make(map[string]int)
+	togName := make(map[int]string)
+	for i := range work {
+		var kept []string
+		for _, f := range work[i] {
+			tog := matchTypeRule(f, o.Together)
+			if tog == "" {
+				kept = append(kept, f)
+				continue
+			}
+			t, ok := target[tog]
+			if !ok {
+				target[tog] = i
+				kept = append(kept, f)
+				continue
+			}
+			if t == i {
+				kept = append(kept, f)
+				continue
+			}
+			work[t] = append(work[t], f)
+			togName[t] = tog
+		}
+		work[i] = kept
+	}
+
+	for i, g := range groups {
+		if len(work[i]) == 0 {
+			continue
+		}
+		if sameFiles(g.Files, work[i]) {
+			result = append(result, g)
+			continue
+		}
+		reason := g.Reason
+		if name, ok := togName[i]; ok {
+			reason = "forced together: " + name
+		}
+		result = append(result, rebuiltGroup(work[i], reason, fileDiffs))
+	}
+
+	for _, name := range sepOrder {
+		files := sepFiles[name]
+		if g, ok := findGroup(groups, files); ok {
+			result = append(result, g) // already exactly this group
+			continue
+		}
+		result = append(result, rebuiltGroup(files, "forced separate: "+name, fileDiffs))
+	}
+
+	return result, conflicts
+}
+
+// rebuiltGroup assembles a group from per-file diffs, with no commit message.
+func rebuiltGroup(files []string, reason string, fileDiffs map[string]string) FileGroup {
+	var diffs strings.Builder
+	for _, f := range files {
+		diffs.WriteString(fileDiffs[f])
+	}
+	return FileGroup{
+		Files:  files,
+		Reason: reason,
+		Diffs:  diffs.String(),
+	}
+}
+
+// findGroup returns the group whose file set is exactly files, if any.
+func findGroup(groups []FileGroup, files []string) (FileGroup, bool) {
+	for _, g := range groups {
+		if sameFiles(g.Files, files) {
+			return g, true
+		}
+	}
+	return FileGroup{}, false
+}
+
+// sameFiles reports whether a and b hold the same paths, in any order.
+func sameFiles(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string(nil), a...)
+	y := append([]string(nil), b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package grouper
+
+import (
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveScope suggests a conventional-commit scope for files (relative to
+// root): the Go package name when the group's Go files all declare the same
+// package (the directory name for package main), otherwise the deepest
+// directory the files share. Returns "" when they only share the repo root.
+func ResolveScope(root string, files []string) string {
+	if pkg := goPackage(root, files); pkg != "" {
+		return pkg
+	}
+	return CommonDir(files)
+}
+
+// goPackage returns the package the Go files among files declare, or "" if
+// there are none, they disagree or none can be read (e.g. all deleted).
+// External test packages (foo_test) count as the package they test.
+func goPackage(root string, files []string) string {
+	var pkg string
+	fset := token.NewFileSet()
+	for _, f := range files {
+		if filepath.Ext(f) != ".go" {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(root, f), nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(file.Name.Name, "_test")
+		if name == "main" {
+			name = path.Base(path.Dir(filepath.ToSlash(f))) // "." at the repo root
+		}
+		if pkg != "" && name != pkg {
+			return ""
+		}
+		pkg = name
+	}
+	if pkg == "." {
+		return ""
+	}
+	return pkg
+}
+
+// CommonDir returns the deepest directory shared by all files, or "" if they
+// only share the repo root.
+func CommonDir(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	common := path.Dir(filepath.ToSlash(files[0]))
+	for _, f := range files[1:] {
+		dir := path.Dir(filepath.ToSlash(f))
+		for common != "." && dir != common && !strings.HasPrefix(dir, common+"/") {
+			common = path.Dir(common)
+		}
+	}
+	if common == "." {
+		return ""
+	}
+	return common
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Notifier sends short alerts to the desktop and/or a Slack incoming
+// webhook. The zero value (or one with nothing configured) does nothing.
+type Notifier struct {
+	desktop      bool
+	slackWebhook string
+	client       *http.Client
+}
+
+// New creates a Notifier. desktop uses notify-send (Linux) or osascript
+// (macOS); slackWebhook is a Slack incoming webhook URL ("" = off).
+func New(desktop bool, slackWebhook string) *Notifier {
+	return &Notifier{
+		desktop:      desktop,
+		slackWebhook: slackWebhook,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Enabled reports whether any notification channel is configured.
+func (n *Notifier) Enabled() bool {
+	return n != nil && (n.desktop || n.slackWebhook != "")
+}
+
+// Send delivers title and message to every configured channel. A failing
+// channel doesn't stop the others; their errors are joined.
+func (n *Notifier) Send(title, message string) error {
+	if !n.Enabled() {
+		return nil
+	}
+	var errs []error
+	if n.desktop {
+		if err := sendDesktop(title, message); err != nil {
+			errs = append(errs, fmt.Errorf("desktop notification failed: %w", err))
+		}
+	}
+	if n.slackWebhook != "" {
+		if err := n.sendSlack(title, message); err != nil {
+			errs = append(errs, fmt.Errorf("slack notification failed: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendDesktop shows a desktop notification with the platform's own tool.
+func sendDesktop(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("notify-send", title, message)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", "on run argv\ndisplay notification (item 2 of argv) with title (item 1 of argv)\nend run", title, message)
+	default:
+		return fmt.Errorf("not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// sendSlack posts the alert to the incoming webhook.
+func (n *Notifier) sendSlack(title, message string) error {
+	body, err := json.Marshal(map[string]string{"text": "*" + title + "*\n" + message})
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.slackWebhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"os"
+	"time"
+)
+
+// CompactOptions picks what Compact does to the history.
+type CompactOptions struct {
+	StripDiffs bool          // drop the stored diffs of old records, keeping their metadata and line stats
+	OlderThan  time.Duration // only records created more than this long ago (0 = every record)
+	DryRun     bool          // count what would be reclaimed without writing anything
+}
+
+// CompactResult reports what Compact did (or, with DryRun, would do).
+type CompactResult struct {
+	Records     int   // records that had diffs stripped
+	Files       int   // file diffs stripped
+	BytesBefore int64 // history file size before
+	BytesAfter  int64 // history file size after
+}
+
+// Reclaimed is how many bytes the history file shrank by. It is negative
+// when rewriting a hand-edited (unindented) file made it bigger.
+func (r CompactResult) Reclaimed() int64 {
+	return r.BytesBefore - r.BytesAfter
+}
+
+// Compact shrinks the history file: with StripDiffs it empties the Diff of
+// every file in records older than OlderThan and marks it DiffCompacted.
+// Line counts, DiffBytes and HunkCount are kept, so stats and the dashboard
+// timeline are unchanged; only the diff view of those commits is lost. The
+// file is rewritten even when nothing was stripped. An in-memory store
+// reports sizes of 0.
+func (s *Store) Compact(opts CompactOptions) (CompactResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result CompactResult
+	if s.path != "" {
+		if info, err := os.Stat(s.path); err == nil {
+			result.BytesBefore = info.Size()
+		}
+	}
+
+	records := make([]CommitRecord, len(s.records))
+	copy(records, s.records)
+	if opts.StripDiffs {
+		cutoff := time.Now().Add(-opts.OlderThan)
+		for i := range records {
+			if !records[i].CreatedAt.Before(cutoff) {
+				continue
+			}
+			stripped := 0
+			files := make([]FileChange, len(records[i].Files))
+			for j, f := range records[i].Files {
+				if f.Diff != "" {
+					f.Diff = ""
+					f.DiffCompacted = true
+					stripped++
+				}
+				files[j] = f
+			}
+			if stripped > 0 {
+				records[i].Files = files
+				result.Records++
+				result.Files += stripped
+			}
+		}
+	}
+
+	if opts.DryRun {
+		data, err := encodeHistory(records)
+		if err != nil {
+			return result, err
+		}
+		if s.path != "" {
+			result.BytesAfter = int64(len(data))
+		}
+		return result, nil
+	}
+
+	s.records = records
+	if err := s.flush(); err != nil {
+		return result, err
+	}
+	if s.path != "" {
+		if info, err := os.Stat(s.path); err == nil {
+			result.BytesAfter = info.Size()
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dismissal records a review finding the user marked as a false positive so
+// later reviews don't block on it again.
+type Dismissal struct {
+	Key         string    `json:"key"` // hash of file + line range + description
+	File        string    `json:"file"`
+	StartLine   int       `json:"start_line"`
+	EndLine     int       `json:"end_line"`
+	Description string    `json:"description"`
+	DismissedAt time.Time `json:"dismissed_at"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"` // zero = never expires
+}
+
+// Dismissals persists dismissed review findings to .gitpulse/dismissed.json.
+type Dismissals struct {
+	mu      sync.Mutex
+	path    string
+	entries []Dismissal
+}
+
+// DismissalKey identifies a finding by file, line range and description.
+// Whitespace and case in the description are normalized so the same finding
+// still matches when the model varies spacing or capitalization.
+func DismissalKey(file string, startLine, endLine int, description string) string {
+	desc := strings.ToLower(strings.Join(strings.Fields(description), " "))
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d-%d\x00%s", file, startLine, endLine, desc)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NewDismissals opens (or lazily creates) the dismissal file at path.
+// Expired entries are dropped on load. An empty path keeps dismissals in
+// memory only.
+func NewDismissals(path string) (*Dismissals, error) {
+	d := &Dismissals{path: path}
+	if path == "" {
+		return d, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return d, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &d.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	d.pruneLocked(time.Now())
+	return d, nil
+}
+
+// Add dismisses a finding. A ttl of 0 means the dismissal never expires.
+// Re-dismissing an existing finding refreshes its expiry.
+func (d *Dismissals) Add(file string, startLine, endLine int, description string, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	entry := Dismissal{
+		Key:         DismissalKey(file, startLine, endLine, description),
+		File:        file,
+		StartLine:   startLine,
+		EndLine:     endLine,
+		Description: description,
+		DismissedAt: now,
+	}
+	if ttl > 0 {
+		entry.ExpiresAt = now.Add(ttl)
+	}
+
+	replaced := false
+	for i := range d.entries {
+		if d.entries[i].Key == entry.Key {
+			d.entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		d.entries = append(d.entries, entry)
+	}
+	return d.flushLocked()
+}
+
+// IsDismissed reports whether a finding was dismissed and hasn't expired.
+func (d *Dismissals) IsDismissed(file string, startLine, endLine int, description string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := DismissalKey(file, startLine, endLine, description)
+	now := time.Now()
+	for _, e := range d.entries {
+		if e.Key == key && (e.ExpiresAt.IsZero() || now.Before(e.ExpiresAt)) {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns the active (unexpired) dismissals.
+func (d *Dismissals) All() []Dismissal {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pruneLocked(time.Now())
+	return append([]Dismissal(nil), d.entries...)
+}
+
+// Clear removes every dismissal, so all findings block again.
+func (d *Dismissals) Clear() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = nil
+	if d.path == "" {
+		return nil
+	}
+	if err := os.Remove(d.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (d *Dismissals) pruneLocked(now time.Time) {
+	kept := d.entries[:0]
+	for _, e := range d.entries {
+		if e.ExpiresAt.IsZero() || now.Before(e.ExpiresAt) {
+			kept = append(kept, e)
+		}
+	}
+	d.entries = kept
+}
+
+func (d *Dismissals) flushLocked() error {
+	d.pruneLocked(time.Now())
+	if d.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(d.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.path, data, 0644)
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// schemaVersion is the history file format this build reads and writes.
+//
+//	0: a bare JSON array of commit records (GitPulse before versioning)
+//	1: {"schema_version": 1, "commits": [...]}
+const schemaVersion = 1
+
+// historyFile is the on-disk layout of the history file.
+type historyFile struct {
+	SchemaVersion int            `json:"schema_version"`
+	Commits       []CommitRecord `json:"commits"`
+}
+
+// migrations[v] upgrades a version v history file to version v+1. Append
+// one (and bump schemaVersion) whenever the format changes.
+var migrations = []func(data []byte) ([]byte, error){
+	migrateV0,
+}
+
+// migrateV0 wraps the original bare array in a versioned object.
+func migrateV0(data []byte) ([]byte, error) {
+	var records json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		SchemaVersion int             `json:"schema_version"`
+		Commits       json.RawMessage `json:"commits"`
+	}{1, records})
+}
+
+// fileVersion returns the schema_version of history file contents.
+func fileVersion(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return 0, nil
+	}
+	var v struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(trimmed, &v); err != nil {
+		return 0, err
+	}
+	return v.SchemaVersion, nil
+}
+
+// migrate upgrades data from version from to schemaVersion. The original is
+// first copied to <path>.v<from>.bak, then the upgraded file is written back
+// to path, so an older GitPulse's history survives the upgrade either way.
+func migrate(path string, data []byte, from int) ([]byte, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to back up history before migrating: %w", err)
+	}
+
+	for v := from; v < schemaVersion; v++ {
+		var err error
+		if data, err = migrations[v](data); err != nil {
+			return nil, fmt.Errorf("failed to migrate history from schema_version %d (original kept at %s): %w", v, backup, err)
+		}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err == nil {
+		data = indented.Bytes()
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write migrated history (original kept at %s): %w", backup, err)
+	}
+	return data, nil
+}
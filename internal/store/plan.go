@@ -0,0 +1,62 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Plan is what the daemon would commit if it flushed now: the pending
+// changes and their proposed groups, persisted to .gitpulse/plan.json each
+// time the pending changes change so the dashboard can preview them. The
+// groups and messages are the heuristic ones; the AI may still regroup and
+// reword them when the flush runs.
+type Plan struct {
+	UpdatedAt time.Time    `json:"updated_at"`
+	Pending   []PlanChange `json:"pending"`
+	Groups    []PlanGroup  `json:"groups"`
+}
+
+// PlanChange is one pending file change.
+type PlanChange struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // "added", "modified", "deleted" or "renamed"
+}
+
+// PlanGroup is one commit the plan proposes.
+type PlanGroup struct {
+	Files   []string `json:"files"`
+	Reason  string   `json:"reason"`
+	Scope   string   `json:"scope,omitempty"`
+	Message string   `json:"message"`
+}
+
+// LoadPlan reads the plan file at path; a missing file (no daemon running,
+// or nothing buffered yet) yields an empty Plan.
+func LoadPlan(path string) (*Plan, error) {
+	plan := &Plan{Pending: []PlanChange{}, Groups: []PlanGroup{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return plan, nil
+		}
+		return plan, err
+	}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return &Plan{Pending: []PlanChange{}, Groups: []PlanGroup{}}, err
+	}
+	return plan, nil
+}
+
+// SavePlan writes plan to path, creating its directory if needed.
+func SavePlan(path string, plan Plan) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
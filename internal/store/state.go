@@ -0,0 +1,88 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State holds small per-repo flags GitPulse remembers between runs, persisted
+// to .gitpulse/state.json.
+type State struct {
+	PushConfirmedAt *time.Time `json:"push_confirmed_at,omitempty"` // user OK'd GitPulse pushing to this repo's remote
+	PushConfirmedTo string     `json:"push_confirmed_to,omitempty"` // "<remote>/<branch>" shown in the confirmation
+	SnoozedUntil    *time.Time `json:"snoozed_until,omitempty"`     // `gitpulse snooze`: no flushing before this time
+
+	LastSeenHead string `json:"last_seen_head,omitempty"` // HEAD when GitPulse last checked for commits made without it
+
+	path string
+}
+
+// LoadState reads the state file at path; a missing file yields an empty State.
+// On a read or parse error the returned State is still usable (empty), so
+// saving it overwrites the bad file.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{path: path}, nil
+		}
+		return &State{path: path}, err
+	}
+	st := &State{path: path}
+	if err := json.Unmarshal(data, st); err != nil {
+		return &State{path: path}, err
+	}
+	return st, nil
+}
+
+// PushConfirmed reports whether the user has already approved pushing.
+func (s *State) PushConfirmed() bool {
+	return s.PushConfirmedAt != nil
+}
+
+// ConfirmPush records that the user approved pushing to target and saves.
+func (s *State) ConfirmPush(target string) error {
+	now := time.Now()
+	s.PushConfirmedAt = &now
+	s.PushConfirmedTo = target
+	return s.save()
+}
+
+// Snoozed returns the snooze end time if a snooze is still in effect.
+func (s *State) Snoozed() (time.Time, bool) {
+	if s.SnoozedUntil == nil || !time.Now().Before(*s.SnoozedUntil) {
+		return time.Time{}, false
+	}
+	return *s.SnoozedUntil, true
+}
+
+// Snooze records a snooze lasting until the given time and saves.
+func (s *State) Snooze(until time.Time) error {
+	s.SnoozedUntil = &until
+	return s.save()
+}
+
+// Resume clears any snooze and saves.
+func (s *State) Resume() error {
+	s.SnoozedUntil = nil
+	return s.save()
+}
+
+// SeeHead records hash as the last HEAD GitPulse saw and saves.
+func (s *State) SeeHead(hash string) error {
+	s.LastSeenHead = hash
+	return s.save()
+}
+
+func (s *State) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
@@ -1,9 +1,14 @@
 package store
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -13,7 +18,19 @@ type FileChange struct {
 	Diff         string `json:"diff"`
 	LinesAdded   int    `json:"lines_added"`
 	LinesRemoved int    `json:"lines_removed"`
-	Status       string `json:"status"` // "modified", "added", "deleted"
+	Status       string `json:"status"` // "modified", "added", "deleted", "renamed"
+
+	OldPath string `json:"old_path,omitempty"` // the path before a rename
+	Binary  bool   `json:"binary,omitempty"`   // binary content: no line counts
+
+	// Size of Diff: its length in bytes and its number of @@ hunks
+	DiffBytes int `json:"diff_bytes,omitempty"`
+	HunkCount int `json:"hunk_count,omitempty"`
+
+	DiffCompacted bool `json:"diff_compacted,omitempty"` // Diff was dropped by `gitpulse history compact`; the stats above still describe it
+
+	FirstChangedAt *time.Time `json:"first_changed_at,omitempty"` // earliest watcher event for this file in the flush
+	LastChangedAt  *time.Time `json:"last_changed_at,omitempty"`  // latest watcher event for this file in the flush
 }
 
 // ReviewFinding is a standalone copy of ai.ReviewFinding to avoid import cycles.
@@ -39,6 +56,10 @@ type ReviewRecord struct {
 	HasBlockers  bool            `json:"has_blockers"`
 	Action       string          `json:"action"` // "manual", "aifix", "continue", ""
 	FixesApplied []FixRecord     `json:"fixes_applied,omitempty"`
+
+	// Post-push review: ran in the background after the commit was made
+	// (and pushed), so its findings didn't hold anything back
+	Async bool `json:"async,omitempty"`
 }
 
 // CommitRecord stores enriched metadata about a single commit made by GitPulse.
@@ -48,12 +69,83 @@ type CommitRecord struct {
 	Files       []FileChange  `json:"files"`
 	GroupReason string        `json:"group_reason"`
 	AIGenerated bool          `json:"ai_generated"`
+	Model       string        `json:"model,omitempty"` // AI model that wrote the message
 	Review      *ReviewRecord `json:"review,omitempty"`
+	LargeCommit bool          `json:"large_commit,omitempty"` // diff exceeded large_commit_lines
+	LocalOnly   bool          `json:"local_only,omitempty"`   // committed with push_mode: never; GitPulse won't push it
+	Grouping    string        `json:"grouping,omitempty"`     // which grouping was committed: "ai", "ai_split" or "heuristic"
 	Pushed      bool          `json:"pushed"`
 	PushedAt    *time.Time    `json:"pushed_at,omitempty"`
 	Remote      string        `json:"remote,omitempty"`
 	Branch      string        `json:"branch,omitempty"`
 	CreatedAt   time.Time     `json:"created_at"`
+
+	// Why the files were grouped, kept apart for auditing: the heuristic
+	// pre-grouping's reason(s) and the AI refinement's rationale ("" when the
+	// heuristic grouping was committed)
+	HeuristicReason string `json:"heuristic_reason,omitempty"`
+	AIReason        string `json:"ai_reason,omitempty"`
+
+	// Every remote the commit has been pushed to (see the remotes config);
+	// Pushed/PushedAt/Remote/Branch describe the first successful push
+	PushedRemotes []string `json:"pushed_remotes,omitempty"`
+
+	// Totals of the files' DiffBytes and HunkCount, filled in by Save/Amend
+	DiffBytes int `json:"diff_bytes,omitempty"`
+	HunkCount int `json:"hunk_count,omitempty"`
+
+	// How the AI review covered the commit (one of the ReviewMode values),
+	// and why it was skipped when ReviewModeSkipped
+	ReviewMode       string `json:"review_mode,omitempty"`
+	ReviewSkipReason string `json:"review_skip_reason,omitempty"`
+}
+
+// sumDiffSize totals the files' diff sizes into the record.
+func (r *CommitRecord) sumDiffSize() {
+	r.DiffBytes, r.HunkCount = 0, 0
+	for _, f := range r.Files {
+		r.DiffBytes += f.DiffBytes
+		r.HunkCount += f.HunkCount
+	}
+}
+
+// PushedTo reports whether the commit has been pushed to remote. Records
+// from before per-remote tracking count as pushed to their Remote.
+func (r CommitRecord) PushedTo(remote string) bool {
+	if len(r.PushedRemotes) == 0 {
+		return r.Pushed && (r.Remote == remote || r.Remote == "")
+	}
+	for _, pr := range r.PushedRemotes {
+		if pr == remote {
+			return true
+		}
+	}
+	return false
+}
+
+// CommitRecord.Grouping values.
+const (
+	GroupingAI        = "ai"        // AI-refined groups
+	GroupingAISplit   = "ai_split"  // AI re-run asked to split more (by the user, or after a degenerate grouping)
+	GroupingHeuristic = "heuristic" // heuristic pre-groups (offline, AI failure, or chosen by the user)
+)
+
+// CommitRecord.ReviewMode values.
+const (
+	ReviewModeBlocking    = "blocking"     // reviewed before committing; blockers held the commit
+	ReviewModeNonBlocking = "non_blocking" // reviewed before committing, findings only logged (safety-timer flush)
+	ReviewModeAsync       = "async"        // reviewed in the background after committing (ai.review_mode: async)
+	ReviewModeSkipped     = "skipped"      // not reviewed; see ReviewSkipReason
+)
+
+// Unreviewed reports whether the commit never got a blocking AI review.
+// Records from before ReviewMode was recorded count as unreviewed when they
+// have no review.
+func (r CommitRecord) Unreviewed() bool {
+	if r.ReviewMode == "" {
+		return r.Review == nil
+	}
+	return r.ReviewMode != ReviewModeBlocking
 }
 
 // StoreStats provides summary statistics for the web UI dashboard.
@@ -64,12 +156,31 @@ type StoreStats struct {
 	TotalLinesRemoved int `json:"total_lines_removed"`
 	ReviewsRun        int `json:"reviews_run"`
 	ReviewsBlocked    int `json:"reviews_blocked"`
+	ErrorFindings     int `json:"error_findings"`
+	WarningFindings   int `json:"warning_findings"`
+	InfoFindings      int `json:"info_findings"`
+	FixesApplied      int `json:"fixes_applied"`
+	LargeCommits      int `json:"large_commits"`
+
+	UnreviewedCommits int `json:"unreviewed_commits"` // commits that never got a blocking review
+
+	Empty         bool `json:"empty"`          // no commit records yet
+	HistoryExists bool `json:"history_exists"` // history file has been written at least once
 }
 
-// Store persists commit history to a JSON file.
+// Store persists commit history to a JSON file, or only in memory when
+// created with NewInMemory. Safe for concurrent use (e.g. dashboard handlers
+// reloading in parallel).
 type Store struct {
-	path    string
+	mu      sync.RWMutex
+	path    string // "" for an in-memory store
 	records []CommitRecord
+
+	// The history file's mtime, size and contents hash as of the last
+	// load/flush, so Reload can tell when another process rewrote it
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
 }
 
 // New creates a new Store. If path is empty, uses ~/.gitpulse/history.json.
@@ -98,26 +209,145 @@ func New(path string) (*Store, error) {
 	return s, nil
 }
 
+// NewInMemory creates a Store that keeps records for the life of the process
+// only, for when the history file can't be written.
+func NewInMemory() *Store {
+	return &Store{}
+}
+
+// InMemory reports whether the store was created with NewInMemory.
+func (s *Store) InMemory() bool {
+	return s.path == ""
+}
+
+// CheckWritable returns an error if files can't be created in dir (which
+// is created first if missing), e.g. on a read-only mount.
+func CheckWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
 // Save appends a commit record and writes to disk.
 func (s *Store) Save(record CommitRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	record.CreatedAt = time.Now()
+	record.sumDiffSize()
 	s.records = append(s.records, record)
 	return s.flush()
 }
 
+// Import adds records of commits made outside GitPulse, keeping their
+// CreatedAt (the commit date) and placing them by it, so the history stays
+// in chronological order. Records whose hash is already in the store are
+// skipped. Returns how many were added.
+func (s *Store) Import(records []CommitRecord) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	known := make(map[string]bool, len(s.records))
+	for _, r := range s.records {
+		known[r.Hash] = true
+	}
+	added := 0
+	for _, r := range records {
+		if known[r.Hash] {
+			continue
+		}
+		known[r.Hash] = true
+		r.sumDiffSize()
+		s.records = append(s.records, r)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	sort.SliceStable(s.records, func(i, j int) bool {
+		return s.records[i].CreatedAt.Before(s.records[j].CreatedAt)
+	})
+	return added, s.flush()
+}
+
+// Amend replaces the record for oldHash (an amended commit) with record,
+// keeping its position in history, CreatedAt (so the amend window still
+// counts from the first commit) and Branch. Push state isn't carried over:
+// the amended commit has a new hash, which isn't on the remote until it's
+// (force-)pushed. Falls back to appending if oldHash is unknown.
+func (s *Store) Amend(oldHash string, record CommitRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.sumDiffSize()
+	for i := range s.records {
+		if s.records[i].Hash == oldHash {
+			record.CreatedAt = s.records[i].CreatedAt
+			if record.Branch == "" {
+				record.Branch = s.records[i].Branch
+			}
+			s.records[i] = record
+			return s.flush()
+		}
+	}
+	record.CreatedAt = time.Now()
+	s.records = append(s.records, record)
+	return s.flush()
+}
+
+// RemapHashes moves the records of rewritten commits (e.g. rebased by a
+// pull) from their old hash to their new one. Returns how many changed.
+func (s *Store) RemapHashes(rewritten map[string]string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := 0
+	for i := range s.records {
+		if hash, ok := rewritten[s.records[i].Hash]; ok {
+			s.records[i].Hash = hash
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, s.flush()
+}
+
+// Delete removes the record for hash and rewrites the history file.
+// Only GitPulse's metadata is affected; the git commit itself is untouched.
+func (s *Store) Delete(hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.records {
+		if s.records[i].Hash == hash {
+			s.records = append(s.records[:i], s.records[i+1:]...)
+			return s.flush()
+		}
+	}
+	return fmt.Errorf("commit %s not found in history", hash)
+}
+
 // Recent returns the last n commit records (newest last).
 func (s *Store) Recent(n int) []CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	if n >= len(s.records) {
-		return s.records
+		return append([]CommitRecord(nil), s.records...)
 	}
-	return s.records[len(s.records)-n:]
+	return append([]CommitRecord(nil), s.records[len(s.records)-n:]...)
 }
 
 // GetByHash returns the commit record matching the given hash, or nil if not found.
 func (s *Store) GetByHash(hash string) *CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	for i := range s.records {
 		if s.records[i].Hash == hash {
-			return &s.records[i]
+			record := s.records[i]
+			return &record
 		}
 	}
 	return nil
@@ -125,6 +355,8 @@ func (s *Store) GetByHash(hash string) *CommitRecord {
 
 // GetByFile returns all commit records that touch the given file path.
 func (s *Store) GetByFile(path string) []CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var results []CommitRecord
 	for _, r := range s.records {
 		for _, f := range r.Files {
@@ -137,8 +369,40 @@ func (s *Store) GetByFile(path string) []CommitRecord {
 	return results
 }
 
+// GetByMessage returns all commit records whose message contains substr
+// (case-insensitive), newest first.
+func (s *Store) GetByMessage(substr string) []CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	needle := strings.ToLower(substr)
+	var results []CommitRecord
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(s.records[i].Message), needle) {
+			results = append(results, s.records[i])
+		}
+	}
+	return results
+}
+
+// GetUnreviewed returns the commit records that never got a blocking AI
+// review (skipped, non-blocking or async; see CommitRecord.Unreviewed),
+// newest first.
+func (s *Store) GetUnreviewed() []CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var results []CommitRecord
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if s.records[i].Unreviewed() {
+			results = append(results, s.records[i])
+		}
+	}
+	return results
+}
+
 // GetByDateRange returns all commit records within the given time range (inclusive).
 func (s *Store) GetByDateRange(from, to time.Time) []CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var results []CommitRecord
 	for _, r := range s.records {
 		if !r.CreatedAt.Before(from) && !r.CreatedAt.After(to) {
@@ -150,12 +414,22 @@ func (s *Store) GetByDateRange(from, to time.Time) []CommitRecord {
 
 // Stats computes summary statistics across all stored commit records.
 func (s *Store) Stats() StoreStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	stats := StoreStats{
-		TotalCommits: len(s.records),
+		TotalCommits:  len(s.records),
+		Empty:         len(s.records) == 0,
+		HistoryExists: s.HistoryExists(),
 	}
 
 	fileSet := make(map[string]bool)
 	for _, r := range s.records {
+		if r.LargeCommit {
+			stats.LargeCommits++
+		}
+		if r.Unreviewed() {
+			stats.UnreviewedCommits++
+		}
 		for _, f := range r.Files {
 			fileSet[f.Path] = true
 			stats.TotalLinesAdded += f.LinesAdded
@@ -166,6 +440,17 @@ func (s *Store) Stats() StoreStats {
 			if r.Review.HasBlockers {
 				stats.ReviewsBlocked++
 			}
+			for _, f := range r.Review.Findings {
+				switch f.Severity {
+				case "error":
+					stats.ErrorFindings++
+				case "warning":
+					stats.WarningFindings++
+				case "info":
+					stats.InfoFindings++
+				}
+			}
+			stats.FixesApplied += len(r.Review.FixesApplied)
 		}
 	}
 	stats.TotalFiles = len(fileSet)
@@ -173,50 +458,209 @@ func (s *Store) Stats() StoreStats {
 	return stats
 }
 
-// MarkPushed updates all records matching the given hashes as pushed.
+// GetUnpushedCommits returns the records not yet marked as pushed, oldest
+// first. Local-only records (push_mode: never) are excluded.
+func (s *Store) GetUnpushedCommits() []CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var results []CommitRecord
+	for _, r := range s.records {
+		if !r.Pushed && !r.LocalOnly {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// GetUnpushedTo returns the records not yet pushed to remote, oldest first.
+// Local-only records are excluded.
+func (s *Store) GetUnpushedTo(remote string) []CommitRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var results []CommitRecord
+	for _, r := range s.records {
+		if !r.PushedTo(remote) && !r.LocalOnly {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// MarkPushed records that the commits with the given hashes were pushed to
+// remote. Idempotent: a record keeps its original push metadata once pushed
+// anywhere, a remote is only added to PushedRemotes once, and if nothing
+// changes the file isn't rewritten. If writing the file fails the in-memory
+// records are left as they were too, so a later call can retry.
 func (s *Store) MarkPushed(hashes []string, remote, branch string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	hashSet := make(map[string]bool, len(hashes))
 	for _, h := range hashes {
 		hashSet[h] = true
 	}
 
+	before := append([]CommitRecord(nil), s.records...)
 	now := time.Now()
+	changed := false
 	for i := range s.records {
-		if hashSet[s.records[i].Hash] {
-			s.records[i].Pushed = true
-			s.records[i].PushedAt = &now
-			s.records[i].Remote = remote
-			s.records[i].Branch = branch
+		r := &s.records[i]
+		if !hashSet[r.Hash] || r.PushedTo(remote) {
+			continue
+		}
+		changed = true
+		if r.Pushed && len(r.PushedRemotes) == 0 {
+			r.PushedRemotes = []string{r.Remote} // pushed before per-remote tracking
+		}
+		r.PushedRemotes = append(r.PushedRemotes, remote)
+		if !r.Pushed {
+			r.Pushed = true
+			r.PushedAt = &now
+			r.Remote = remote
+			r.Branch = branch
 		}
 	}
 
-	return s.flush()
+	if !changed {
+		return nil
+	}
+	if err := s.flush(); err != nil {
+		s.records = before
+		return err
+	}
+	return nil
+}
+
+// SetReview attaches review results to the record for hash, e.g. once a
+// background review of an already-made commit finishes.
+func (s *Store) SetReview(hash string, review *ReviewRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.records {
+		if s.records[i].Hash == hash {
+			prev := s.records[i].Review
+			s.records[i].Review = review
+			if err := s.flush(); err != nil {
+				s.records[i].Review = prev
+				return err
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("commit %s not found in history", hash)
+}
+
+// IsEmpty reports whether the store has no commit records.
+func (s *Store) IsEmpty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records) == 0
+}
+
+// HistoryExists reports whether the history file exists on disk, which
+// distinguishes "GitPulse never ran here" from "ran but made no commits".
+func (s *Store) HistoryExists() bool {
+	if s.path == "" {
+		return false
+	}
+	_, err := os.Stat(s.path)
+	return err == nil
 }
 
-// All returns every stored commit record.
+// All returns a copy of every stored commit record.
 func (s *Store) All() []CommitRecord {
-	return s.records
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]CommitRecord(nil), s.records...)
 }
 
-// Reload re-reads the history file from disk. Use when serving a dashboard
-// that should reflect commits made by another process (e.g., the daemon).
+// Reload re-reads the history file from disk if it changed since the last
+// load. Use when serving a dashboard that should reflect commits made by
+// another process (e.g., the daemon); cheap to call on every request. A
+// rewrite can keep the mtime (coarse timestamps, two writes in one tick) and
+// even the size (e.g. hashes remapped after a pull), so when both match the
+// contents are hashed before the file is skipped.
 func (s *Store) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		// History file removed — nothing to show
+		s.records = nil
+		s.modTime, s.size, s.sum = time.Time{}, 0, [sha256.Size]byte{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			return err
+		}
+		if sha256.Sum256(data) == s.sum {
+			return nil
+		}
+	}
 	return s.load()
 }
 
+// load reads the history file, first migrating it in place if it was
+// written in an older format. Callers must hold mu (or own s exclusively).
 func (s *Store) load() error {
 	data, err := os.ReadFile(s.path)
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, &s.records)
+
+	version, err := fileVersion(data)
+	if err != nil {
+		return fmt.Errorf("failed to read history schema_version: %w", err)
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("history file %s has schema_version %d, newer than this GitPulse supports (%d); upgrade GitPulse", s.path, version, schemaVersion)
+	}
+	if version < schemaVersion {
+		if data, err = migrate(s.path, data, version); err != nil {
+			return err
+		}
+	}
+
+	var file historyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	s.records = file.Commits
+	s.modTime, s.size, s.sum = info.ModTime(), info.Size(), sha256.Sum256(data)
+	return nil
+}
+
+// encodeHistory renders records as the contents of a history file.
+func encodeHistory(records []CommitRecord) ([]byte, error) {
+	return json.MarshalIndent(historyFile{SchemaVersion: schemaVersion, Commits: records}, "", "  ")
 }
 
+// flush writes the history file (a no-op in memory). Callers must hold mu.
 func (s *Store) flush() error {
-	data, err := json.MarshalIndent(s.records, "", "  ")
+	if s.path == "" {
+		return nil
+	}
+	data, err := encodeHistory(s.records)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, data, 0644)
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime, s.size, s.sum = info.ModTime(), info.Size(), sha256.Sum256(data)
+	}
+	return nil
 }
-
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxLogLines is how much log history the panel keeps.
+const maxLogLines = 500
+
+// LogBuffer collects Logger output for the log panel. It is an io.Writer, so
+// it can be handed to ui.Logger.SetOutput and log.SetOutput. A trailing line
+// without a newline (a prompt waiting for input) is kept as the last line.
+type LogBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	partial string
+}
+
+// NewLogBuffer creates an empty LogBuffer.
+func NewLogBuffer() *LogBuffer {
+	return &LogBuffer{}
+}
+
+// Write appends output, splitting it into lines.
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	text := b.partial + string(p)
+	parts := strings.Split(text, "\n")
+	b.partial = parts[len(parts)-1]
+	b.lines = append(b.lines, parts[:len(parts)-1]...)
+	if over := len(b.lines) - maxLogLines; over > 0 {
+		b.lines = append([]string(nil), b.lines[over:]...)
+	}
+	return len(p), nil
+}
+
+// Last returns up to n of the most recent lines, including a pending prompt.
+func (b *LogBuffer) Last(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := b.lines
+	if b.partial != "" {
+		lines = append(append([]string(nil), lines...), b.partial)
+	}
+	if n <= 0 {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return append([]string(nil), lines...)
+}
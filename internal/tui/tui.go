@@ -0,0 +1,294 @@
+// Package tui is the optional `gitpulse --tui` interface: a live view of
+// pending changes and the safety-timer countdown, with the engine's log and
+// review prompts shown in a panel and answered in place.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/firasastwani/gitpulse/internal/engine"
+	"github.com/firasastwani/gitpulse/internal/ui"
+	"github.com/firasastwani/gitpulse/internal/watcher"
+)
+
+// ANSI styles, matching the plain logger's palette.
+const (
+	styleBold  = "\033[1m"
+	styleGray  = "\033[90m"
+	styleCyan  = "\033[36m"
+	styleGreen = "\033[32m"
+	styleRed   = "\033[31m"
+	styleReset = "\033[0m"
+)
+
+// maxPendingShown caps the pending list so the log panel keeps some room.
+const maxPendingShown = 8
+
+type (
+	eventMsg  engine.Event
+	tickMsg   time.Time
+	actionMsg struct{} // a keypress-triggered flush/push finished
+)
+
+// Model is the bubbletea model. Answers typed while the logger is prompting
+// (review actions, confirmations) are sent down input, the same channel the
+// plain mode feeds from stdin.
+type Model struct {
+	eng    *engine.Engine
+	events <-chan engine.Event
+	logger *ui.Logger
+	input  chan<- string
+	logs   *LogBuffer
+	title  string
+
+	pending  []watcher.FileChange
+	deadline time.Time // safety timer; zero when not armed
+	snoozed  time.Time // zero when not snoozed
+	busy     bool      // a flush is running
+	answer   string    // prompt answer being typed
+	activity string    // latest commit, push or review result
+
+	width, height int
+}
+
+// New creates the model. logs must already be the logger's output.
+func New(eng *engine.Engine, logger *ui.Logger, input chan<- string, logs *LogBuffer, title string) Model {
+	return Model{eng: eng, events: eng.Subscribe(), logger: logger, input: input, logs: logs, title: title}
+}
+
+// Run shows the TUI full-screen until the user quits or stop fires.
+// Ctrl+C and SIGINT are a normal exit, not an error.
+func Run(m Model, stop <-chan os.Signal) error {
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	go func() {
+		if _, ok := <-stop; ok {
+			p.Quit()
+		}
+	}()
+
+	_, err := p.Run()
+	if errors.Is(err, tea.ErrInterrupted) {
+		return nil
+	}
+	return err
+}
+
+// Init starts listening for engine events and the redraw tick.
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.waitForEvent(), tick())
+}
+
+func (m Model) waitForEvent() tea.Cmd {
+	events := m.events
+	return func() tea.Msg {
+		ev, ok := <-events
+		if !ok {
+			return nil // engine stopped
+		}
+		return eventMsg(ev)
+	}
+}
+
+// tick redraws twice a second for the countdown and new log lines.
+func tick() tea.Cmd {
+	return tea.Tick(time.Second/2, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// Update handles engine events, ticks and keys.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case eventMsg:
+		switch msg.Type {
+		case engine.EventPending:
+			m.pending = msg.Pending
+		case engine.EventTimer:
+			m.deadline = msg.Deadline
+		case engine.EventFlushStart:
+			m.busy = true
+		case engine.EventFlushDone:
+			m.busy = false
+		case engine.EventSnooze:
+			m.snoozed = msg.Deadline
+		case engine.EventCommit:
+			m.activity = styleGreen + "committed " + shortHash(msg.Hash) + styleReset + " " + firstLine(msg.Message)
+		case engine.EventPush:
+			if msg.Err != nil {
+				m.activity = styleRed + "push to " + msg.Remote + " failed" + styleReset
+			} else {
+				m.activity = fmt.Sprintf("%spushed %d commit(s) to %s%s", styleGreen, len(msg.Hashes), msg.Remote, styleReset)
+			}
+		case engine.EventReviewBlocked:
+			m.activity = fmt.Sprintf("%sreview blocked: %d finding(s)%s", styleRed, len(msg.Findings), styleReset)
+		}
+		return m, m.waitForEvent()
+
+	case tickMsg:
+		return m, tick()
+
+	case actionMsg:
+		m.busy = false
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m Model) handleKey(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+
+	// A prompt is waiting: keys type its answer
+	if m.logger.Prompting() {
+		switch key.Type {
+		case tea.KeyEnter:
+			answer := m.answer
+			m.answer = ""
+			input := m.input
+			return m, func() tea.Msg {
+				input <- answer
+				return nil
+			}
+		case tea.KeyBackspace:
+			if m.answer != "" {
+				_, size := utf8.DecodeLastRuneInString(m.answer)
+				m.answer = m.answer[:len(m.answer)-size]
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.answer += string(key.Runes)
+		}
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q":
+		return m, tea.Quit
+	case "enter", "f":
+		return m.run(m.eng.Flush)
+	case "p":
+		return m.run(m.eng.PushNow)
+	}
+	return m, nil
+}
+
+// run starts a flush or push in the background so prompts stay answerable.
+func (m Model) run(action func()) (tea.Model, tea.Cmd) {
+	if m.busy {
+		return m, nil
+	}
+	m.busy = true
+	return m, func() tea.Msg {
+		action()
+		return actionMsg{}
+	}
+}
+
+// View renders the header, pending files, log panel and key help.
+func (m Model) View() string {
+	var b strings.Builder
+
+	status := ""
+	switch {
+	case m.busy:
+		status = styleCyan + "flushing…" + styleReset
+	case !m.snoozed.IsZero() && time.Now().Before(m.snoozed):
+		status = styleCyan + "snoozed until " + m.snoozed.Format("15:04") + styleReset
+	case !m.deadline.IsZero():
+		left := time.Until(m.deadline).Round(time.Second)
+		if left < 0 {
+			left = 0
+		}
+		status = fmt.Sprintf("safety flush in %s", formatCountdown(left))
+	}
+	fmt.Fprintf(&b, "%sGitPulse%s  %s%s%s  %s\n", styleBold, styleReset, styleGray, m.title, styleReset, status)
+	if m.activity != "" {
+		b.WriteString(m.activity + "\n")
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "%sPending changes (%d)%s\n", styleBold, len(m.pending), styleReset)
+	shown := m.pending
+	if len(shown) > maxPendingShown {
+		shown = shown[len(shown)-maxPendingShown:]
+	}
+	if len(shown) == 0 {
+		fmt.Fprintf(&b, "  %snone%s\n", styleGray, styleReset)
+	}
+	for _, fc := range shown {
+		fmt.Fprintf(&b, "  %s %s\n", changeMarker(fc.Type), fc.Path)
+	}
+	if hidden := len(m.pending) - len(shown); hidden > 0 {
+		fmt.Fprintf(&b, "  %s… and %d earlier%s\n", styleGray, hidden, styleReset)
+	}
+
+	b.WriteString("\n" + styleGray + strings.Repeat("─", max(m.width, 20)) + styleReset + "\n")
+
+	// Log panel fills what's left above the two footer lines
+	used := strings.Count(b.String(), "\n")
+	room := m.height - used - 3
+	if m.height == 0 {
+		room = 15
+	}
+	for _, line := range m.logs.Last(room) {
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString(styleGray + strings.Repeat("─", max(m.width, 20)) + styleReset + "\n")
+	if m.logger.Prompting() {
+		fmt.Fprintf(&b, "%s›%s %s%s█%s  %s(enter to answer)%s", styleBold, styleReset, m.answer, styleGray, styleReset, styleGray, styleReset)
+	} else {
+		fmt.Fprintf(&b, "%senter/f%s flush  %sp%s flush & push  %sq%s quit", styleBold, styleReset, styleBold, styleReset, styleBold, styleReset)
+	}
+	return b.String()
+}
+
+func changeMarker(t watcher.ChangeType) string {
+	switch t {
+	case watcher.Created:
+		return styleGreen + "A" + styleReset
+	case watcher.Deleted:
+		return styleRed + "D" + styleReset
+	case watcher.Renamed:
+		return styleCyan + "R" + styleReset
+	default:
+		return styleCyan + "M" + styleReset
+	}
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// formatCountdown renders d as m:ss (or h:mm:ss).
+func formatCountdown(d time.Duration) string {
+	h := int(d.Hours())
+	mins := int(d.Minutes()) % 60
+	secs := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, mins, secs)
+	}
+	return fmt.Sprintf("%d:%02d", mins, secs)
+}
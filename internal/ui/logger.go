@@ -2,11 +2,15 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/firasastwani/gitpulse/internal/ai"
+	"github.com/firasastwani/gitpulse/internal/store"
 )
 
 // ANSI color codes for terminal output.
@@ -24,13 +28,38 @@ const (
 // terminal detection, which can hang in some environments (IDE terminals, SSH, etc.).
 type Logger struct {
 	stdinCh <-chan string // shared channel for all stdin reads
+	out     io.Writer     // prompts, tables and findings
+	std     *log.Logger   // timestamped log lines
+
+	prompting atomic.Bool // a prompt is waiting for a line from stdinCh
 }
 
 // New creates a new Logger.
 // stdinCh should be a channel fed by a single goroutine reading lines from os.Stdin.
 // Pass nil if no interactive input is needed (e.g., non-interactive mode).
 func New(stdinCh <-chan string) *Logger {
-	return &Logger{stdinCh: stdinCh}
+	return &Logger{stdinCh: stdinCh, out: os.Stdout, std: log.Default()}
+}
+
+// SetOutput sends all output to w instead of the terminal (e.g. the --tui
+// log panel). Log lines drop the stdlib date prefix; they carry their own time.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.out = w
+	l.std = log.New(w, "", 0)
+}
+
+// Prompting reports whether a prompt is waiting for an answer, so a UI
+// feeding stdinCh knows the next line it sends is that answer.
+func (l *Logger) Prompting() bool {
+	return l.prompting.Load()
+}
+
+// readLine waits for the next input line, flagging the wait for Prompting.
+func (l *Logger) readLine() (string, bool) {
+	l.prompting.Store(true)
+	defer l.prompting.Store(false)
+	input, ok := <-l.stdinCh
+	return input, ok
 }
 
 func (l *Logger) logWithKeyvals(level, levelColor, msg string, keyvals ...interface{}) {
@@ -42,7 +71,7 @@ func (l *Logger) logWithKeyvals(level, levelColor, msg string, keyvals ...interf
 	for i := 0; i+1 < len(keyvals); i += 2 {
 		b.WriteString(colorGray + fmt.Sprintf(" %v=%v", keyvals[i], keyvals[i+1]) + colorReset)
 	}
-	log.Println(b.String())
+	l.std.Println(b.String())
 }
 
 // Info logs an informational message with optional key-value pairs.
@@ -69,8 +98,8 @@ func (l *Logger) GroupInfo(groupCount int, groups []GroupDisplay) {
 		if i == len(groups)-1 {
 			prefix = "└─"
 		}
-		fmt.Printf("  %s Group %d: %s\n", prefix, i+1, g.Files)
-		fmt.Printf("     reason: %q\n", g.Reason)
+		fmt.Fprintf(l.out, "  %s Group %d: %s\n", prefix, i+1, g.Files)
+		fmt.Fprintf(l.out, "     reason: %q\n", g.Reason)
 	}
 }
 
@@ -101,7 +130,7 @@ func (l *Logger) ReviewFindings(findings []ai.ReviewFinding) {
 	}
 
 	l.Warn(fmt.Sprintf("Code review found %d issue(s), %d blocking", len(findings), blockerCount))
-	fmt.Println()
+	fmt.Fprintln(l.out)
 
 	for i, f := range findings {
 		// Tree connector
@@ -131,13 +160,13 @@ func (l *Logger) ReviewFindings(findings []ai.ReviewFinding) {
 			lineRange = fmt.Sprintf("L%d-%d", f.StartLine, f.EndLine)
 		}
 
-		fmt.Printf("  %s %s[%s]%s %s %s(%s)%s\n",
+		fmt.Fprintf(l.out, "  %s %s[%s]%s %s %s(%s)%s\n",
 			prefix, color, label, colorReset,
 			f.File, colorGray, lineRange, colorReset)
-		fmt.Printf("     %s%s%s\n", colorBold, f.Description, colorReset)
+		fmt.Fprintf(l.out, "     %s%s%s\n", colorBold, f.Description, colorReset)
 
 		if f.Suggestion != "" {
-			fmt.Printf("     %sfix: %s%s\n", colorGray, f.Suggestion, colorReset)
+			fmt.Fprintf(l.out, "     %sfix: %s%s\n", colorGray, f.Suggestion, colorReset)
 		}
 
 		// Related locations
@@ -146,23 +175,25 @@ func (l *Logger) ReviewFindings(findings []ai.ReviewFinding) {
 			if j == len(f.RelatedLocations)-1 {
 				relPrefix = "│  └─"
 			}
-			fmt.Printf("     %s %salso see: %s (L%d-%d)%s\n",
+			fmt.Fprintf(l.out, "     %s %salso see: %s (L%d-%d)%s\n",
 				relPrefix, colorGray, loc.File, loc.StartLine, loc.EndLine, colorReset)
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(l.out)
 }
 
-// PromptReviewAction displays the 3 review options and reads the user's choice.
-// Returns "manual", "aifix", or "continue".
+// PromptReviewAction displays the 5 review options and reads the user's choice.
+// Returns "manual", "aifix", "continue", "dismiss", or "abort".
 func (l *Logger) PromptReviewAction() (string, error) {
-	fmt.Println(colorBold + "  How would you like to proceed?" + colorReset)
-	fmt.Println("    [1] Fix manually (pause and re-review after)")
-	fmt.Println("    [2] Let AI fix")
-	fmt.Println("    [3] Continue anyway (push with current code)")
-	fmt.Print("\n  Choice [1/2/3]: ")
+	fmt.Fprintln(l.out, colorBold+"  How would you like to proceed?"+colorReset)
+	fmt.Fprintln(l.out, "    [1] Fix manually (pause and re-review after)")
+	fmt.Fprintln(l.out, "    [2] Let AI fix")
+	fmt.Fprintln(l.out, "    [3] Continue anyway (push with current code)")
+	fmt.Fprintln(l.out, "    [4] Dismiss as false positives (continue, and don't flag these again)")
+	fmt.Fprintln(l.out, "    [5] Abort (don't commit these changes now)")
+	fmt.Fprint(l.out, "\n  Choice [1/2/3/4/5]: ")
 
-	input, ok := <-l.stdinCh
+	input, ok := l.readLine()
 	if !ok {
 		return "continue", fmt.Errorf("stdin channel closed")
 	}
@@ -174,6 +205,10 @@ func (l *Logger) PromptReviewAction() (string, error) {
 		return "aifix", nil
 	case "3":
 		return "continue", nil
+	case "4":
+		return "dismiss", nil
+	case "5":
+		return "abort", nil
 	default:
 		// Invalid input — default to continue to avoid blocking
 		l.Warn("Invalid choice, defaulting to continue")
@@ -181,11 +216,185 @@ func (l *Logger) PromptReviewAction() (string, error) {
 	}
 }
 
+// PromptReviewLimit asks what to do when the review still finds blockers
+// after limit fix rounds. Returns "retry", "continue", or "abort".
+func (l *Logger) PromptReviewLimit(limit int) (string, error) {
+	fmt.Fprintf(l.out, "\n  %sStill blocked after %d fix round(s).%s\n", colorBold, limit, colorReset)
+	fmt.Fprintln(l.out, "    [1] Keep trying (allow more fix rounds)")
+	fmt.Fprintln(l.out, "    [2] Continue (commit groups that passed, hold back the blocked ones)")
+	fmt.Fprintln(l.out, "    [3] Abort this flush (commit nothing, keep changes pending)")
+	fmt.Fprint(l.out, "\n  Choice [1/2/3]: ")
+
+	input, ok := l.readLine()
+	if !ok {
+		return "continue", fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.TrimSpace(input) {
+	case "1":
+		return "retry", nil
+	case "2":
+		return "continue", nil
+	case "3":
+		return "abort", nil
+	default:
+		l.Warn("Invalid choice, holding back the blocked groups")
+		return "continue", nil
+	}
+}
+
+// PromptGrouping asks whether to keep the AI grouping shown above.
+// Returns "accept", "heuristic", or "split".
+func (l *Logger) PromptGrouping() (string, error) {
+	fmt.Fprintf(l.out, "\n  %sUse this grouping?%s\n", colorBold, colorReset)
+	fmt.Fprintln(l.out, "    [1] Accept")
+	fmt.Fprintln(l.out, "    [2] Revert to the heuristic grouping")
+	fmt.Fprintln(l.out, "    [3] Re-run the AI, splitting more aggressively")
+	fmt.Fprint(l.out, "\n  Choice [1/2/3]: ")
+
+	input, ok := l.readLine()
+	if !ok {
+		return "accept", fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.TrimSpace(input) {
+	case "1", "":
+		return "accept", nil
+	case "2":
+		return "heuristic", nil
+	case "3":
+		return "split", nil
+	default:
+		l.Warn("Invalid choice, accepting the AI grouping")
+		return "accept", nil
+	}
+}
+
+// PromptLargeCommit asks how to handle an oversized group.
+// Returns "file", "dir", or "keep".
+func (l *Logger) PromptLargeCommit(message string, lines int) (string, error) {
+	fmt.Fprintf(l.out, "\n  %s%q changes %d lines.%s\n", colorBold, message, lines, colorReset)
+	fmt.Fprintln(l.out, "    [1] Split into one commit per file")
+	fmt.Fprintln(l.out, "    [2] Split into one commit per directory")
+	fmt.Fprintln(l.out, "    [3] Keep as a single commit")
+	fmt.Fprint(l.out, "\n  Choice [1/2/3]: ")
+
+	input, ok := l.readLine()
+	if !ok {
+		return "keep", fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.TrimSpace(input) {
+	case "1":
+		return "file", nil
+	case "2":
+		return "dir", nil
+	case "3":
+		return "keep", nil
+	default:
+		l.Warn("Invalid choice, keeping as a single commit")
+		return "keep", nil
+	}
+}
+
+// ConfirmFirstPush asks before GitPulse pushes to a repo for the first time.
+// Anything but y/yes declines.
+func (l *Logger) ConfirmFirstPush(target string) (bool, error) {
+	fmt.Fprintf(l.out, "\n  %sGitPulse will push to %s. Continue? [y/N]: %s", colorBold, target, colorReset)
+
+	input, ok := l.readLine()
+	if !ok {
+		return false, fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// ConfirmForcePush warns that amending hash, which target already has, means
+// force-pushing over it. Anything but y/yes declines.
+func (l *Logger) ConfirmForcePush(hash, target string) (bool, error) {
+	fmt.Fprintf(l.out, "\n  %sCommit %s is already on %s. Amending it rewrites shared history and needs a force push.%s\n", colorBold, hash, target, colorReset)
+	fmt.Fprintf(l.out, "  %sAmend and force-push? [y/N]: %s", colorBold, colorReset)
+
+	input, ok := l.readLine()
+	if !ok {
+		return false, fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// ConfirmLargeBinary asks whether to commit a new binary file of size bytes,
+// which is often a build artifact added by mistake. Default is no.
+func (l *Logger) ConfirmLargeBinary(path string, size int64) (bool, error) {
+	fmt.Fprintf(l.out, "\n  %sAdd large binary %s (%.0fMB)? [y/N]: %s", colorBold, path, float64(size)/(1<<20), colorReset)
+
+	input, ok := l.readLine()
+	if !ok {
+		return false, fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// PromptPullFirst warns that the local branch trails target by behind commits
+// and asks whether to pull before committing. Default is yes.
+func (l *Logger) PromptPullFirst(target string, behind int) (bool, error) {
+	fmt.Fprintf(l.out, "\n  %sYour branch is %d commit(s) behind %s. Pull (rebase) before committing? [Y/n]: %s", colorBold, behind, target, colorReset)
+
+	input, ok := l.readLine()
+	if !ok {
+		return false, fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "n", "no":
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// PromptConflicts pauses on files that still contain merge-conflict markers.
+// Returns true to re-check them after the user resolves the conflicts, false
+// to skip them this flush.
+func (l *Logger) PromptConflicts(files []string) (bool, error) {
+	fmt.Fprintf(l.out, "\n  %s%sConflict markers in: %s%s\n", colorBold, colorRed, strings.Join(files, ", "), colorReset)
+	fmt.Fprint(l.out, "  Resolve them and press ENTER to re-check, or type s to skip these files: ")
+
+	input, ok := l.readLine()
+	if !ok {
+		return false, fmt.Errorf("stdin channel closed")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "s", "skip":
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
 // WaitForManualFix prints instructions and blocks until the user presses ENTER.
 func (l *Logger) WaitForManualFix() error {
-	fmt.Println()
+	fmt.Fprintln(l.out)
 	l.Info("Fix the issues in your editor, then press ENTER to re-review...")
-	_, ok := <-l.stdinCh
+	_, ok := l.readLine()
 	if !ok {
 		return fmt.Errorf("stdin channel closed")
 	}
@@ -196,3 +405,50 @@ func (l *Logger) WaitForManualFix() error {
 func (l *Logger) AIFixApplied(file, description string) {
 	l.Info("AI fix applied", "file", file, "fix", description)
 }
+
+// HistoryTable renders commit records as a terminal table, in the order given.
+// Columns: hash, time, message (subject line), files, +/- lines, pushed.
+func (l *Logger) HistoryTable(records []store.CommitRecord) {
+	if len(records) == 0 {
+		l.Info("No commits in history yet")
+		return
+	}
+
+	fmt.Fprintf(l.out, "  %s%-7s  %-16s  %-50s  %5s  %13s  %-6s%s\n",
+		colorBold, "HASH", "TIME", "MESSAGE", "FILES", "+/-", "PUSHED", colorReset)
+
+	for _, r := range records {
+		var added, removed int
+		for _, f := range r.Files {
+			added += f.LinesAdded
+			removed += f.LinesRemoved
+		}
+
+		hash := r.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+
+		// Cut by runes so a multi-byte character isn't split
+		subject := strings.SplitN(r.Message, "\n", 2)[0]
+		if runes := []rune(subject); len(runes) > 50 {
+			subject = string(runes[:47]) + "..."
+		}
+
+		pushed := colorGray + "no    " + colorReset
+		if r.Pushed {
+			pushed = colorGreen + "yes   " + colorReset
+		} else if r.LocalOnly {
+			pushed = colorGray + "local " + colorReset
+		}
+
+		lines := fmt.Sprintf("%s%6s%s %s%6s%s",
+			colorGreen, fmt.Sprintf("+%d", added), colorReset,
+			colorRed, fmt.Sprintf("-%d", removed), colorReset)
+
+		fmt.Fprintf(l.out, "  %s%-7s%s  %s%-16s%s  %-50s  %5d  %s  %s\n",
+			colorCyan, hash, colorReset,
+			colorGray, r.CreatedAt.Local().Format("2006-01-02 15:04"), colorReset,
+			subject, len(r.Files), lines, pushed)
+	}
+}
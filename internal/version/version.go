@@ -0,0 +1,23 @@
+// Package version reports which GitPulse build is running.
+package version
+
+import "runtime/debug"
+
+// Version is the release version. Release builds set it with
+//
+//	go build -ldflags "-X github.com/firasastwani/gitpulse/internal/version.Version=v1.2.3"
+//
+// When it's unset, String falls back to the module version `go install` records.
+var Version = ""
+
+// String returns the running version, e.g. "v1.2.3", or "dev" for a local
+// build without one.
+func String() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
@@ -0,0 +1,72 @@
+package watcher
+
+// CoalesceAtomicSaves collapses the event bursts editors produce when they
+// save atomically (write a temp file, then rename it over the original; or,
+// like Vim, rename the original to a backup and write a new file) into one
+// Modified change per saved file. Call before Start.
+func (w *Watcher) CoalesceAtomicSaves(on bool) {
+	w.coalesce = on
+}
+
+// Coalesce rewrites one batch of changes, in the order they were observed:
+//   - transient files, created and then removed or renamed away within the
+//     batch (temp files, Vim's 4913 probe and ~ backup), are dropped
+//   - a file removed or renamed away and then created again (Vim's save), or
+//     created by a transient file being renamed onto it (write-temp-then-
+//     rename), becomes a single Modified change where it first appeared
+//
+// Everything else, including real renames between two kept paths, is left
+// as it was.
+func Coalesce(changes []FileChange) []FileChange {
+	first := make(map[string]int) // path -> index of its first change
+	last := make(map[string]int)
+	for i, fc := range changes {
+		if _, ok := first[fc.Path]; !ok {
+			first[fc.Path] = i
+		}
+		last[fc.Path] = i
+	}
+
+	gone := func(t ChangeType) bool { return t == Deleted || t == Renamed }
+	transient := make(map[string]bool)
+	for path, i := range first {
+		if changes[i].Type == Created && gone(changes[last[path]].Type) {
+			transient[path] = true
+		}
+	}
+
+	saved := make(map[string]bool)
+	for i, fc := range changes {
+		if fc.Type != Created || transient[fc.Path] {
+			continue
+		}
+		// Vim: the path went away earlier in the batch and came back
+		for j := first[fc.Path]; j < i; j++ {
+			if changes[j].Path == fc.Path && gone(changes[j].Type) {
+				saved[fc.Path] = true
+				break
+			}
+		}
+		// Write-temp-then-rename: the rename of the temp file is reported
+		// right before the Create of its new name
+		if i > 0 && changes[i-1].Type == Renamed && transient[changes[i-1].Path] {
+			saved[fc.Path] = true
+		}
+	}
+
+	var out []FileChange
+	for i, fc := range changes {
+		switch {
+		case transient[fc.Path]:
+			continue
+		case saved[fc.Path]:
+			if i != first[fc.Path] {
+				continue
+			}
+			fc.Type = Modified
+			fc.Time = changes[last[fc.Path]].Time
+		}
+		out = append(out, fc)
+	}
+	return out
+}
@@ -25,6 +25,7 @@ const (
 type FileChange struct {
 	Path string
 	Type ChangeType
+	Time time.Time // when the event was observed
 }
 
 // ChangeSet represents a debounced batch of file changes.
@@ -40,6 +41,13 @@ type Watcher struct {
 	ignorePatterns []string
 	events         chan ChangeSet
 	done           chan struct{}
+
+	gitDir string        // watched for HEAD rewrites when set (see WatchHead)
+	heads  chan struct{} // signalled when HEAD is rewritten
+
+	coalesce bool // collapse editor atomic-save bursts (see CoalesceAtomicSaves)
+
+	modeChanged func(path string) bool // decides which Chmod events count (see FilterModeChanges)
 }
 
 // New creates a new Watcher for the given path.
@@ -51,9 +59,32 @@ func New(root string, debounceSeconds int, ignorePatterns []string) (*Watcher, e
 		ignorePatterns: ignorePatterns,
 		events:         make(chan ChangeSet, 10),
 		done:           make(chan struct{}),
+		heads:          make(chan struct{}, 1),
 	}, nil
 }
 
+// WatchHead also watches gitDir's HEAD, which the ignore patterns otherwise
+// keep out, signalling HeadChanges whenever git rewrites it (e.g. on a
+// branch switch). Nothing else in gitDir is reported. Call before Start.
+func (w *Watcher) WatchHead(gitDir string) {
+	w.gitDir = gitDir
+}
+
+// FilterModeChanges reports a Chmod event (e.g. chmod +x, which fires no
+// content event) as a change only when modeChanged says git sees a new mode
+// for the file, given its path relative to the watch dir. touch, attribute
+// and xattr updates and indexers such as Spotlight fire Chmod too; without
+// a filter every Chmod event is ignored. Call before Start.
+func (w *Watcher) FilterModeChanges(modeChanged func(path string) bool) {
+	w.modeChanged = modeChanged
+}
+
+// HeadChanges signals that HEAD was rewritten; see WatchHead. Signals that
+// arrive before the last one was received are merged into it.
+func (w *Watcher) HeadChanges() <-chan struct{} {
+	return w.heads
+}
+
 // Events returns the channel that emits debounced ChangeSets.
 func (w *Watcher) Events() <-chan ChangeSet {
 	return w.events
@@ -66,6 +97,13 @@ func (w *Watcher) Start() error {
 	if err != nil {
 		return err
 	}
+	// HEAD is replaced by a rename, so watch its directory rather than the file
+	if w.gitDir != "" {
+		if err := fsWatcher.Add(w.gitDir); err != nil {
+			fsWatcher.Close()
+			return err
+		}
+	}
 
 	// Event-processing goroutine (runs immediately)
 	go func() {
@@ -81,18 +119,34 @@ func (w *Watcher) Start() error {
 					return
 				}
 
+				if w.gitDir != "" && filepath.Dir(event.Name) == w.gitDir {
+					if filepath.Base(event.Name) == "HEAD" {
+						select {
+						case w.heads <- struct{}{}:
+						default:
+						}
+					}
+					continue
+				}
+
 				if w.shouldIgnore(event.Name) {
 					continue
 				}
 
-				// Auto-watch newly created directories
+				// Auto-watch newly created directories. The event still goes
+				// through: the engine decides what an empty directory means
 				if event.Has(fsnotify.Create) {
 					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
 						_ = fsWatcher.Add(event.Name)
-						continue
 					}
 				}
 
+				// Store relative path
+				relPath, err := filepath.Rel(w.root, event.Name)
+				if err != nil {
+					relPath = event.Name
+				}
+
 				var changeType ChangeType
 				switch {
 				case event.Has(fsnotify.Create):
@@ -103,19 +157,17 @@ func (w *Watcher) Start() error {
 					changeType = Modified
 				case event.Has(fsnotify.Rename):
 					changeType = Renamed
+				case event.Has(fsnotify.Chmod) && w.modeChanged != nil && w.modeChanged(relPath):
+					// e.g. chmod +x: no content event, but git records the mode
+					changeType = Modified
 				default:
 					continue
 				}
 
-				// Store relative path
-				relPath, err := filepath.Rel(w.root, event.Name)
-				if err != nil {
-					relPath = event.Name
-				}
-
 				pending = append(pending, FileChange{
 					Path: relPath,
 					Type: changeType,
+					Time: time.Now(),
 				})
 
 				// Short debounce — just batches rapid saves, not the pipeline trigger
@@ -126,9 +178,14 @@ func (w *Watcher) Start() error {
 				copy(snapshot, pending)
 
 				timer = time.AfterFunc(2*time.Second, func() {
-					w.events <- ChangeSet{
-						Files:     snapshot,
-						Timestamp: time.Now(),
+					if w.coalesce {
+						snapshot = Coalesce(snapshot)
+					}
+					if len(snapshot) > 0 {
+						w.events <- ChangeSet{
+							Files:     snapshot,
+							Timestamp: time.Now(),
+						}
 					}
 					pending = nil
 				})
@@ -186,6 +243,18 @@ func (w *Watcher) shouldIgnore(path string) bool {
 	return false
 }
 
+// Ignored reports whether path (relative to the watch root) is covered by
+// the ignore patterns, either itself or through one of its directories,
+// which the watcher never descends into.
+func (w *Watcher) Ignored(path string) bool {
+	for p := filepath.Clean(path); p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if w.shouldIgnore(p) {
+			return true
+		}
+	}
+	return false
+}
+
 // Stop shuts down the watcher.
 func (w *Watcher) Stop() {
 	close(w.done)
@@ -12,11 +12,14 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/firasastwani/gitpulse/internal/ai"
 	"github.com/firasastwani/gitpulse/internal/config"
 	"github.com/firasastwani/gitpulse/internal/dashboard"
 	"github.com/firasastwani/gitpulse/internal/engine"
 	"github.com/firasastwani/gitpulse/internal/store"
+	"github.com/firasastwani/gitpulse/internal/tui"
 	"github.com/firasastwani/gitpulse/internal/ui"
 )
 
@@ -35,19 +38,69 @@ func main() {
 		return
 	}
 
+	// gitpulse snooze [-C path] [duration]
+	if len(os.Args) > 1 && os.Args[1] == "snooze" {
+		snoozeCmd()
+		return
+	}
+
+	// gitpulse resume [-C path]
+	if len(os.Args) > 1 && os.Args[1] == "resume" {
+		resumeCmd()
+		return
+	}
+
+	// gitpulse status [-C path]
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		statusCmd()
+		return
+	}
+
 	// gitpulse dashboard [-C path] [-port 8080]
 	if len(os.Args) > 1 && os.Args[1] == "dashboard" {
 		dashboardCmd()
 		return
 	}
 
+	// gitpulse history [-C path] [-n 20] [--file path]
+	// gitpulse history compact [-C path] [-older-than-days 30] [-dry-run]
+	if len(os.Args) > 2 && os.Args[1] == "history" && os.Args[2] == "compact" {
+		historyCompactCmd()
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		historyCmd()
+		return
+	}
+
+	// gitpulse config schema
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		configCmd()
+		return
+	}
+
+	// gitpulse dismissed [-C path] [--clear]
+	if len(os.Args) > 1 && os.Args[1] == "dismissed" {
+		dismissedCmd()
+		return
+	}
+
+	// gitpulse cache stats|clear
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		cacheCmd()
+		return
+	}
+
 	// ── Daemon mode: resolve -C/path, load config, run ──
-	watchDir := resolveWatchDir()
+	watchDir, opts := resolveWatchDir()
 	cfg, err := config.LoadFromDir(watchDir, watchDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 		os.Exit(1)
 	}
+	if opts.noAI {
+		cfg.AI.Provider = ai.ProviderNone
+	}
 	// Ensure WatchPath is absolute so watcher/git/store work from any cwd
 	if cfg.WatchPath != "" {
 		abs, err := filepath.Abs(cfg.WatchPath)
@@ -56,22 +109,35 @@ func main() {
 		}
 	}
 
-	// Single stdin reader — shared between main loop and interactive review prompts
+	// Single stdin reader — shared between main loop and interactive review prompts.
+	// In --tui mode bubbletea owns stdin and feeds prompt answers in instead.
 	stdinCh := make(chan string, 1)
-	go func() {
-		scanner := bufio.NewScanner(os.Stdin)
-		for scanner.Scan() {
-			stdinCh <- scanner.Text()
-		}
-		close(stdinCh)
-	}()
+	var tuiLogs *tui.LogBuffer
+	if opts.tui {
+		tuiLogs = tui.NewLogBuffer()
+	} else {
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				stdinCh <- scanner.Text()
+			}
+			close(stdinCh)
+		}()
+	}
 
 	logger := ui.New(stdinCh)
+	if opts.tui {
+		logger.SetOutput(tuiLogs)
+		log.SetOutput(tuiLogs)
+	}
 	logger.Info("GitPulse starting", "path", cfg.WatchPath, "branch", cfg.Branch)
 
 	eng, err := engine.New(cfg, logger)
 	if err != nil {
 		logger.Error("Failed to initialize engine", err)
+		if opts.tui {
+			fmt.Fprintf(os.Stderr, "Failed to initialize engine: %v\n", err)
+		}
 		os.Exit(1)
 	}
 
@@ -86,6 +152,10 @@ func main() {
 	usr1 := make(chan os.Signal, 1)
 	signal.Notify(usr1, syscall.SIGUSR1)
 
+	// Listen for SIGUSR2 (from `gitpulse snooze`/`resume`) to re-read the snooze state
+	usr2 := make(chan os.Signal, 1)
+	signal.Notify(usr2, syscall.SIGUSR2)
+
 	// Listen for SIGINT/SIGTERM to shut down
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -93,7 +163,18 @@ func main() {
 	// Start the engine (watches + buffers changes)
 	go eng.Run()
 
-	logger.Info("Press ENTER to commit & push (or Ctrl+C to quit)")
+	if opts.tui {
+		runTUI(eng, logger, stdinCh, tuiLogs, cfg, usr1, usr2, quit)
+		return
+	}
+
+	// ENTER only pushes with push_mode: auto; otherwise `gitpulse push` does
+	enterHint := "Press ENTER to commit & push (or Ctrl+C to quit)"
+	if cfg.PushMode != config.PushModeAuto {
+		enterHint = "Press ENTER to commit (or Ctrl+C to quit)"
+	}
+
+	logger.Info(enterHint)
 
 	for {
 		select {
@@ -102,13 +183,15 @@ func main() {
 			if pending > 0 {
 				logger.Info("Flushing changes...", "pending", pending)
 				eng.Flush()
-				logger.Info("Press ENTER to commit & push (or Ctrl+C to quit)")
+				logger.Info(enterHint)
 			} else {
 				logger.Info("No pending changes to flush")
 			}
 		case <-usr1:
 			logger.Info("Received push signal — flushing changes...")
-			eng.Flush()
+			eng.PushNow()
+		case <-usr2:
+			eng.SyncSnooze()
 		case <-quit:
 			logger.Info("Shutting down GitPulse...")
 			eng.Stop()
@@ -132,37 +215,159 @@ func pushCmd() {
 		}
 		dir = abs
 	}
-	pidPath := filepath.Join(dir, pidFile)
-	data, err := os.ReadFile(pidPath)
+	pid, err := signalDaemon(dir, syscall.SIGUSR1)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "GitPulse daemon is not running. Start it with `gitpulse` (or `gitpulse -C "+dir+"`) first.")
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	pid, err := strconv.Atoi(string(data))
+	fmt.Printf("Sent push signal to GitPulse daemon (PID %d)\n", pid)
+}
+
+// snoozeCmd pauses auto-commits for a while: changes keep buffering but
+// nothing is flushed (not even by the safety timer) until the snooze ends.
+func snoozeCmd() {
+	fs := flag.NewFlagSet("snooze", flag.ExitOnError)
+	path := fs.String("C", "", "Run as if GitPulse was started in <path>")
+	_ = fs.Parse(os.Args[2:])
+
+	dir := "."
+	if *path != "" {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err)
+			os.Exit(1)
+		}
+		dir = abs
+	}
+
+	d := time.Hour
+	if fs.NArg() > 0 {
+		parsed, err := time.ParseDuration(fs.Arg(0))
+		if err != nil || parsed <= 0 {
+			fmt.Fprintf(os.Stderr, "Invalid duration %q (e.g. 30m, 2h)\n", fs.Arg(0))
+			os.Exit(1)
+		}
+		d = parsed
+	}
+	until := time.Now().Add(d)
+
+	state, err := store.LoadState(filepath.Join(dir, ".gitpulse", "state.json"))
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Invalid PID file. Restart the daemon.")
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := state.Snooze(until); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save snooze: %v\n", err)
 		os.Exit(1)
 	}
 
-	proc, err := os.FindProcess(pid)
+	if pid, err := signalDaemon(dir, syscall.SIGUSR2); err != nil {
+		fmt.Printf("Snoozed until %s (daemon not running — takes effect when it starts)\n", until.Format("15:04"))
+	} else {
+		fmt.Printf("Snoozed GitPulse daemon (PID %d) until %s — run `gitpulse resume` to end early\n", pid, until.Format("15:04"))
+	}
+}
+
+// resumeCmd ends a snooze early; buffered changes flush on the next safety timer.
+func resumeCmd() {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	path := fs.String("C", "", "Run as if GitPulse was started in <path>")
+	_ = fs.Parse(os.Args[2:])
+
+	dir := "."
+	if *path != "" {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err)
+			os.Exit(1)
+		}
+		dir = abs
+	}
+
+	state, err := store.LoadState(filepath.Join(dir, ".gitpulse", "state.json"))
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Could not find daemon process. Restart the daemon.")
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if _, ok := state.Snoozed(); !ok {
+		fmt.Println("GitPulse is not snoozed")
+	}
+	if err := state.Resume(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save state: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := proc.Signal(syscall.SIGUSR1); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to signal daemon (PID %d): %v\n", pid, err)
+	if pid, err := signalDaemon(dir, syscall.SIGUSR2); err == nil {
+		fmt.Printf("Resumed GitPulse daemon (PID %d)\n", pid)
+	}
+}
+
+// statusCmd reports whether the daemon is running, any active snooze, and
+// how many GitPulse commits haven't been pushed yet.
+func statusCmd() {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	path := fs.String("C", "", "Path to project")
+	_ = fs.Parse(os.Args[2:])
+
+	dir := "."
+	if *path != "" {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err)
+			os.Exit(1)
+		}
+		dir = abs
+	}
+
+	if pid, err := signalDaemon(dir, syscall.Signal(0)); err != nil {
+		fmt.Println("Daemon:   not running")
+	} else {
+		fmt.Printf("Daemon:   running (PID %d)\n", pid)
+	}
+
+	state, _ := store.LoadState(filepath.Join(dir, ".gitpulse", "state.json"))
+	if until, ok := state.Snoozed(); ok {
+		fmt.Printf("Snoozed:  until %s (%s left)\n", until.Format("15:04"), time.Until(until).Round(time.Minute))
+	} else {
+		fmt.Println("Snoozed:  no")
+	}
+
+	s, err := store.New(filepath.Join(dir, ".gitpulse", "history.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open history: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Printf("Unpushed: %d commit(s)\n", len(s.GetUnpushedCommits()))
+}
 
-	fmt.Printf("Sent push signal to GitPulse daemon (PID %d)\n", pid)
+// signalDaemon sends sig to the daemon whose PID file is in dir and returns
+// its PID. Signal 0 just checks that the daemon is alive.
+func signalDaemon(dir string, sig os.Signal) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, pidFile))
+	if err != nil {
+		return 0, fmt.Errorf("GitPulse daemon is not running. Start it with `gitpulse` (or `gitpulse -C %s`) first.", dir)
+	}
+
+	pid, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0, fmt.Errorf("Invalid PID file. Restart the daemon.")
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return 0, fmt.Errorf("Could not find daemon process. Restart the daemon.")
+	}
+
+	if err := proc.Signal(sig); err != nil {
+		return pid, fmt.Errorf("Failed to signal daemon (PID %d): %w", pid, err)
+	}
+	return pid, nil
 }
 
 func dashboardCmd() {
 	fs := flag.NewFlagSet("dashboard", flag.ExitOnError)
 	path := fs.String("C", "", "Path to project (for history)")
 	port := fs.String("port", "8080", "HTTP server port")
+	token := fs.String("token", os.Getenv("GITPULSE_DASHBOARD_TOKEN"), "Bearer token required for DELETE /api/commits and POST /api/plan/flush (disabled if empty)")
 	_ = fs.Parse(os.Args[2:])
 
 	dir := "."
@@ -182,6 +387,11 @@ func dashboardCmd() {
 	}
 
 	svr := dashboard.NewServer(s, historyPath)
+	svr.SetAuthToken(*token)
+	svr.SetFlushFunc(func() error {
+		_, err := signalDaemon(dir, syscall.SIGUSR1)
+		return err
+	})
 	addr := ":" + *port
 	fmt.Printf("GitPulse Effects Dashboard at http://localhost%s\n", addr)
 	if err := http.ListenAndServe(addr, svr.Handler()); err != nil {
@@ -189,6 +399,189 @@ func dashboardCmd() {
 	}
 }
 
+// historyCmd prints recent GitPulse commits from the store as a terminal table.
+func historyCmd() {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	path := fs.String("C", "", "Path to project (for history)")
+	n := fs.Int("n", 20, "Number of commits to show")
+	file := fs.String("file", "", "Only show commits touching this file")
+	_ = fs.Parse(os.Args[2:])
+
+	dir := "."
+	if *path != "" {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err)
+			os.Exit(1)
+		}
+		dir = abs
+	}
+	historyPath := filepath.Join(dir, ".gitpulse", "history.json")
+	s, err := store.New(historyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open history: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *n <= 0 {
+		*n = len(s.All())
+	}
+
+	var records []store.CommitRecord
+	if *file != "" {
+		records = s.GetByFile(*file)
+		if len(records) > *n {
+			records = records[len(records)-*n:]
+		}
+	} else {
+		records = s.Recent(*n)
+	}
+
+	// Newest first, copying so the store's slice isn't mutated
+	out := make([]store.CommitRecord, len(records))
+	for i, r := range records {
+		out[len(records)-1-i] = r
+	}
+
+	ui.New(nil).HistoryTable(out)
+}
+
+// historyCompactCmd strips the stored diffs of old commits from
+// history.json, keeping their metadata, and reports the bytes reclaimed.
+func historyCompactCmd() {
+	fs := flag.NewFlagSet("history compact", flag.ExitOnError)
+	path := fs.String("C", "", "Path to project")
+	days := fs.Int("older-than-days", 30, "Strip diffs of commits older than this many days (0 = all commits)")
+	dryRun := fs.Bool("dry-run", false, "Report what would be reclaimed without rewriting history.json")
+	_ = fs.Parse(os.Args[3:])
+
+	if *days < 0 {
+		fmt.Fprintln(os.Stderr, "-older-than-days must be 0 or more")
+		os.Exit(1)
+	}
+	dir := "."
+	if *path != "" {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err)
+			os.Exit(1)
+		}
+		dir = abs
+	}
+	// A running daemon holds the full records in memory and would write the
+	// diffs back on its next commit
+	if pid, err := signalDaemon(dir, syscall.Signal(0)); err == nil && !*dryRun {
+		fmt.Fprintf(os.Stderr, "GitPulse daemon is running (PID %d). Stop it before compacting the history.\n", pid)
+		os.Exit(1)
+	}
+
+	s, err := store.New(filepath.Join(dir, ".gitpulse", "history.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open history: %v\n", err)
+		os.Exit(1)
+	}
+	result, err := s.Compact(store.CompactOptions{
+		StripDiffs: true,
+		OlderThan:  time.Duration(*days) * 24 * time.Hour,
+		DryRun:     *dryRun,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to compact history: %v\n", err)
+		os.Exit(1)
+	}
+
+	verb := "Stripped"
+	if *dryRun {
+		verb = "Would strip"
+	}
+	fmt.Printf("%s diffs of %d file(s) in %d commit(s) older than %d day(s)\n", verb, result.Files, result.Records, *days)
+	if result.Reclaimed() < 0 {
+		// Rewriting re-indents a hand-edited file, which can outgrow what was stripped
+		fmt.Printf("history.json: %d -> %d bytes (grew by %d after reformatting)\n", result.BytesBefore, result.BytesAfter, -result.Reclaimed())
+		return
+	}
+	fmt.Printf("history.json: %d -> %d bytes (%d reclaimed)\n", result.BytesBefore, result.BytesAfter, result.Reclaimed())
+}
+
+// dismissedCmd lists review findings dismissed as false positives, or clears them.
+func dismissedCmd() {
+	fs := flag.NewFlagSet("dismissed", flag.ExitOnError)
+	path := fs.String("C", "", "Path to project")
+	clearAll := fs.Bool("clear", false, "Remove all dismissals so every finding blocks again")
+	_ = fs.Parse(os.Args[2:])
+
+	dir := "."
+	if *path != "" {
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err)
+			os.Exit(1)
+		}
+		dir = abs
+	}
+	d, err := store.NewDismissals(filepath.Join(dir, ".gitpulse", "dismissed.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open dismissals: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *clearAll {
+		n := len(d.All())
+		if err := d.Clear(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to clear dismissals: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Cleared %d dismissed finding(s)\n", n)
+		return
+	}
+
+	entries := d.All()
+	if len(entries) == 0 {
+		fmt.Println("No dismissed findings")
+		return
+	}
+	for _, e := range entries {
+		expires := "never"
+		if !e.ExpiresAt.IsZero() {
+			expires = e.ExpiresAt.Format("2006-01-02")
+		}
+		fmt.Printf("%s  %s:%d-%d  (expires %s)\n    %s\n", e.Key[:8], e.File, e.StartLine, e.EndLine, expires, e.Description)
+	}
+}
+
+// configCmd documents the config file. `schema` prints every key with its
+// type, default and description.
+func configCmd() {
+	if len(os.Args) < 3 || os.Args[2] != "schema" {
+		fmt.Fprintln(os.Stderr, "Usage: gitpulse config schema")
+		os.Exit(2)
+	}
+	for _, f := range config.Schema() {
+		line := fmt.Sprintf("%s (%s", f.Key, f.Type)
+		if f.Default != "" {
+			line += ", default: " + f.Default
+		}
+		fmt.Println(line + ")")
+		if f.Description != "" {
+			fmt.Printf("    %s\n", f.Description)
+		}
+	}
+}
+
+// cacheCmd would report on or clear a cache of AI responses. GitPulse doesn't
+// cache them (every flush asks the API afresh), so both only say so.
+func cacheCmd() {
+	if len(os.Args) < 3 || (os.Args[2] != "stats" && os.Args[2] != "clear") {
+		fmt.Fprintln(os.Stderr, "Usage: gitpulse cache stats|clear")
+		os.Exit(2)
+	}
+	if os.Args[2] == "clear" {
+		fmt.Println("No AI response cache is enabled: nothing to clear")
+		return
+	}
+	fmt.Println("No AI response cache is enabled: every flush calls the API")
+}
+
 func writePID(watchDir string) {
 	pid := os.Getpid()
 	path := filepath.Join(watchDir, pidFile)
@@ -199,25 +592,67 @@ func removePID(watchDir string) {
 	os.Remove(filepath.Join(watchDir, pidFile))
 }
 
+// daemonOptions are the daemon-mode flags besides the watch dir.
+type daemonOptions struct {
+	noAI bool // --no-ai
+	tui  bool // --tui
+}
+
+// runTUI runs the --tui interface until the user quits or a signal arrives.
+// Signals behave as in the plain mode; flushes they start show up in the TUI
+// through the engine's events.
+func runTUI(eng *engine.Engine, logger *ui.Logger, stdinCh chan string, logs *tui.LogBuffer, cfg *config.Config, usr1, usr2, quit chan os.Signal) {
+	title := cfg.WatchPath
+	if cfg.Branch != "" && cfg.Branch != "auto" {
+		var targets []string
+		for _, remote := range cfg.PushRemotes() {
+			targets = append(targets, remote+"/"+cfg.Branch)
+		}
+		title += " → " + strings.Join(targets, ", ")
+	}
+	go func() {
+		for {
+			select {
+			case <-usr1:
+				logger.Info("Received push signal — flushing changes...")
+				go eng.PushNow()
+			case <-usr2:
+				eng.SyncSnooze()
+			}
+		}
+	}()
+
+	if err := tui.Run(tui.New(eng, logger, stdinCh, logs, title), quit); err != nil {
+		fmt.Fprintf(os.Stderr, "TUI failed: %v\n", err)
+	}
+	log.SetOutput(os.Stderr)
+	fmt.Println("Shutting down GitPulse...")
+	eng.Stop()
+}
+
 // resolveWatchDir returns the directory to watch: -C path, or first positional arg, or ".".
-func resolveWatchDir() string {
+// Also returns the other daemon flags (--no-ai, --tui).
+func resolveWatchDir() (string, daemonOptions) {
 	fs := flag.NewFlagSet("gitpulse", flag.ContinueOnError)
 	path := fs.String("C", "", "Run as if GitPulse was started in <path>")
+	noAI := fs.Bool("no-ai", false, "Generate commit messages without any AI calls (same as ai.provider: none)")
+	useTUI := fs.Bool("tui", false, "Full-screen interface: live pending changes, safety-timer countdown, in-panel review")
 	_ = fs.Parse(os.Args[1:])
+	opts := daemonOptions{noAI: *noAI, tui: *useTUI}
 
 	if *path != "" {
 		abs, _ := filepath.Abs(*path)
-		return abs
+		return abs, opts
 	}
 	// First non-flag arg can be the path (e.g. gitpulse /path/to/project)
 	for _, a := range fs.Args() {
 		if a != "" && a[0] != '-' {
 			abs, _ := filepath.Abs(a)
-			return abs
+			return abs, opts
 		}
 	}
 	abs, _ := filepath.Abs(".")
-	return abs
+	return abs, opts
 }
 
 func initCmd() {